@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// Supported values for the price_alert tool's direction argument.
+const (
+	directionAbove = "above"
+	directionBelow = "below"
+)
+
+// PriceAlertArguments defines the structure for arguments used to check a Bitcoin price against a threshold.
+type PriceAlertArguments struct {
+	Currency  string  `json:"currency" jsonschema:"description=The currency the threshold is given in (USD, EUR, GBP, etc)"`
+	Threshold float64 `json:"threshold" jsonschema:"required,description=The Bitcoin price to compare against; must be positive"`
+	Direction string  `json:"direction" jsonschema:"required,enum=above,enum=below,description=Whether the alert fires when the price is above or below the threshold"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting numeric arguments given as numeric strings too.
+func (a *PriceAlertArguments) UnmarshalJSON(data []byte) error {
+	type plain PriceAlertArguments
+	return decodeArguments(data, (*plain)(a))
+}
+
+// PriceAlert is the outcome of comparing a price with an alert threshold.
+type PriceAlert struct {
+	Crossed bool
+	// Margin is how far the price is past the threshold in the alert's direction; negative while not crossed.
+	Margin        float64
+	MarginPercent float64
+}
+
+// checkPriceAlert compares price with threshold. Reaching the threshold exactly counts as crossing it.
+func checkPriceAlert(price, threshold float64, direction string) PriceAlert {
+	margin := price - threshold
+	if direction == directionBelow {
+		margin = threshold - price
+	}
+	return PriceAlert{
+		Crossed:       margin >= 0,
+		Margin:        margin,
+		MarginPercent: margin / threshold * 100,
+	}
+}
+
+// priceAlertTool returns the handler for the price_alert tool, serving prices from cache before asking client.
+func priceAlertTool(client *CryptoClient, cache *priceCache) func(context.Context, PriceAlertArguments) (*mcp_golang.ToolResponse, error) {
+	return func(ctx context.Context, arguments PriceAlertArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", "price_alert", "currency", arguments.Currency, "threshold", arguments.Threshold, "direction", arguments.Direction)
+
+		direction := strings.ToLower(strings.TrimSpace(arguments.Direction))
+		if direction != directionAbove && direction != directionBelow {
+			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Error checking price alert: unsupported direction %q, expected %s or %s", arguments.Direction, directionAbove, directionBelow))), nil
+		}
+		if arguments.Threshold <= 0 {
+			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Error checking price alert: threshold must be positive, got %v", arguments.Threshold))), nil
+		}
+
+		quote, err := lookupBitcoinPrice(ctx, client, cache, arguments.Currency)
+		if err != nil {
+			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Error checking price alert: %s", describeError(err)))), nil
+		}
+		alert := checkPriceAlert(quote.Price, arguments.Threshold, direction)
+
+		status := "not crossed"
+		if alert.Crossed {
+			status = "crossed"
+		}
+		text := fmt.Sprintf("Alert for Bitcoin %s %s %s: %s. The current price is %s %s, a margin of %+.*f %s (%+.2f%%)",
+			direction,
+			formatAmount(arguments.Threshold, quote.Currency),
+			quote.Currency,
+			status,
+			formatAmount(quote.Price, quote.Currency),
+			quote.Currency,
+			decimalsFor(quote.Currency), alert.Margin,
+			quote.Currency,
+			alert.MarginPercent)
+		if quote.Stale {
+			text += "\nWarning: " + quote.staleNote()
+		}
+		if quote.Fallback {
+			text += "\nNote: " + fallbackNote(quote.Source)
+		}
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(markSimulated(client, text))), nil
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCheckPriceAlert(t *testing.T) {
+	tests := []struct {
+		price, threshold float64
+		direction        string
+		crossed          bool
+		margin           float64
+	}{
+		{50000, 45000, directionAbove, true, 5000},
+		{50000, 55000, directionAbove, false, -5000},
+		{50000, 55000, directionBelow, true, 5000},
+		{50000, 40000, directionBelow, false, -10000},
+		{50000, 50000, directionAbove, true, 0},
+	}
+	for _, tt := range tests {
+		alert := checkPriceAlert(tt.price, tt.threshold, tt.direction)
+		if alert.Crossed != tt.crossed || alert.Margin != tt.margin {
+			t.Errorf("%v %s %v: got %+v, want crossed %v with margin %v", tt.price, tt.direction, tt.threshold, alert, tt.crossed, tt.margin)
+		}
+	}
+}
+
+func TestPriceAlertTool(t *testing.T) {
+	tool := priceAlertTool(testCryptoClient(cannedJSON(`{"bitcoin":{"usd":50000}}`)), newPriceCache(defaultCacheTTL, 0))
+	tests := []struct {
+		args PriceAlertArguments
+		want string
+	}{
+		{PriceAlertArguments{Currency: "USD", Threshold: 40000, Direction: "above"},
+			"Alert for Bitcoin above 40000.00 USD: crossed. The current price is 50000.00 USD, a margin of +10000.00 USD (+25.00%)"},
+		{PriceAlertArguments{Currency: "USD", Threshold: 62500, Direction: "Above"},
+			"Alert for Bitcoin above 62500.00 USD: not crossed. The current price is 50000.00 USD, a margin of -12500.00 USD (-20.00%)"},
+		{PriceAlertArguments{Currency: "USD", Threshold: 40000, Direction: "sideways"},
+			`Error checking price alert: unsupported direction "sideways", expected above or below`},
+	}
+	for _, tt := range tests {
+		resp, err := tool(context.Background(), tt.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if text := toolText(t, resp); !strings.HasPrefix(text, tt.want) {
+			t.Errorf("got %q, want %q", text, tt.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// maxParallelRequests bounds how many requests a batch fallback has in flight at once.
+const maxParallelRequests = 4
+
+// fetchBatch fetches values for keys with a single batch call. If that fails for any reason other than rate
+// limiting, it falls back to fetching each key on its own with fetchEach, so that a problem with one key can't
+// sink the rest. It returns the values fetched, the error for each key that failed on its own, and an error only
+// when nothing could be fetched at all.
+func fetchBatch(ctx context.Context, keys []string,
+	batch func(context.Context) (map[string]float64, error),
+	single func(context.Context, string) (float64, error),
+) (map[string]float64, map[string]error, error) {
+	values, err := batch(ctx)
+	if err == nil {
+		return values, nil, nil
+	}
+	if len(keys) < 2 || errors.Is(err, ErrRateLimited) || ctx.Err() != nil {
+		return nil, nil, err
+	}
+
+	values, failed := fetchEach(ctx, keys, single)
+	if len(values) == 0 {
+		// Being rate limited is the more useful explanation if the fallback ran into it
+		for _, key := range keys {
+			if errors.Is(failed[key], ErrRateLimited) {
+				return nil, nil, failed[key]
+			}
+		}
+		return nil, nil, err
+	}
+	return values, failed, nil
+}
+
+// fetchEach calls fetch for every key, at most maxParallelRequests at a time, and returns the values fetched and
+// the error for each key that failed. A failure only affects its own key, except rate limiting: once upstream
+// answers 429, the shared context is cancelled because every other request would be throttled too, and the keys
+// that had not been fetched yet report why they were skipped.
+func fetchEach(ctx context.Context, keys []string, fetch func(context.Context, string) (float64, error)) (map[string]float64, map[string]error) {
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxParallelRequests)
+
+	var mu sync.Mutex
+	values := make(map[string]float64, len(keys))
+	failed := make(map[string]error)
+	for _, key := range keys {
+		g.Go(func() error {
+			if gctx.Err() != nil {
+				mu.Lock()
+				failed[key] = fmt.Errorf("skipped: %w", context.Cause(gctx))
+				mu.Unlock()
+				return nil
+			}
+
+			v, err := fetch(gctx, key)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed[key] = err
+				if errors.Is(err, ErrRateLimited) {
+					return err
+				}
+				return nil
+			}
+			values[key] = v
+			return nil
+		})
+	}
+	g.Wait()
+	return values, failed
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"testing"
+)
+
+func TestFetchBatchReportsPartialFailure(t *testing.T) {
+	prices := map[string]float64{"USD": 50000, "EUR": 46000, "GBP": 39000}
+	errBad := errors.New("unknown currency")
+	batch := func(context.Context) (map[string]float64, error) {
+		return nil, fmt.Errorf("batch failed: %w", errBad)
+	}
+	single := func(ctx context.Context, key string) (float64, error) {
+		if v, ok := prices[key]; ok {
+			return v, nil
+		}
+		return 0, errBad
+	}
+
+	values, failed, err := fetchBatch(context.Background(), []string{"USD", "EUR", "XYZ", "GBP"}, batch, single)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !maps.Equal(values, prices) {
+		t.Errorf("got values %v, want %v", values, prices)
+	}
+	if len(failed) != 1 || !errors.Is(failed["XYZ"], errBad) {
+		t.Errorf("got failures %v, want only XYZ", failed)
+	}
+}
+
+func TestFetchEachStopsAfterRateLimit(t *testing.T) {
+	keys := make([]string, 20)
+	for i := range keys {
+		keys[i] = fmt.Sprint(i)
+	}
+	values, failed := fetchEach(context.Background(), keys, func(ctx context.Context, key string) (float64, error) {
+		return 0, &StatusError{API: "CoinGecko", Status: 429}
+	})
+	if len(values) != 0 || len(failed) != len(keys) {
+		t.Fatalf("got %d values and %d failures, want every key failed", len(values), len(failed))
+	}
+	for key, err := range failed {
+		if !errors.Is(err, ErrRateLimited) && !errors.Is(err, context.Canceled) {
+			t.Errorf("key %s failed with %v, want rate limited or skipped", key, err)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Defaults for the circuit breaker around CoinGecko calls.
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+)
+
+// Circuit breaker states.
+const (
+	breakerClosed = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// circuitBreaker stops an apiClient from hammering an upstream API that keeps failing. After threshold
+// consecutive failures it opens, and calls fail straight away with ErrUpstreamUnavailable for cooldown. Then it
+// turns half-open and lets a single trial call through: success closes it again, failure reopens it for another
+// cooldown. Only failures that speak to the API's health count: it being unreachable or answering 5xx.
+// A nil *circuitBreaker lets every call through.
+type circuitBreaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    int
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+// newCircuitBreaker creates a closed circuit breaker for the named API, or returns nil when threshold is not
+// positive, which disables it.
+func newCircuitBreaker(name string, threshold int, cooldown time.Duration) *circuitBreaker {
+	if threshold <= 0 {
+		return nil
+	}
+	return &circuitBreaker{name: name, threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// WithCircuitBreaker guards every request made by the client with b. A nil breaker disables it.
+func WithCircuitBreaker(b *circuitBreaker) ClientOption {
+	return func(c *apiClient) {
+		c.breaker = b
+	}
+}
+
+// WithOwnCircuitBreaker gives the client a circuit breaker of its own, named after its API, opening after threshold
+// consecutive failures for cooldown. Unlike WithCircuitBreaker, the option can be shared by several clients without
+// one API's failures pausing the others. A threshold that is not positive disables it.
+func WithOwnCircuitBreaker(threshold int, cooldown time.Duration) ClientOption {
+	return func(c *apiClient) {
+		c.breaker = newCircuitBreaker(c.name, threshold, cooldown)
+	}
+}
+
+// allow reports whether a call may go ahead, returning an error matching ErrUpstreamUnavailable when the breaker
+// is open, or half-open with its trial call still running, and whether the call is that trial. A call that is
+// allowed must be followed by record, passing trial back.
+func (b *circuitBreaker) allow() (trial bool, err error) {
+	if b == nil {
+		return false, nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		wait := b.cooldown - b.now().Sub(b.openedAt)
+		if wait > 0 {
+			return false, fmt.Errorf("%w: %s API failed %d times in a row, so calls are paused for another %s", ErrUpstreamUnavailable, b.name, b.failures, wait.Round(time.Second))
+		}
+		slog.Info("Circuit breaker half-open, sending a trial request", "api", b.name)
+		b.state = breakerHalfOpen
+		b.trial = true
+		return true, nil
+	case breakerHalfOpen:
+		if b.trial {
+			return false, fmt.Errorf("%w: %s API is being probed after repeated failures, try again shortly", ErrUpstreamUnavailable, b.name)
+		}
+		b.trial = true
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// record updates the breaker with the outcome of a call allow let through, where trial is what allow returned for
+// it. A call cut short by its own context says nothing about the upstream API, so it neither counts as a failure
+// nor closes the breaker. Once the breaker has opened only the trial decides what happens next, so a slow call
+// let through while it was closed can't close it again or be taken for the trial.
+func (b *circuitBreaker) record(ctx context.Context, trial bool, err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if trial {
+		b.trial = false
+	} else if b.state != breakerClosed {
+		return
+	}
+	switch {
+	case ctx.Err() != nil || errors.Is(err, ErrRateLimited):
+		// Neither healthy nor failing; a half-open breaker sends another trial on the next call
+	case errors.Is(err, ErrUpstreamUnavailable):
+		b.failures++
+		if trial || b.failures >= b.threshold {
+			if b.state != breakerOpen {
+				slog.Warn("Circuit breaker opened", "api", b.name, "failures", b.failures, "cooldown", b.cooldown)
+			}
+			b.state = breakerOpen
+			b.openedAt = b.now()
+		}
+	default:
+		// Any answer that isn't a server error, a 404 included, shows the API is up
+		if b.state != breakerClosed {
+			slog.Info("Circuit breaker closed", "api", b.name)
+		}
+		b.state = breakerClosed
+		b.failures = 0
+	}
+}
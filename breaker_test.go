@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	flaky := &countingTransport{respond: func(req *http.Request) (*http.Response, error) {
+		if failing.Load() {
+			return cannedResponse(req, http.StatusServiceUnavailable, `{"error":"maintenance"}`), nil
+		}
+		return cannedResponse(req, http.StatusOK, `{"bitcoin":{"usd":50000}}`), nil
+	}}
+	now := time.Date(2024, time.March, 1, 9, 30, 0, 0, time.UTC)
+	breaker := newCircuitBreaker("CoinGecko", 2, 30*time.Second)
+	breaker.now = func() time.Time { return now }
+	client := testCryptoClient(flaky, WithCircuitBreaker(breaker))
+	ctx := context.Background()
+
+	call := func(step string, wantRequests int32, wantState int) error {
+		t.Helper()
+		before := flaky.requests.Load()
+		_, err := client.Price(ctx, "bitcoin", "usd")
+		if n := flaky.requests.Load() - before; n != wantRequests {
+			t.Errorf("%s: made %d requests, want %d", step, n, wantRequests)
+		}
+		if breaker.state != wantState {
+			t.Errorf("%s: breaker state %d, want %d", step, breaker.state, wantState)
+		}
+		return err
+	}
+
+	// Closed: failures go through until the threshold is reached
+	call("first failure", 1, breakerClosed)
+	call("second failure", 1, breakerOpen)
+
+	// Open: calls fail straight away without reaching the API
+	if err := call("while open", 0, breakerOpen); !errors.Is(err, ErrUpstreamUnavailable) {
+		t.Errorf("while open: got error %v, want ErrUpstreamUnavailable", err)
+	}
+
+	// Half-open: after the cooldown one trial goes through, and its failure reopens the breaker
+	now = now.Add(31 * time.Second)
+	call("failed trial", 1, breakerOpen)
+	call("reopened", 0, breakerOpen)
+
+	// A successful trial closes the breaker again
+	now = now.Add(31 * time.Second)
+	failing.Store(false)
+	if err := call("successful trial", 1, breakerClosed); err != nil {
+		t.Errorf("successful trial: %v", err)
+	}
+
+	// Closed again, the failure count starts over
+	failing.Store(true)
+	call("failure after recovery", 1, breakerClosed)
+}
+
+func TestCircuitBreakerIgnoresRejectedRequests(t *testing.T) {
+	breaker := newCircuitBreaker("CoinGecko", 1, time.Minute)
+	notFound := &countingTransport{respond: func(req *http.Request) (*http.Response, error) {
+		return cannedResponse(req, http.StatusNotFound, `{"error":"coin not found"}`), nil
+	}}
+	client := testCryptoClient(notFound, WithCircuitBreaker(breaker))
+	for range 3 {
+		client.Price(context.Background(), "no-such-coin", "usd")
+	}
+	if breaker.state != breakerClosed || notFound.requests.Load() != 3 {
+		t.Errorf("breaker state %d after %d requests, want a 404 to leave it closed", breaker.state, notFound.requests.Load())
+	}
+
+	if newCircuitBreaker("CoinGecko", 0, time.Minute) != nil {
+		t.Error("a zero threshold did not disable the breaker")
+	}
+}
+
+func TestCircuitBreakerLeavesTheTrialToTheTrialCall(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 9, 30, 0, 0, time.UTC)
+	breaker := newCircuitBreaker("CoinGecko", 1, 30*time.Second)
+	breaker.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// A slow call is let through while closed, then another call's failure opens the breaker
+	slow, _ := breaker.allow()
+	failed, _ := breaker.allow()
+	breaker.record(ctx, failed, ErrUpstreamUnavailable)
+	now = now.Add(31 * time.Second)
+	trial, err := breaker.allow()
+	if err != nil || !trial || slow {
+		t.Fatalf("got trial %v, error %v and slow call trial %v, want only the call after the cooldown to be the trial", trial, err, slow)
+	}
+
+	// The slow call finishing, either way, neither ends the trial nor changes the state
+	breaker.record(ctx, slow, nil)
+	breaker.record(ctx, slow, ErrUpstreamUnavailable)
+	if breaker.state != breakerHalfOpen {
+		t.Errorf("breaker state %d after the slow call finished, want it still half-open", breaker.state)
+	}
+	if _, err := breaker.allow(); !errors.Is(err, ErrUpstreamUnavailable) {
+		t.Errorf("got error %v, want a second trial refused while the first runs", err)
+	}
+
+	breaker.record(ctx, trial, nil)
+	if breaker.state != breakerClosed {
+		t.Errorf("breaker state %d after a successful trial, want it closed", breaker.state)
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// defaultCacheTTL is how long a fetched price is served from the cache before CoinGecko is queried again.
+const defaultCacheTTL = 60 * time.Second
+
+// maxCacheTTL caps how long an upstream Cache-Control max-age can keep a price cached.
+const maxCacheTTL = 10 * time.Minute
+
+// ttlJitter is the largest fraction of the TTL an entry may expire early by, so that prices fetched together
+// don't all expire at the same instant and send a burst of requests to CoinGecko.
+const ttlJitter = 0.1
+
+// defaultMaxStale is how long past its TTL a cached price may still be served when CoinGecko can't be reached.
+const defaultMaxStale = 10 * time.Minute
+
+// cacheEntry holds a cached price together with when it was fetched and the time it stops being valid.
+type cacheEntry struct {
+	value     float64
+	fetchedAt time.Time
+	expiresAt time.Time
+}
+
+// priceCache is a small in-memory TTL cache for price lookups, safe for concurrent use.
+// Expired entries are kept so they can serve as a fallback for up to maxStale after they expire.
+type priceCache struct {
+	mu       sync.RWMutex
+	ttl      time.Duration
+	maxStale time.Duration
+	entries  map[string]cacheEntry
+	// inflight collapses concurrent fetches of the same key into one upstream call.
+	inflight singleflight.Group
+}
+
+// newPriceCache creates an empty priceCache whose entries expire after ttl and remain usable as stale
+// fallbacks for a further maxStale. A zero maxStale disables the stale fallback.
+func newPriceCache(ttl, maxStale time.Duration) *priceCache {
+	return &priceCache{
+		ttl:      ttl,
+		maxStale: maxStale,
+		entries:  make(map[string]cacheEntry),
+	}
+}
+
+// priceCacheKey builds the cache key for a coin and currency pair.
+func priceCacheKey(coinID, currency string) string {
+	return coinID + "/" + currency
+}
+
+// Get returns the cached value for key and whether it was present and not yet expired.
+func (c *priceCache) Get(key string) (float64, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return 0, false
+	}
+	return entry.value, true
+}
+
+// GetStale returns the last value stored under key and when it was fetched, as long as it expired no more
+// than maxStale ago. It is meant for when a fresh value can't be fetched; use Get otherwise.
+func (c *priceCache) GetStale(key string) (float64, time.Time, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt.Add(c.maxStale)) {
+		return 0, time.Time{}, false
+	}
+	return entry.value, entry.fetchedAt, true
+}
+
+// Set stores v under key for the cache's TTL, shortened by a random jitter of up to ttlJitter.
+func (c *priceCache) Set(key string, v float64) {
+	c.SetWithMaxAge(key, v, 0)
+}
+
+// SetWithMaxAge stores v under key for maxAge, as sent by the upstream API, instead of the cache's TTL. The max-age
+// is capped at maxCacheTTL, and a zero max-age or a cache whose TTL is zero (caching disabled) keeps the cache's TTL.
+// Either way the TTL is shortened by a random jitter of up to ttlJitter.
+func (c *priceCache) SetWithMaxAge(key string, v float64, maxAge time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ttl := c.ttl
+	if maxAge > 0 && ttl > 0 {
+		ttl = min(maxAge, maxCacheTTL)
+	}
+	now := time.Now()
+	ttl -= time.Duration(rand.Float64() * ttlJitter * float64(ttl))
+	c.entries[key] = cacheEntry{
+		value:     v,
+		fetchedAt: now,
+		expiresAt: now.Add(ttl),
+	}
+}
+
+// Fetch returns the cached price for key, with sourceCache as its source, or calls fetch and caches its result for
+// the max-age it returns, as SetWithMaxAge does. Concurrent calls for the same key share a single fetch, so a burst of
+// requests for an expired price makes one upstream call; they all receive its result, source included, or its error.
+// The shared fetch runs detached from any one caller's ctx and is bounded by timeout instead, unless that is zero, so
+// a caller that gives up doesn't fail the fetch for the others. Each caller still stops waiting when its ctx is done.
+func (c *priceCache) Fetch(ctx context.Context, key string, timeout time.Duration, fetch func(context.Context) (SourcedPrice, error)) (SourcedPrice, error) {
+	if v, ok := c.Get(key); ok {
+		return SourcedPrice{Price: v, Source: sourceCache}, nil
+	}
+	results := c.inflight.DoChan(key, func() (any, error) {
+		// Another caller may have stored the value while this one waited to start
+		if v, ok := c.Get(key); ok {
+			return SourcedPrice{Price: v, Source: sourceCache}, nil
+		}
+		fetchCtx := context.WithoutCancel(ctx)
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			fetchCtx, cancel = context.WithTimeout(fetchCtx, timeout)
+			defer cancel()
+		}
+		p, err := fetch(fetchCtx)
+		if err != nil {
+			return SourcedPrice{}, err
+		}
+		c.SetWithMaxAge(key, p.Price, p.MaxAge)
+		return p, nil
+	})
+	select {
+	case res := <-results:
+		return res.Val.(SourcedPrice), res.Err
+	case <-ctx.Done():
+		return SourcedPrice{}, ctx.Err()
+	}
+}
+
+// persistedEntry is the on-disk form of a cacheEntry.
+type persistedEntry struct {
+	Value     float64   `json:"value"`
+	FetchedAt time.Time `json:"fetched_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SaveFile writes the cache contents to path as JSON, replacing the file atomically so a crash mid-write
+// never leaves a truncated cache behind.
+func (c *priceCache) SaveFile(path string) error {
+	c.mu.RLock()
+	entries := make(map[string]persistedEntry, len(c.entries))
+	for key, entry := range c.entries {
+		entries[key] = persistedEntry{Value: entry.value, FetchedAt: entry.fetchedAt, ExpiresAt: entry.expiresAt}
+	}
+	c.mu.RUnlock()
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("error encoding cache: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error writing cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing cache file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing cache file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error writing cache file: %w", err)
+	}
+	return nil
+}
+
+// LoadFile restores entries saved by SaveFile, skipping those already past their TTL, and returns how many
+// were loaded. A missing file is not an error, since there is nothing to restore on the first start.
+func (c *priceCache) LoadFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error reading cache file: %w", err)
+	}
+	var entries map[string]persistedEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return 0, fmt.Errorf("error parsing cache file: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	loaded := 0
+	for key, entry := range entries {
+		if now.After(entry.ExpiresAt) {
+			continue
+		}
+		c.entries[key] = cacheEntry{value: entry.Value, fetchedAt: entry.FetchedAt, expiresAt: entry.ExpiresAt}
+		loaded++
+	}
+	return loaded, nil
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBitcoinPriceServesSecondCallFromCache(t *testing.T) {
+	transport := cannedJSON(`{"bitcoin":{"usd":50000}}`)
+	tmpl, err := parsePriceTemplate("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tool := bitcoinPriceTool(testCryptoClient(transport), newPriceCache(defaultCacheTTL, 0), tmpl)
+
+	for i := range 2 {
+		resp, err := tool(context.Background(), BitcoinPriceArguments{Currency: "USD"})
+		if err != nil {
+			t.Fatalf("call %d: %v", i+1, err)
+		}
+		if text := toolText(t, resp); !strings.Contains(text, "50,000") {
+			t.Errorf("call %d: got %q, want the price 50,000", i+1, text)
+		}
+	}
+	if n := transport.requests.Load(); n != 1 {
+		t.Errorf("made %d HTTP requests for two calls within the TTL, want 1", n)
+	}
+}
+
+func TestBitcoinPriceServesStaleDuringOutage(t *testing.T) {
+	// The first request succeeds, then CoinGecko goes down
+	transport := &countingTransport{}
+	transport.respond = func(req *http.Request) (*http.Response, error) {
+		if transport.requests.Load() > 1 {
+			return cannedResponse(req, http.StatusServiceUnavailable, `{"error":"down"}`), nil
+		}
+		return cannedResponse(req, http.StatusOK, `{"bitcoin":{"usd":50000}}`), nil
+	}
+	tmpl, err := parsePriceTemplate("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Entries expire straight away but stay usable as a fallback for an hour
+	tool := bitcoinPriceTool(testCryptoClient(transport), newPriceCache(time.Nanosecond, time.Hour), tmpl)
+
+	if _, err := tool(context.Background(), BitcoinPriceArguments{Currency: "USD"}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := tool(context.Background(), BitcoinPriceArguments{Currency: "USD"})
+	if err != nil {
+		t.Fatalf("outage with a cached price: %v", err)
+	}
+	text := toolText(t, resp)
+	if !strings.Contains(text, "50,000") || !strings.Contains(text, "Warning: CoinGecko is currently unavailable") {
+		t.Errorf("got %q, want the previous price with a staleness warning", text)
+	}
+	if n := transport.requests.Load(); n != 2 {
+		t.Errorf("made %d requests, want 2", n)
+	}
+}
+
+func TestPriceCacheSurvivesSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.json")
+	saved := newPriceCache(defaultCacheTTL, 0)
+	saved.Set(priceCacheKey("bitcoin", "USD"), 50000)
+	past := time.Now().Add(-time.Hour)
+	saved.entries[priceCacheKey("bitcoin", "EUR")] = cacheEntry{value: 40000, fetchedAt: past, expiresAt: past.Add(defaultCacheTTL)}
+	if err := saved.SaveFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := newPriceCache(defaultCacheTTL, 0)
+	n, err := restored.LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("loaded %d entries, want only the unexpired one", n)
+	}
+	if v, ok := restored.Get(priceCacheKey("bitcoin", "USD")); !ok || v != 50000 {
+		t.Errorf("got %v, %v for the saved USD price, want 50000", v, ok)
+	}
+	if _, ok := restored.entries[priceCacheKey("bitcoin", "EUR")]; ok {
+		t.Error("the expired EUR price was restored")
+	}
+
+	if n, err := newPriceCache(defaultCacheTTL, 0).LoadFile(filepath.Join(t.TempDir(), "missing.json")); n != 0 || err != nil {
+		t.Errorf("missing file: got %d, %v, want nothing loaded and no error", n, err)
+	}
+}
+
+func TestPriceCacheFetchCollapsesConcurrentCalls(t *testing.T) {
+	cache := newPriceCache(defaultCacheTTL, 0)
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fetch := func(context.Context) (SourcedPrice, error) {
+		calls.Add(1)
+		<-release
+		return SourcedPrice{Price: 50000, Source: "CoinGecko"}, nil
+	}
+
+	const callers = 50
+	var started, done sync.WaitGroup
+	started.Add(callers)
+	done.Add(callers)
+	for range callers {
+		go func() {
+			defer done.Done()
+			started.Done()
+			// Every caller sharing the fetch gets its source; one arriving after it finished hits the cache
+			p, err := cache.Fetch(context.Background(), priceCacheKey("bitcoin", "USD"), 0, fetch)
+			if err != nil || p.Price != 50000 || (p.Source != "CoinGecko" && p.Source != sourceCache) {
+				t.Errorf("got %+v, %v, want 50000", p, err)
+			}
+		}()
+	}
+	started.Wait()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	done.Wait()
+
+	// Callers that missed the shared fetch find its result in the cache
+	if n := calls.Load(); n != 1 {
+		t.Errorf("made %d upstream calls, want 1", n)
+	}
+}
+
+func TestPriceCacheFetchGivesWaitersTheFetchedSource(t *testing.T) {
+	cache := newPriceCache(defaultCacheTTL, 0)
+	fetching, release := make(chan struct{}), make(chan struct{})
+	fetch := func(context.Context) (SourcedPrice, error) {
+		close(fetching)
+		<-release
+		return SourcedPrice{Price: 50000, Source: "CoinCap"}, nil
+	}
+
+	first := make(chan SourcedPrice)
+	go func() {
+		p, _ := cache.Fetch(context.Background(), "bitcoin/usd", 0, fetch)
+		first <- p
+	}()
+	<-fetching
+	second := make(chan SourcedPrice)
+	go func() {
+		p, _ := cache.Fetch(context.Background(), "bitcoin/usd", 0, fetch)
+		second <- p
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	// The waiter didn't read the price from the cache, so it must not say it did
+	for _, p := range []SourcedPrice{<-first, <-second} {
+		if p.Price != 50000 || p.Source != "CoinCap" {
+			t.Errorf("got %+v, want 50000 from CoinCap", p)
+		}
+	}
+	if p, err := cache.Fetch(context.Background(), "bitcoin/usd", 0, fetch); err != nil || p.Source != sourceCache {
+		t.Errorf("got %+v, %v, want the next call served from the cache", p, err)
+	}
+}
+
+func TestPriceCacheFetchOutlivesCancelledCaller(t *testing.T) {
+	cache := newPriceCache(defaultCacheTTL, 0)
+	fetching, release := make(chan struct{}), make(chan struct{})
+	fetchErr := make(chan error, 1)
+	fetch := func(ctx context.Context) (SourcedPrice, error) {
+		close(fetching)
+		<-release
+		fetchErr <- ctx.Err()
+		return SourcedPrice{Price: 50000, Source: "CoinGecko"}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error)
+	go func() {
+		_, err := cache.Fetch(ctx, "bitcoin/usd", time.Minute, fetch)
+		cancelled <- err
+	}()
+	<-fetching
+	waiter := make(chan SourcedPrice)
+	go func() {
+		p, _ := cache.Fetch(context.Background(), "bitcoin/usd", time.Minute, fetch)
+		waiter <- p
+	}()
+
+	// The caller that started the fetch stops waiting as soon as it gives up
+	cancel()
+	if err := <-cancelled; !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want the cancelled caller to return context.Canceled", err)
+	}
+	close(release)
+	if p := <-waiter; p.Price != 50000 {
+		t.Errorf("got %+v, want the other caller to get the price", p)
+	}
+	if err := <-fetchErr; err != nil {
+		t.Errorf("the shared fetch saw %v, want it unaffected by the cancelled caller", err)
+	}
+}
+
+func TestPriceCacheFetchTimesOut(t *testing.T) {
+	cache := newPriceCache(defaultCacheTTL, 0)
+	_, err := cache.Fetch(context.Background(), "bitcoin/usd", 10*time.Millisecond, func(ctx context.Context) (SourcedPrice, error) {
+		<-ctx.Done()
+		return SourcedPrice{}, ctx.Err()
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want the fetch bounded by the timeout", err)
+	}
+}
+
+func TestPriceCacheTTLJitter(t *testing.T) {
+	cache := newPriceCache(defaultCacheTTL, 0)
+	before := time.Now()
+	for i := range 100 {
+		cache.Set(fmt.Sprint(i), 1)
+	}
+	for key, entry := range cache.entries {
+		ttl := entry.expiresAt.Sub(before)
+		if ttl < time.Duration(float64(defaultCacheTTL)*(1-ttlJitter)) || ttl > defaultCacheTTL+time.Second {
+			t.Errorf("entry %s expires after %v, want within %v of %v", key, ttl, ttlJitter, defaultCacheTTL)
+		}
+	}
+}
+
+func TestPriceCacheHonorsMaxAge(t *testing.T) {
+	transport := &countingTransport{respond: func(req *http.Request) (*http.Response, error) {
+		resp := cannedResponse(req, http.StatusOK, `{"bitcoin":{"usd":50000}}`)
+		resp.Header.Set("Cache-Control", "public, max-age=30")
+		return resp, nil
+	}}
+	cache := newPriceCache(defaultCacheTTL, 0)
+	before := time.Now()
+	if _, err := lookupBitcoinPrice(context.Background(), testCryptoClient(transport), cache, "USD"); err != nil {
+		t.Fatal(err)
+	}
+	entry, ok := cache.entries[priceCacheKey("bitcoin", "usd")]
+	if !ok {
+		t.Fatal("the price was not cached")
+	}
+	if ttl := entry.expiresAt.Sub(before); ttl < 27*time.Second || ttl > 31*time.Second {
+		t.Errorf("cached for %v, want the 30s max-age less jitter", ttl)
+	}
+}
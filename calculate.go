@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// CalculateArguments defines the structure for arguments used to evaluate an arithmetic expression.
+type CalculateArguments struct {
+	Expression string `json:"expression" jsonschema:"required,maxLength=1024,description=The expression to evaluate using + - * / and parentheses on decimal numbers such as (1.5 + 2) * 3"`
+}
+
+// Kinds of calcToken.
+const (
+	tokenNumber = iota
+	tokenOperator
+	tokenOpenParen
+	tokenCloseParen
+)
+
+// opNegate is the operator unary minus is turned into, so the evaluator can tell it from subtraction.
+const opNegate = '~'
+
+// calcToken is one number, operator or parenthesis of an expression. Pos is its 1-based position in characters,
+// for error messages.
+type calcToken struct {
+	kind  int
+	op    rune
+	value float64
+	pos   int
+}
+
+// precedence returns how tightly op binds; higher binds tighter.
+func precedence(op rune) int {
+	switch op {
+	case opNegate:
+		return 3
+	case '*', '/':
+		return 2
+	default:
+		return 1
+	}
+}
+
+// evaluateExpression evaluates an arithmetic expression of decimal numbers, + - * / and parentheses with the usual
+// precedence, using the shunting-yard algorithm. Unary minus and plus are allowed wherever a number may start.
+func evaluateExpression(expr string) (float64, error) {
+	tokens, err := tokenizeExpression(expr)
+	if err != nil {
+		return 0, err
+	}
+	rpn, err := toRPN(tokens)
+	if err != nil {
+		return 0, err
+	}
+	return evaluateRPN(rpn)
+}
+
+// tokenizeExpression splits expr into tokens, checking that numbers and operators alternate properly so that the
+// later stages only have parentheses left to check.
+func tokenizeExpression(expr string) ([]calcToken, error) {
+	runes := []rune(expr)
+	var tokens []calcToken
+	// expectOperand is set where a number, an opening parenthesis or a unary sign must come next
+	expectOperand := true
+	for i := 0; i < len(runes); i++ {
+		r, pos := runes[i], i+1
+		switch {
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			continue
+		case r >= '0' && r <= '9' || r == '.':
+			if !expectOperand {
+				return nil, fmt.Errorf("missing operator before the number at position %d", pos)
+			}
+			end := i
+			for end < len(runes) && (runes[end] >= '0' && runes[end] <= '9' || runes[end] == '.') {
+				end++
+			}
+			literal := string(runes[i:end])
+			v, err := strconv.ParseFloat(literal, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at position %d", literal, pos)
+			}
+			tokens = append(tokens, calcToken{kind: tokenNumber, value: v, pos: pos})
+			i = end - 1
+			expectOperand = false
+		case r == '(':
+			if !expectOperand {
+				return nil, fmt.Errorf("missing operator before the parenthesis at position %d", pos)
+			}
+			tokens = append(tokens, calcToken{kind: tokenOpenParen, pos: pos})
+		case r == ')':
+			if expectOperand {
+				return nil, fmt.Errorf("expected a number before the closing parenthesis at position %d", pos)
+			}
+			tokens = append(tokens, calcToken{kind: tokenCloseParen, pos: pos})
+		case r == '+' || r == '-':
+			if expectOperand {
+				// A unary plus changes nothing, so only a minus needs a token
+				if r == '-' {
+					tokens = append(tokens, calcToken{kind: tokenOperator, op: opNegate, pos: pos})
+				}
+				continue
+			}
+			tokens = append(tokens, calcToken{kind: tokenOperator, op: r, pos: pos})
+			expectOperand = true
+		case r == '*' || r == '/':
+			if expectOperand {
+				return nil, fmt.Errorf("operator %q at position %d is missing its left operand", r, pos)
+			}
+			tokens = append(tokens, calcToken{kind: tokenOperator, op: r, pos: pos})
+			expectOperand = true
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", r, pos)
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("expression is empty")
+	}
+	if expectOperand {
+		return nil, fmt.Errorf("expression ends before its last operand")
+	}
+	return tokens, nil
+}
+
+// toRPN reorders tokens into reverse Polish notation, reporting unbalanced parentheses.
+func toRPN(tokens []calcToken) ([]calcToken, error) {
+	var out, stack []calcToken
+	for _, tok := range tokens {
+		switch tok.kind {
+		case tokenNumber:
+			out = append(out, tok)
+		case tokenOperator:
+			for len(stack) > 0 {
+				top := stack[len(stack)-1]
+				if top.kind != tokenOperator {
+					break
+				}
+				// Binary operators are left-associative, negation is right-associative
+				if precedence(top.op) < precedence(tok.op) || precedence(top.op) == precedence(tok.op) && tok.op == opNegate {
+					break
+				}
+				out = append(out, top)
+				stack = stack[:len(stack)-1]
+			}
+			stack = append(stack, tok)
+		case tokenOpenParen:
+			stack = append(stack, tok)
+		case tokenCloseParen:
+			for len(stack) > 0 && stack[len(stack)-1].kind != tokenOpenParen {
+				out = append(out, stack[len(stack)-1])
+				stack = stack[:len(stack)-1]
+			}
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("closing parenthesis at position %d has no matching opening one", tok.pos)
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		if top.kind == tokenOpenParen {
+			return nil, fmt.Errorf("opening parenthesis at position %d is never closed", top.pos)
+		}
+		out = append(out, top)
+		stack = stack[:len(stack)-1]
+	}
+	return out, nil
+}
+
+// evaluateRPN evaluates tokens in reverse Polish notation. tokenizeExpression has already checked that every
+// operator has its operands.
+func evaluateRPN(rpn []calcToken) (float64, error) {
+	var stack []float64
+	for _, tok := range rpn {
+		if tok.kind == tokenNumber {
+			stack = append(stack, tok.value)
+			continue
+		}
+		if tok.op == opNegate {
+			stack[len(stack)-1] = -stack[len(stack)-1]
+			continue
+		}
+		a, b := stack[len(stack)-2], stack[len(stack)-1]
+		stack = stack[:len(stack)-2]
+		var v float64
+		switch tok.op {
+		case '+':
+			v = a + b
+		case '-':
+			v = a - b
+		case '*':
+			v = a * b
+		case '/':
+			if b == 0 {
+				return 0, fmt.Errorf("division by zero at position %d", tok.pos)
+			}
+			v = a / b
+		}
+		stack = append(stack, v)
+	}
+
+	result := stack[0]
+	if math.IsInf(result, 0) || math.IsNaN(result) {
+		return 0, fmt.Errorf("result is too large to represent")
+	}
+	// Avoid reporting -0 for results such as -0 * 5
+	if result == 0 {
+		result = 0
+	}
+	return result, nil
+}
+
+// formatResult formats a calculation result with up to 15 significant digits, which hides the binary rounding
+// noise of sums such as 0.1 + 0.2.
+func formatResult(v float64) string {
+	return strconv.FormatFloat(v, 'g', 15, 64)
+}
+
+// calculateTool handles the calculate tool. It needs no network access, so it also works in offline mode.
+func calculateTool(ctx context.Context, arguments CalculateArguments) (*mcp_golang.ToolResponse, error) {
+	slog.DebugContext(ctx, "Received tool request", "tool", "calculate", "expression", arguments.Expression)
+	if err := validateRequired(arguments); err != nil {
+		return nil, err
+	}
+
+	expr := strings.TrimSpace(arguments.Expression)
+	result, err := evaluateExpression(expr)
+	if err != nil {
+		return toolFailure("error evaluating expression", err)
+	}
+	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("%s = %s", expr, formatResult(result)))), nil
+}
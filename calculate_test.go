@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestEvaluateExpression(t *testing.T) {
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		{"1 + 2 * 3", 7},
+		{"10 - 4 - 3", 3},
+		{"8 / 4 / 2", 1},
+		{"(1 + 2) * 3", 9},
+		{"((2))", 2},
+		{"2 * (3 + (4 - 1)) / 4", 3},
+		{"1.5 + 2.25", 3.75},
+		{"-3 + 5", 2},
+		{"-(2 + 3) * 2", -10},
+		{"2 * -3", -6},
+		{"-0 * 5", 0},
+	}
+	for _, tt := range tests {
+		got, err := evaluateExpression(tt.expr)
+		if err != nil {
+			t.Errorf("evaluateExpression(%q): %v", tt.expr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("evaluateExpression(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluateExpressionErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"1 / 0", "division by zero"},
+		{"4 / (2 - 2)", "division by zero"},
+		{"(1 + 2", "parenthes"},
+		{"1 + 2)", "parenthes"},
+		{"1 +", ""},
+		{"* 2", ""},
+		{"1 2", ""},
+		{"2 ^ 3", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		_, err := evaluateExpression(tt.expr)
+		if err == nil {
+			t.Errorf("evaluateExpression(%q) succeeded, want an error", tt.expr)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("evaluateExpression(%q) error = %q, want it to mention %q", tt.expr, err, tt.want)
+		}
+	}
+}
+
+func TestCalculateTool(t *testing.T) {
+	resp, err := calculateTool(context.Background(), CalculateArguments{Expression: " 0.1 + 0.2 "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := toolText(t, resp); got != "0.1 + 0.2 = 0.3" {
+		t.Errorf("got %q, want 0.1 + 0.2 = 0.3", got)
+	}
+
+	// Returning the error is what makes mcp-golang flag the response with isError
+	for _, expr := range []string{"1 / 0", "(1 + 2"} {
+		if _, err := calculateTool(context.Background(), CalculateArguments{Expression: expr}); err == nil || !strings.HasPrefix(err.Error(), "error evaluating expression: ") {
+			t.Errorf("%s: got error %v, want it rejected", expr, err)
+		}
+	}
+
+	if _, err := calculateTool(context.Background(), CalculateArguments{}); err == nil || !strings.Contains(err.Error(), "expression") {
+		t.Errorf("got error %v, want the missing expression rejected", err)
+	}
+	if _, err := calculateTool(context.Background(), CalculateArguments{Expression: strings.Repeat("1+", 600) + "1"}); err == nil {
+		t.Error("an expression over the length limit was evaluated")
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// defaultCallHistorySize is how many recent tool calls are kept for the call_history tool.
+const defaultCallHistorySize = 100
+
+// maxHistoryValueLength caps the length of the string argument values kept in the history, in runes.
+const maxHistoryValueLength = 80
+
+// sensitiveArgumentNames are the substrings that mark an argument name as potentially holding a secret.
+// Its value is replaced with redactedValue in the history.
+var sensitiveArgumentNames = []string{"key", "token", "secret", "password", "passwd", "auth", "cookie", "credential", "session"}
+
+// CallRecord is one tool call in the call history.
+type CallRecord struct {
+	Time      time.Time      `json:"time" jsonschema:"description=When the call started"`
+	Tool      string         `json:"tool" jsonschema:"description=The name of the tool called"`
+	RequestID string         `json:"request_id,omitempty" jsonschema:"description=The request ID the call was logged with"`
+	Arguments map[string]any `json:"arguments,omitempty" jsonschema:"description=The call arguments with secrets redacted and long strings shortened"`
+	Outcome   string         `json:"outcome" jsonschema:"enum=ok,enum=error,description=ok unless the tool failed"`
+	Error     string         `json:"error,omitempty" jsonschema:"description=Why the tool failed"`
+	Duration  string         `json:"duration" jsonschema:"description=How long the call took"`
+}
+
+// CallHistory is the report returned by the call_history tool.
+type CallHistory struct {
+	Calls []CallRecord `json:"calls" jsonschema:"description=The most recent tool calls; oldest first"`
+}
+
+// callHistory keeps the last tool calls in a ring buffer, safe for concurrent use.
+type callHistory struct {
+	mu      sync.Mutex
+	records []CallRecord
+	// next is the index the next record is stored at; once the history is full it is also the oldest record.
+	next int
+	full bool
+}
+
+// newCallHistory creates a callHistory holding at most size records.
+func newCallHistory(size int) *callHistory {
+	return &callHistory{records: make([]CallRecord, size)}
+}
+
+// recentCalls records every tool call through WithHistory; nil when the history is disabled.
+// main sets it before any tool is registered.
+var recentCalls *callHistory
+
+// Add stores r, evicting the oldest record once the history is full.
+func (h *callHistory) Add(r CallRecord) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.records) == 0 {
+		return
+	}
+	h.records[h.next] = r
+	h.next = (h.next + 1) % len(h.records)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// Last returns up to n of the most recent records, oldest first.
+func (h *callHistory) Last(n int) []CallRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	all := make([]CallRecord, 0, len(h.records))
+	if h.full {
+		all = append(all, h.records[h.next:]...)
+	}
+	all = append(all, h.records[:h.next]...)
+	if n < len(all) {
+		all = all[len(all)-n:]
+	}
+	return all
+}
+
+// WithHistory records every invocation of the named tool in recentCalls with a redacted summary of its arguments,
+// its outcome and duration. It does nothing while the history is disabled.
+func WithHistory(name string) Middleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, arguments any) (*mcp_golang.ToolResponse, error) {
+			if recentCalls == nil {
+				return next(ctx, arguments)
+			}
+			start := time.Now()
+			resp, err := next(ctx, arguments)
+
+			record := CallRecord{
+				Time:      start.UTC(),
+				Tool:      name,
+				RequestID: requestIDFromContext(ctx),
+				Arguments: summarizeArguments(arguments),
+				Outcome:   outcomeOK,
+				Duration:  time.Since(start).String(),
+			}
+			if err != nil {
+				record.Outcome = outcomeError
+				record.Error = err.Error()
+			}
+			recentCalls.Add(record)
+			return resp, err
+		}
+	}
+}
+
+// summarizeArguments converts arguments into their JSON object form for the history, redacting the values of
+// sensitive looking fields and shortening long strings. It returns nil for arguments that don't encode to an object.
+func summarizeArguments(arguments any) map[string]any {
+	data, err := json.Marshal(arguments)
+	if err != nil {
+		return nil
+	}
+	var summary map[string]any
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return nil
+	}
+	redactArguments(summary)
+	return summary
+}
+
+// redactArguments redacts and shortens the values of m in place, descending into nested objects and arrays.
+func redactArguments(m map[string]any) {
+	for name, v := range m {
+		if isSensitiveArgument(name) && v != nil {
+			m[name] = redactedValue
+			continue
+		}
+		m[name] = summarizeValue(v)
+	}
+}
+
+// summarizeValue shortens v if it is a long string and redacts the objects it contains.
+func summarizeValue(v any) any {
+	switch v := v.(type) {
+	case string:
+		if runes := []rune(v); len(runes) > maxHistoryValueLength {
+			return string(runes[:maxHistoryValueLength]) + "…"
+		}
+	case map[string]any:
+		redactArguments(v)
+	case []any:
+		for i := range v {
+			v[i] = summarizeValue(v[i])
+		}
+	}
+	return v
+}
+
+// isSensitiveArgument reports whether an argument name suggests its value is a secret.
+func isSensitiveArgument(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range sensitiveArgumentNames {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// CallHistoryArguments defines the structure for arguments used to request the recent tool calls.
+type CallHistoryArguments struct {
+	N int `json:"n" jsonschema:"default=20,description=How many of the most recent calls to return"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting numeric arguments given as numeric strings too.
+func (a *CallHistoryArguments) UnmarshalJSON(data []byte) error {
+	type plain CallHistoryArguments
+	return decodeArguments(data, (*plain)(a))
+}
+
+// callHistoryTool returns the handler for the call_history tool, reporting the most recent calls in history as JSON,
+// oldest first. A call_history call is recorded once it completes, so it doesn't show up in its own result.
+func callHistoryTool(history *callHistory) func(context.Context, CallHistoryArguments) (*mcp_golang.ToolResponse, error) {
+	return func(ctx context.Context, arguments CallHistoryArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", "call_history", "n", arguments.N)
+
+		if arguments.N < 1 {
+			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Error reading call history: n must be at least 1, got %d", arguments.N))), nil
+		}
+		return NewJSONToolResponse(CallHistory{Calls: history.Last(arguments.N)})
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+func TestCallHistoryKeepsLastCallsInOrder(t *testing.T) {
+	history := newCallHistory(3)
+	for i := range 5 {
+		history.Add(CallRecord{Tool: fmt.Sprint("tool", i)})
+	}
+	var tools []string
+	for _, r := range history.Last(10) {
+		tools = append(tools, r.Tool)
+	}
+	if got := strings.Join(tools, ","); got != "tool2,tool3,tool4" {
+		t.Errorf("got %s, want the last three calls, oldest first", got)
+	}
+	if last := history.Last(1); len(last) != 1 || last[0].Tool != "tool4" {
+		t.Errorf("got %+v, want only the newest call", last)
+	}
+}
+
+func TestWithHistoryRecordsRedactedCalls(t *testing.T) {
+	previous := recentCalls
+	recentCalls = newCallHistory(defaultCallHistorySize)
+	t.Cleanup(func() { recentCalls = previous })
+
+	type arguments struct {
+		City   string `json:"city"`
+		APIKey string `json:"api_key"`
+	}
+	ok := WithHistory("weather")(func(ctx context.Context, _ any) (*mcp_golang.ToolResponse, error) {
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent("sunny")), nil
+	})
+	failing := WithHistory("weather")(func(ctx context.Context, _ any) (*mcp_golang.ToolResponse, error) {
+		return nil, errors.New("upstream down")
+	})
+	ok(context.Background(), arguments{City: "Paris", APIKey: "secret"})
+	failing(context.Background(), arguments{City: strings.Repeat("a", 100)})
+
+	calls := recentCalls.Last(10)
+	if len(calls) != 2 {
+		t.Fatalf("got %d calls, want 2", len(calls))
+	}
+	if calls[0].Outcome != outcomeOK || calls[0].Arguments["city"] != "Paris" || calls[0].Arguments["api_key"] != redactedValue {
+		t.Errorf("first call recorded as %+v, want ok with the key redacted", calls[0])
+	}
+	if calls[1].Outcome != outcomeError || calls[1].Error != "upstream down" || calls[1].Arguments["city"] != strings.Repeat("a", maxHistoryValueLength)+"…" {
+		t.Errorf("second call recorded as %+v, want the error and a shortened city", calls[1])
+	}
+}
+
+func TestWithHistoryRecordsUpstreamFailuresAsErrors(t *testing.T) {
+	previous := recentCalls
+	recentCalls = newCallHistory(defaultCallHistorySize)
+	t.Cleanup(func() { recentCalls = previous })
+
+	// A real tool against a dead upstream, so the outcome depends on the tool returning its error
+	tool := wrapTool("top_coins", topCoinsTool(testCryptoClient(offlineTransport())))
+	if _, err := tool(context.Background(), TopCoinsArguments{N: 3, Currency: "USD"}); err == nil {
+		t.Fatal("top_coins succeeded with no network")
+	}
+
+	calls := recentCalls.Last(1)
+	if len(calls) != 1 || calls[0].Outcome != outcomeError || !strings.HasPrefix(calls[0].Error, "error listing top coins: ") {
+		t.Errorf("got %+v, want the failed call recorded as an error", calls)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// BitcoinChangeArguments defines the structure for arguments used to request the Bitcoin price change over a window.
+type BitcoinChangeArguments struct {
+	Currency string `json:"currency" jsonschema:"required,description=The currency to get the Bitcoin price in (USD, EUR, GBP, etc)"`
+	Days     int    `json:"days" jsonschema:"required,description=How many days back to compare against, from 1 to 365"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting numeric arguments given as numeric strings too.
+func (a *BitcoinChangeArguments) UnmarshalJSON(data []byte) error {
+	type plain BitcoinChangeArguments
+	return decodeArguments(data, (*plain)(a))
+}
+
+// PriceChange is the difference between the first and last points of a price series.
+type PriceChange struct {
+	From, To PricePoint
+	Absolute float64
+	Percent  float64
+}
+
+// PriceChangeResult is the structured payload returned by the bitcoin_change tool.
+type PriceChangeResult struct {
+	Currency      string    `json:"currency" jsonschema:"description=The ISO 4217 code of the currency"`
+	Days          int       `json:"days" jsonschema:"description=How many days back the change is measured over"`
+	FromPrice     float64   `json:"from_price" jsonschema:"description=The Bitcoin price at the start of the window"`
+	FromTime      time.Time `json:"from_time" jsonschema:"description=When the starting price was recorded"`
+	ToPrice       float64   `json:"to_price" jsonschema:"description=The latest Bitcoin price in the window"`
+	ToTime        time.Time `json:"to_time" jsonschema:"description=When the latest price was recorded"`
+	Change        float64   `json:"change" jsonschema:"description=The absolute change from from_price to to_price"`
+	ChangePercent float64   `json:"change_percent" jsonschema:"description=The change as a percentage of from_price"`
+}
+
+// priceChange computes the change between the earliest and latest points, which must be sorted by time.
+func priceChange(points []PricePoint) (PriceChange, error) {
+	if len(points) < 2 {
+		return PriceChange{}, fmt.Errorf("not enough price data to compute a change")
+	}
+	from, to := points[0], points[len(points)-1]
+	if from.Price == 0 {
+		return PriceChange{}, fmt.Errorf("starting price on %s is zero", from.Time.Format(isoDateLayout))
+	}
+	return PriceChange{
+		From:     from,
+		To:       to,
+		Absolute: to.Price - from.Price,
+		Percent:  (to.Price - from.Price) / from.Price * 100,
+	}, nil
+}
+
+// bitcoinChangeTool returns the handler for the bitcoin_change tool, fetching the price history with client and
+// reporting the change as a JSON object.
+func bitcoinChangeTool(client *CryptoClient) func(context.Context, BitcoinChangeArguments) (*mcp_golang.ToolResponse, error) {
+	return func(ctx context.Context, arguments BitcoinChangeArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", "bitcoin_change", "currency", arguments.Currency, "days", arguments.Days)
+
+		// Fall back to the configured default currency if none is specified
+		currency := currencyOrDefault(arguments.Currency)
+		currency, err := NormalizeCurrency(currency)
+		if err != nil {
+			return toolFailure("error fetching Bitcoin price change", err)
+		}
+		if arguments.Days < 1 || arguments.Days > maxMarketChartDays {
+			return toolFailure("error fetching Bitcoin price change", fmt.Errorf("days must be between 1 and %d, got %d", maxMarketChartDays, arguments.Days))
+		}
+
+		points, err := client.MarketChart(ctx, "bitcoin", currency, arguments.Days)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error fetching Bitcoin market chart", "tool", "bitcoin_change", "currency", currency, "days", arguments.Days, "error", err)
+			return toolFailure("error fetching Bitcoin price change", err)
+		}
+		change, err := priceChange(points)
+		if err != nil {
+			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Error fetching Bitcoin price change: %v", err))), nil
+		}
+
+		return NewJSONToolResponse(PriceChangeResult{
+			Currency:      currency,
+			Days:          arguments.Days,
+			FromPrice:     change.From.Price,
+			FromTime:      change.From.Time.UTC(),
+			ToPrice:       change.To.Price,
+			ToTime:        change.To.Time.UTC(),
+			Change:        change.Absolute,
+			ChangePercent: change.Percent,
+		})
+	}
+}
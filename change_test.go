@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// cannedMarketChart is a market_chart payload of three daily prices, listed out of order.
+const cannedMarketChart = `{"prices":[[1704153600000,44000],[1704067200000,40000],[1704240000000,42000]],"market_caps":[],"total_volumes":[]}`
+
+func TestPriceChangeFromMarketChart(t *testing.T) {
+	points, err := testCryptoClient(cannedJSON(cannedMarketChart)).MarketChart(context.Background(), "bitcoin", "USD", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	change, err := priceChange(points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if change.From.Price != 40000 || change.To.Price != 42000 {
+		t.Errorf("compared %v with %v, want the oldest and newest prices 40000 and 42000", change.From.Price, change.To.Price)
+	}
+	if change.Absolute != 2000 || math.Abs(change.Percent-5) > 1e-9 {
+		t.Errorf("got a change of %v (%v%%), want 2000 (5%%)", change.Absolute, change.Percent)
+	}
+
+	if _, err := priceChange(points[:1]); err == nil {
+		t.Error("a single price point gave a change")
+	}
+}
+
+func TestBitcoinChangeTool(t *testing.T) {
+	tool := bitcoinChangeTool(testCryptoClient(cannedJSON(cannedMarketChart)))
+	resp, err := tool(context.Background(), BitcoinChangeArguments{Currency: "USD", Days: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got PriceChangeResult
+	if err := json.Unmarshal([]byte(toolText(t, resp)), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Currency != "USD" || got.Days != 2 || got.FromPrice != 40000 || got.ToPrice != 42000 || got.Change != 2000 || math.Abs(got.ChangePercent-5) > 1e-9 {
+		t.Errorf("got %+v, want a change of 2000 USD (5%%) from 40000", got)
+	}
+	if !got.FromTime.Equal(time.UnixMilli(1704067200000)) || !got.ToTime.Equal(time.UnixMilli(1704240000000)) {
+		t.Errorf("compared %s with %s, want the oldest and newest points", got.FromTime, got.ToTime)
+	}
+
+	if _, err := tool(context.Background(), BitcoinChangeArguments{Currency: "USD", Days: 0}); err == nil || !strings.HasPrefix(err.Error(), "error fetching Bitcoin price change: days must be between") {
+		t.Errorf("got error %v, want zero days rejected", err)
+	}
+}
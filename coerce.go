@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// decodeArguments decodes the tool arguments in data into v, a pointer to an arguments struct, like json.Unmarshal
+// does, except that numeric fields also accept their value as a numeric JSON string such as "10", since some clients
+// quote every argument. Errors name the offending field.
+//
+// Argument types opt in with an UnmarshalJSON method that calls decodeArguments on a method-less copy of themselves,
+// which keeps the input schema derived from the struct unchanged.
+func decodeArguments(data []byte, v any) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("arguments must be a JSON object: %w", err)
+	}
+	if err := coerceNumericStrings(raw, reflect.TypeOf(v).Elem()); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(data, v)
+	if typeErr := (*json.UnmarshalTypeError)(nil); errors.As(err, &typeErr) && typeErr.Field != "" {
+		return fmt.Errorf("field %s must be of type %s, got %s", typeErr.Field, typeErr.Type, typeErr.Value)
+	}
+	return err
+}
+
+// coerceNumericStrings replaces the quoted values of the numeric fields of t in raw with the numbers they hold.
+// Fields of embedded structs are top-level arguments too, so they are coerced as well.
+func coerceNumericStrings(raw map[string]json.RawMessage, t reflect.Type) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if err := coerceNumericStrings(raw, field.Type); err != nil {
+				return err
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		name := jsonFieldName(field)
+		value, ok := raw[name]
+		if !ok || len(value) == 0 || value[0] != '"' {
+			continue
+		}
+
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			return fmt.Errorf("field %s: %w", name, err)
+		}
+		s = strings.TrimSpace(s)
+		kind := field.Type
+		if kind.Kind() == reflect.Ptr {
+			kind = kind.Elem()
+		}
+		var err error
+		want := "a whole number"
+		switch kind.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			_, err = strconv.ParseInt(s, 10, kind.Bits())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			_, err = strconv.ParseUint(s, 10, kind.Bits())
+		case reflect.Float32, reflect.Float64:
+			want = "a number"
+			// ParseFloat also takes "NaN" and "Inf", which are no JSON numbers
+			if _, err = strconv.ParseFloat(s, kind.Bits()); err == nil && !json.Valid([]byte(s)) {
+				err = strconv.ErrSyntax
+			}
+		default:
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("field %s must be %s, got %s", name, want, value)
+		}
+		raw[name] = json.RawMessage(s)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestArgumentsAcceptNumericStrings(t *testing.T) {
+	var quoted, plain TopCoinsArguments
+	if err := json.Unmarshal([]byte(`{"n":" 10 ","currency":"EUR"}`), &quoted); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`{"n":10,"currency":"EUR"}`), &plain); err != nil {
+		t.Fatal(err)
+	}
+	if quoted != plain || quoted.N != 10 {
+		t.Errorf("quoted arguments decoded to %+v, want %+v", quoted, plain)
+	}
+
+	var convert FiatConvertArguments
+	if err := json.Unmarshal([]byte(`{"amount":"12.5","from":"USD","to":"EUR"}`), &convert); err != nil {
+		t.Fatal(err)
+	}
+	if convert.Amount != 12.5 {
+		t.Errorf("amount decoded to %v, want 12.5", convert.Amount)
+	}
+	// Strings stay strings, even when they look like numbers
+	if convert.From != "USD" {
+		t.Errorf("from decoded to %q", convert.From)
+	}
+}
+
+func TestArgumentsRejectNonNumericStrings(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{`{"n":"ten"}`, `field n must be a whole number, got "ten"`},
+		{`{"n":"2.5"}`, `field n must be a whole number, got "2.5"`},
+		{`{"n":true}`, "field n must be of type int, got bool"},
+	}
+	for _, tt := range tests {
+		var args TopCoinsArguments
+		if err := json.Unmarshal([]byte(tt.data), &args); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got error %v, want %q", tt.data, err, tt.want)
+		}
+	}
+	for _, amount := range []string{`"NaN"`, `"Inf"`, `"1e"`} {
+		var args FiatConvertArguments
+		if err := json.Unmarshal([]byte(`{"amount":`+amount+`}`), &args); err == nil {
+			t.Errorf("amount %s was accepted", amount)
+		}
+	}
+}
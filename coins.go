@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// coinListTTL is how long the full CoinGecko coin list is kept before it is fetched again.
+const coinListTTL = time.Hour
+
+// maxCoinSearchResults caps how many matches search_coins returns.
+const maxCoinSearchResults = 10
+
+// Coin identifies a coin listed on CoinGecko.
+type Coin struct {
+	ID     string `json:"id"`
+	Symbol string `json:"symbol"`
+	Name   string `json:"name"`
+}
+
+// CoinGeckoSearchResponse represents the parts of the CoinGecko search response we use.
+type CoinGeckoSearchResponse struct {
+	Coins []Coin `json:"coins"`
+}
+
+// SearchCoinsArguments defines the structure for arguments used to search for CoinGecko coin ids.
+type SearchCoinsArguments struct {
+	Query string `json:"query" jsonschema:"required,description=A coin name, symbol or id to search for (bitcoin, ETH, sol, etc)"`
+}
+
+// coinListMaxBodySize is the smallest body size limit the coin list is fetched with.
+const coinListMaxBodySize = 32 << 20
+
+// CoinList retrieves every coin listed on CoinGecko.
+func (c *CryptoClient) CoinList(ctx context.Context) ([]Coin, error) {
+	// The full list runs to a few MiB, well past the limit that suits every other response
+	list := c.apiClient
+	list.maxBodySize = max(list.maxBodySize, coinListMaxBodySize)
+
+	var coins []Coin
+	if err := list.getJSON(ctx, "/coins/list", nil, &coins); err != nil {
+		return nil, err
+	}
+	return coins, nil
+}
+
+// SearchCoins asks CoinGecko for the coins matching query, best matches first.
+func (c *CryptoClient) SearchCoins(ctx context.Context, query string) ([]Coin, error) {
+	var data CoinGeckoSearchResponse
+	if err := c.getJSON(ctx, "/search", url.Values{"query": {query}}, &data); err != nil {
+		return nil, err
+	}
+	return data.Coins, nil
+}
+
+// coinIndex answers coin searches from a cached copy of the full CoinGecko coin list, falling back to the
+// CoinGecko search endpoint while the list is not loaded. It is safe for concurrent use.
+type coinIndex struct {
+	client *CryptoClient
+	ttl    time.Duration
+
+	mu         sync.Mutex
+	coins      []Coin
+	fetchedAt  time.Time
+	refreshing bool
+}
+
+// newCoinIndex creates an empty coinIndex that keeps the coin list for ttl.
+func newCoinIndex(client *CryptoClient, ttl time.Duration) *coinIndex {
+	return &coinIndex{client: client, ttl: ttl}
+}
+
+// Search returns up to limit coins matching query. When the cached list is missing or expired the query goes to
+// CoinGecko's search endpoint, and the list is refreshed in the background for the next search.
+func (idx *coinIndex) Search(ctx context.Context, query string, limit int) ([]Coin, error) {
+	idx.mu.Lock()
+	coins, warm := idx.coins, time.Since(idx.fetchedAt) < idx.ttl
+	if !warm && !idx.refreshing {
+		idx.refreshing = true
+		go idx.refresh()
+	}
+	idx.mu.Unlock()
+
+	if warm {
+		return filterCoins(coins, query, limit), nil
+	}
+
+	found, err := idx.client.SearchCoins(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(found) > limit {
+		found = found[:limit]
+	}
+	return found, nil
+}
+
+// refresh reloads the coin list. It runs detached from any tool call, so its timeout is the client's own.
+func (idx *coinIndex) refresh() {
+	coins, err := idx.client.CoinList(context.Background())
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.refreshing = false
+	if err != nil {
+		slog.Warn("Error refreshing CoinGecko coin list", "error", err)
+		return
+	}
+	idx.coins, idx.fetchedAt = coins, time.Now()
+	slog.Debug("Refreshed CoinGecko coin list", "coins", len(coins))
+}
+
+// filterCoins returns up to limit coins whose id, symbol or name contains query, ignoring case.
+// Exact matches rank first, then prefix matches, then other matches; ties keep the shorter name first.
+func filterCoins(coins []Coin, query string, limit int) []Coin {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil
+	}
+
+	type match struct {
+		coin Coin
+		rank int
+	}
+	var matches []match
+	for _, coin := range coins {
+		best := -1
+		for _, field := range []string{coin.ID, coin.Symbol, coin.Name} {
+			field = strings.ToLower(field)
+			rank := -1
+			switch {
+			case field == query:
+				rank = 0
+			case strings.HasPrefix(field, query):
+				rank = 1
+			case strings.Contains(field, query):
+				rank = 2
+			}
+			if rank >= 0 && (best < 0 || rank < best) {
+				best = rank
+			}
+		}
+		if best >= 0 {
+			matches = append(matches, match{coin, best})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].rank != matches[j].rank {
+			return matches[i].rank < matches[j].rank
+		}
+		return len(matches[i].coin.Name) < len(matches[j].coin.Name)
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	result := make([]Coin, len(matches))
+	for i, m := range matches {
+		result[i] = m.coin
+	}
+	return result
+}
+
+// searchCoinsTool returns the handler for the search_coins tool, looking coins up in index.
+func searchCoinsTool(index *coinIndex) func(context.Context, SearchCoinsArguments) (*mcp_golang.ToolResponse, error) {
+	return func(ctx context.Context, arguments SearchCoinsArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", "search_coins", "query", arguments.Query)
+
+		query := strings.TrimSpace(arguments.Query)
+		if query == "" {
+			return toolFailure("error searching coins", errors.New("a query is required"))
+		}
+
+		coins, err := index.Search(ctx, query, maxCoinSearchResults)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error searching coins", "tool", "search_coins", "query", query, "error", err)
+			return toolFailure("error searching coins", err)
+		}
+		if len(coins) == 0 {
+			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("No coins match %q", query))), nil
+		}
+
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "Coins matching %q (use the id with crypto_price):\n", query)
+		for _, coin := range coins {
+			fmt.Fprintf(&sb, "- %s: %s (%s)\n", coin.ID, coin.Name, strings.ToUpper(coin.Symbol))
+		}
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(sb.String())), nil
+	}
+}
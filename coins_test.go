@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+// cannedCoinList is a coins/list payload with a few coins sharing parts of their names.
+const cannedCoinList = `[
+	{"id":"bitcoin-cash","symbol":"bch","name":"Bitcoin Cash"},
+	{"id":"wrapped-bitcoin","symbol":"wbtc","name":"Wrapped Bitcoin"},
+	{"id":"bitcoin","symbol":"btc","name":"Bitcoin"},
+	{"id":"ethereum","symbol":"eth","name":"Ethereum"},
+	{"id":"solana","symbol":"sol","name":"Solana"}
+]`
+
+func TestFilterCoins(t *testing.T) {
+	var coins []Coin
+	if err := json.Unmarshal([]byte(cannedCoinList), &coins); err != nil {
+		t.Fatal(err)
+	}
+	ids := func(coins []Coin) []string {
+		var ids []string
+		for _, coin := range coins {
+			ids = append(ids, coin.ID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		query string
+		limit int
+		want  []string
+	}{
+		// Exact matches first, then prefix matches, then the rest
+		{"Bitcoin", 10, []string{"bitcoin", "bitcoin-cash", "wrapped-bitcoin"}},
+		{"ETH", 10, []string{"ethereum"}},
+		{"bitcoin", 2, []string{"bitcoin", "bitcoin-cash"}},
+		{"dogecoin", 10, nil},
+		{"  ", 10, nil},
+	}
+	for _, tt := range tests {
+		if got := ids(filterCoins(coins, tt.query, tt.limit)); !slices.Equal(got, tt.want) {
+			t.Errorf("filterCoins(%q, %d) = %v, want %v", tt.query, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestSearchCoinsFallsBackToSearchEndpoint(t *testing.T) {
+	transport := cannedJSON(`{"coins":[{"id":"solana","symbol":"sol","name":"Solana"}]}`)
+	// A zero TTL keeps the index cold, so every search goes to the endpoint
+	index := newCoinIndex(testCryptoClient(transport), 0)
+	coins, err := index.Search(context.Background(), "sol", maxCoinSearchResults)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(coins) != 1 || coins[0].ID != "solana" {
+		t.Errorf("got %v, want solana", coins)
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// BitcoinStatsArguments defines the structure for arguments used to request the 24h Bitcoin market statistics.
+type BitcoinStatsArguments struct {
+	Currency string `json:"currency" jsonschema:"description=The currency to report the statistics in (USD, EUR, GBP, etc)"`
+	Locale   string `json:"locale" jsonschema:"default=en-US,description=The locale to format the amounts for (en-US, de-DE, etc)"`
+}
+
+// CoinGeckoCoinResponse represents the parts of the CoinGecko coins/{id} response we use.
+// Every market data field maps lowercase currency codes to a value.
+type CoinGeckoCoinResponse struct {
+	MarketData *struct {
+		CurrentPrice map[string]float64 `json:"current_price"`
+		High24h      map[string]float64 `json:"high_24h"`
+		Low24h       map[string]float64 `json:"low_24h"`
+		TotalVolume  map[string]float64 `json:"total_volume"`
+		MarketCap    map[string]float64 `json:"market_cap"`
+	} `json:"market_data"`
+}
+
+// CoinStats are the current price and 24h market statistics of a coin in one currency.
+type CoinStats struct {
+	Price     float64
+	High24h   float64
+	Low24h    float64
+	Volume24h float64
+	MarketCap float64
+}
+
+// CoinStats retrieves the current price, 24h high and low, 24h trading volume and market cap of a coin.
+func (c *CryptoClient) CoinStats(ctx context.Context, coinID, currency string) (CoinStats, error) {
+	coinID = strings.ToLower(coinID)
+	query := url.Values{}
+	query.Set("localization", "false")
+	query.Set("tickers", "false")
+	query.Set("community_data", "false")
+	query.Set("developer_data", "false")
+
+	var data CoinGeckoCoinResponse
+	err := c.getJSON(ctx, "/coins/"+url.PathEscape(coinID), query, &data)
+	if isStatus(err, http.StatusNotFound) {
+		return CoinStats{}, fmt.Errorf("%w: %s", ErrCoinNotFound, coinID)
+	}
+	if err != nil {
+		return CoinStats{}, err
+	}
+	return parseCoinStats(data, currency)
+}
+
+// parseCoinStats picks the values for currency out of the market data maps. Every field must be present,
+// so a currency CoinGecko only partly covers is reported rather than shown as zero.
+func parseCoinStats(data CoinGeckoCoinResponse, currency string) (CoinStats, error) {
+	if data.MarketData == nil {
+		return CoinStats{}, fmt.Errorf("CoinGecko returned no market data")
+	}
+	var stats CoinStats
+	fields := []struct {
+		name   string
+		values map[string]float64
+		dst    *float64
+	}{
+		{"price", data.MarketData.CurrentPrice, &stats.Price},
+		{"24h high", data.MarketData.High24h, &stats.High24h},
+		{"24h low", data.MarketData.Low24h, &stats.Low24h},
+		{"24h volume", data.MarketData.TotalVolume, &stats.Volume24h},
+		{"market cap", data.MarketData.MarketCap, &stats.MarketCap},
+	}
+	key := strings.ToLower(currency)
+	for _, f := range fields {
+		v, ok := f.values[key]
+		if !ok {
+			return CoinStats{}, fmt.Errorf("%s in %s not available from upstream", f.name, currency)
+		}
+		*f.dst = v
+	}
+	return stats, nil
+}
+
+// bitcoinStatsTool returns the handler for the bitcoin_stats tool, fetching market data with client.
+func bitcoinStatsTool(client *CryptoClient) func(context.Context, BitcoinStatsArguments) (*mcp_golang.ToolResponse, error) {
+	return func(ctx context.Context, arguments BitcoinStatsArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", "bitcoin_stats", "currency", arguments.Currency, "locale", arguments.Locale)
+
+		printer, err := localePrinter(arguments.Locale)
+		if err != nil {
+			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Error fetching Bitcoin stats: %v", err))), nil
+		}
+		// Fall back to the configured default currency if none is specified
+		currency, err := NormalizeCurrency(currencyOrDefault(arguments.Currency))
+		if err != nil {
+			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Error fetching Bitcoin stats: %v", err))), nil
+		}
+
+		stats, err := client.CoinStats(ctx, "bitcoin", currency)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error fetching Bitcoin market data", "tool", "bitcoin_stats", "currency", currency, "error", err)
+			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Error fetching Bitcoin stats: %s", describeError(err)))), nil
+		}
+
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Bitcoin in %s: price %s, 24h high %s, 24h low %s, 24h volume %s, market cap %s",
+			currency,
+			formatPrice(printer, stats.Price, currency),
+			formatPrice(printer, stats.High24h, currency),
+			formatPrice(printer, stats.Low24h, currency),
+			formatPrice(printer, stats.Volume24h, currency),
+			formatPrice(printer, stats.MarketCap, currency)))), nil
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// cannedCoin is a trimmed coins/bitcoin payload; CoinGecko sends many more fields and currencies.
+const cannedCoin = `{
+	"id": "bitcoin", "symbol": "btc", "name": "Bitcoin",
+	"market_data": {
+		"current_price": {"usd": 50000, "jpy": 7500000},
+		"high_24h": {"usd": 51000.5, "jpy": 7650000},
+		"low_24h": {"usd": 49000.25, "jpy": 7350000},
+		"total_volume": {"usd": 25000000000, "jpy": 3750000000000},
+		"market_cap": {"usd": 980000000000},
+		"price_change_percentage_24h": 2.5
+	}
+}`
+
+func TestBitcoinStatsParsesCoinPayload(t *testing.T) {
+	tool := bitcoinStatsTool(testCryptoClient(cannedJSON(cannedCoin)))
+	resp, err := tool(context.Background(), BitcoinStatsArguments{Currency: "USD", Locale: "en-US"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Bitcoin in USD: price 50,000.00, 24h high 51,000.50, 24h low 49,000.25, 24h volume 25,000,000,000.00, market cap 980,000,000,000.00"
+	if text := toolText(t, resp); text != want {
+		t.Errorf("got %q, want %q", text, want)
+	}
+
+	// The JPY maps lack a market cap
+	resp, err = tool(context.Background(), BitcoinStatsArguments{Currency: "JPY", Locale: "en-US"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := toolText(t, resp); !strings.Contains(text, "market cap in JPY not available from upstream") {
+		t.Errorf("got %q, want the missing market cap reported", text)
+	}
+}
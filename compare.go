@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"text/tabwriter"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"golang.org/x/text/message"
+)
+
+// CompareCoinsArguments defines the structure for arguments used to compare two coins.
+type CompareCoinsArguments struct {
+	CoinA    string `json:"coin_a" jsonschema:"required,description=The CoinGecko id of the first coin (bitcoin, ethereum, solana, etc)"`
+	CoinB    string `json:"coin_b" jsonschema:"required,description=The CoinGecko id of the second coin (bitcoin, ethereum, solana, etc)"`
+	Currency string `json:"currency" jsonschema:"description=The currency to compare the coins in (USD, EUR, GBP, etc)"`
+	Locale   string `json:"locale" jsonschema:"default=en-US,description=The locale to format the amounts for (en-US, de-DE, etc)"`
+}
+
+// formatComparison renders two coins side by side, one column each, with their price, 24h change and market cap,
+// followed by which of the two is larger by market cap when both caps are known. Market caps are shown in whole
+// units, their cents being noise at that size.
+func formatComparison(printer *message.Printer, a, b CoinGeckoMarket, currency string) string {
+	optional := func(v *float64, format func(float64) string) string {
+		if v == nil {
+			return "n/a"
+		}
+		return format(*v)
+	}
+	price := func(v float64) string {
+		if v < 1 {
+			return tablePrice(v, currency)
+		}
+		return formatPrice(printer, v, currency)
+	}
+	change := func(v float64) string { return fmt.Sprintf("%+.2f%%", v) }
+	amount := func(v float64) string { return printer.Sprintf("%.0f", v) }
+
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "\t%s (%s)\t%s (%s)\t\n", a.Name, strings.ToUpper(a.Symbol), b.Name, strings.ToUpper(b.Symbol))
+	fmt.Fprintf(w, "Price (%s)\t%s\t%s\t\n", currency, optional(a.CurrentPrice, price), optional(b.CurrentPrice, price))
+	fmt.Fprintf(w, "24h change\t%s\t%s\t\n", optional(a.PriceChangePercentage24h, change), optional(b.PriceChangePercentage24h, change))
+	fmt.Fprintf(w, "Market cap (%s)\t%s\t%s\t\n", currency, optional(a.MarketCap, amount), optional(b.MarketCap, amount))
+	w.Flush()
+
+	switch {
+	case a.MarketCap == nil || b.MarketCap == nil:
+		sb.WriteString("Market caps are not available for both coins, so they can't be ranked\n")
+	case *a.MarketCap == *b.MarketCap:
+		fmt.Fprintf(&sb, "%s and %s have the same market cap\n", a.Name, b.Name)
+	default:
+		larger, smaller := a, b
+		if *b.MarketCap > *a.MarketCap {
+			larger, smaller = b, a
+		}
+		if *smaller.MarketCap > 0 {
+			fmt.Fprintf(&sb, "%s is larger by market cap, %.2f times %s\n", larger.Name, *larger.MarketCap / *smaller.MarketCap, smaller.Name)
+		} else {
+			fmt.Fprintf(&sb, "%s is larger by market cap than %s\n", larger.Name, smaller.Name)
+		}
+	}
+	return sb.String()
+}
+
+// compareCoinsTool returns the handler for the compare_coins tool, fetching both coins' market data with client.
+func compareCoinsTool(client *CryptoClient) func(context.Context, CompareCoinsArguments) (*mcp_golang.ToolResponse, error) {
+	return func(ctx context.Context, arguments CompareCoinsArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", "compare_coins", "coin_a", arguments.CoinA, "coin_b", arguments.CoinB, "currency", arguments.Currency, "locale", arguments.Locale)
+
+		printer, err := localePrinter(arguments.Locale)
+		if err != nil {
+			return toolFailure("error comparing coins", err)
+		}
+
+		coinA := strings.ToLower(strings.TrimSpace(arguments.CoinA))
+		coinB := strings.ToLower(strings.TrimSpace(arguments.CoinB))
+		if coinA == "" || coinB == "" {
+			return toolFailure("error comparing coins", errors.New("coin_a and coin_b are both required"))
+		}
+		if coinA == coinB {
+			return toolFailure("error comparing coins", fmt.Errorf("coin_a and coin_b are both %s", coinA))
+		}
+		// Fall back to the configured default currency if none is specified
+		currency, err := NormalizeCurrency(currencyOrDefault(arguments.Currency))
+		if err != nil {
+			return toolFailure("error comparing coins", err)
+		}
+
+		// Fetch both coins with a single CoinGecko call
+		markets, err := client.CoinMarkets(ctx, []string{coinA, coinB}, currency)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error fetching coin markets", "tool", "compare_coins", "coin_ids", []string{coinA, coinB}, "currency", currency, "error", err)
+			return toolFailure("error comparing coins", err)
+		}
+		a, okA := markets[coinA]
+		b, okB := markets[coinB]
+		switch {
+		case !okA && !okB:
+			err = fmt.Errorf("%w: neither coin_a %s nor coin_b %s", ErrCoinNotFound, coinA, coinB)
+		case !okA:
+			err = fmt.Errorf("%w: coin_a %s", ErrCoinNotFound, coinA)
+		case !okB:
+			err = fmt.Errorf("%w: coin_b %s", ErrCoinNotFound, coinB)
+		}
+		if err != nil {
+			return toolFailure("error comparing coins", err)
+		}
+
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(formatComparison(printer, a, b, currency))), nil
+	}
+}
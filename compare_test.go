@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// cannedComparison is a coins/markets payload for the two coins being compared, in CoinGecko's order by market cap.
+const cannedComparison = `[
+	{"id":"bitcoin","symbol":"btc","name":"Bitcoin","current_price":50000,"market_cap":1000000000000,"market_cap_rank":1,"price_change_percentage_24h":2.5},
+	{"id":"ethereum","symbol":"eth","name":"Ethereum","current_price":3000,"market_cap":400000000000,"market_cap_rank":2,"price_change_percentage_24h":-1.25}
+]`
+
+func TestCompareCoins(t *testing.T) {
+	var ids string
+	transport := &countingTransport{respond: func(req *http.Request) (*http.Response, error) {
+		ids = req.URL.Query().Get("ids")
+		return cannedResponse(req, http.StatusOK, cannedComparison), nil
+	}}
+	// The coins are given in the opposite order to the payload's, so the columns must follow the arguments
+	resp, err := compareCoinsTool(testCryptoClient(transport))(context.Background(), CompareCoinsArguments{CoinA: "Ethereum", CoinB: "bitcoin", Currency: "usd", Locale: "en-US"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := transport.requests.Load(); n != 1 || ids != "ethereum,bitcoin" {
+		t.Errorf("made %d requests for %q, want one for both coins", n, ids)
+	}
+
+	// The table's padding is tabwriter's business, so only the cells are compared
+	want := [][]string{
+		{"Ethereum", "(ETH)", "Bitcoin", "(BTC)"},
+		{"Price", "(USD)", "3,000.00", "50,000.00"},
+		{"24h", "change", "-1.25%", "+2.50%"},
+		{"Market", "cap", "(USD)", "400,000,000,000", "1,000,000,000,000"},
+		{"Bitcoin", "is", "larger", "by", "market", "cap,", "2.50", "times", "Ethereum"},
+	}
+	text := toolText(t, resp)
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d:\n%s", len(lines), len(want), text)
+	}
+	for i, line := range lines {
+		if got := strings.Fields(line); !slices.Equal(got, want[i]) {
+			t.Errorf("line %d is %q, want %q", i+1, got, want[i])
+		}
+	}
+}
+
+func TestCompareCoinsNamesMissingCoin(t *testing.T) {
+	transport := cannedJSON(`[{"id":"bitcoin","symbol":"btc","name":"Bitcoin","current_price":50000,"market_cap":1000000000000}]`)
+	_, err := compareCoinsTool(testCryptoClient(transport))(context.Background(), CompareCoinsArguments{CoinA: "bitcoin", CoinB: "not-a-coin", Currency: "USD"})
+	if !errors.Is(err, ErrCoinNotFound) || !strings.HasPrefix(err.Error(), "error comparing coins: "+ErrCoinNotFound.Error()+": coin_b not-a-coin") {
+		t.Errorf("got error %v, want coin_b named as not found", err)
+	}
+	if _, err := compareCoinsTool(testCryptoClient(transport))(context.Background(), CompareCoinsArguments{CoinA: "bitcoin", CoinB: "Bitcoin"}); err == nil {
+		t.Error("comparing a coin with itself succeeded")
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// defaultMaxConcurrency is how many tool calls the network transports run at once unless overridden.
+var defaultMaxConcurrency = runtime.NumCPU() * 2
+
+// queueTimeout is how long a tool call waits for one of the others to finish once the limit is reached, before
+// it is turned away as busy.
+var queueTimeout = 5 * time.Second
+
+// toolSlots holds a token for every tool call running; its capacity is the concurrency limit. It is set from the
+// config in main before the tools are registered, and nil leaves concurrency unlimited.
+var toolSlots chan struct{}
+
+// newToolSlots returns the slots for at most n tool calls at once, or nil for no limit when n is not positive.
+func newToolSlots(n int) chan struct{} {
+	if n <= 0 {
+		return nil
+	}
+	return make(chan struct{}, n)
+}
+
+// WithConcurrencyLimit makes every invocation of the named tool take one of toolSlots while it runs, so that many
+// clients calling at once can't overwhelm the upstream APIs. A call that finds every slot taken waits up to
+// queueTimeout for one to free up, then fails with ErrServerBusy.
+func WithConcurrencyLimit(name string) Middleware {
+	return func(next ToolHandler) ToolHandler {
+		slots := toolSlots
+		if slots == nil {
+			return next
+		}
+		return func(ctx context.Context, arguments any) (*mcp_golang.ToolResponse, error) {
+			select {
+			case slots <- struct{}{}:
+			default:
+				timer := time.NewTimer(queueTimeout)
+				defer timer.Stop()
+				slog.DebugContext(ctx, "Waiting for a free tool call slot", "tool", name, "max_concurrency", cap(slots))
+				select {
+				case slots <- struct{}{}:
+				case <-timer.C:
+					slog.WarnContext(ctx, "Too many concurrent tool calls", "tool", name, "max_concurrency", cap(slots), "waited", queueTimeout)
+					return toolFailure("error calling "+name, fmt.Errorf("%w with %d other tool calls", ErrServerBusy, cap(slots)))
+				case <-ctx.Done():
+					return nil, context.Cause(ctx)
+				}
+			}
+			defer func() { <-slots }()
+			return next(ctx, arguments)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// withToolSlots sets the concurrency limit to n until the test ends.
+func withToolSlots(t *testing.T, n int) {
+	previous := toolSlots
+	toolSlots = newToolSlots(n)
+	t.Cleanup(func() { toolSlots = previous })
+}
+
+func TestWithConcurrencyLimitCapsInFlightCalls(t *testing.T) {
+	const limit, calls = 3, 20
+	withToolSlots(t, limit)
+
+	var running, peak atomic.Int32
+	h := WithConcurrencyLimit("bitcoin_price")(func(ctx context.Context, arguments any) (*mcp_golang.ToolResponse, error) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent("ok")), nil
+	})
+
+	var wg sync.WaitGroup
+	var failed atomic.Int32
+	for range calls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := h(context.Background(), nil)
+			if err != nil || toolText(t, resp) != "ok" {
+				failed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := failed.Load(); n != 0 {
+		t.Errorf("%d of %d calls failed, want the extra ones to wait their turn", n, calls)
+	}
+	if p := peak.Load(); p > limit {
+		t.Errorf("%d calls ran at once, want at most %d", p, limit)
+	}
+	if n := len(toolSlots); n != 0 {
+		t.Errorf("%d slots still taken after every call returned", n)
+	}
+}
+
+func TestWithConcurrencyLimitStopsWaitingWhenCallerGivesUp(t *testing.T) {
+	withToolSlots(t, 1)
+	release := make(chan struct{})
+	h := WithConcurrencyLimit("bitcoin_price")(func(ctx context.Context, arguments any) (*mcp_golang.ToolResponse, error) {
+		<-release
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent("ok")), nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h(context.Background(), nil)
+	}()
+	for len(toolSlots) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := h(ctx, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want the caller's deadline", err)
+	}
+	close(release)
+	<-done
+}
+
+func TestNewToolSlots(t *testing.T) {
+	if newToolSlots(0) != nil || newToolSlots(-1) != nil {
+		t.Error("a limit that is not positive did not disable the cap")
+	}
+	if got := cap(newToolSlots(4)); got != 4 {
+		t.Errorf("got %d slots, want 4", got)
+	}
+}
+
+func TestWithConcurrencyLimitFailsWhenBusy(t *testing.T) {
+	withToolSlots(t, 1)
+	previous := queueTimeout
+	queueTimeout = 10 * time.Millisecond
+	t.Cleanup(func() { queueTimeout = previous })
+
+	release := make(chan struct{})
+	h := WithConcurrencyLimit("bitcoin_price")(func(ctx context.Context, arguments any) (*mcp_golang.ToolResponse, error) {
+		<-release
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent("ok")), nil
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h(context.Background(), nil)
+	}()
+	for len(toolSlots) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Returning the error is what makes mcp-golang flag the response with isError
+	resp, err := h(context.Background(), nil)
+	if !errors.Is(err, ErrServerBusy) || resp != nil {
+		t.Errorf("got response %v and error %v, want ErrServerBusy", resp, err)
+	}
+	close(release)
+	<-done
+}
@@ -0,0 +1,471 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/metoro-io/mcp-golang/transport"
+	mcphttp "github.com/metoro-io/mcp-golang/transport/http"
+	"gopkg.in/yaml.v3"
+)
+
+// Supported values for the -transport flag.
+const (
+	transportStdio = "stdio"
+	transportSSE   = "sse"
+	transportWS    = "ws"
+)
+
+// httpEndpoint is the path on which the HTTP transport accepts MCP messages.
+const httpEndpoint = "/mcp"
+
+// Duration is a time.Duration that is read from config files as a string such as "30s" or "1m".
+type Duration time.Duration
+
+// UnmarshalText implements encoding.TextUnmarshaler, which both the JSON and YAML decoders use.
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// Config holds the runtime settings of the server.
+// Values come from defaults, then an optional config file, then environment variables, then flags.
+type Config struct {
+	Transport        string   `json:"transport" yaml:"transport"`
+	Addr             string   `json:"addr" yaml:"addr"`
+	CoinGeckoBaseURL string   `json:"coingecko_base_url" yaml:"coingecko_base_url"`
+	CoinGeckoAPIKey  string   `json:"coingecko_api_key" yaml:"coingecko_api_key"`
+	CoinCapBaseURL   string   `json:"coincap_base_url" yaml:"coincap_base_url"`
+	MempoolBaseURL   string   `json:"mempool_base_url" yaml:"mempool_base_url"`
+	FeeVBytes        int      `json:"fee_vbytes" yaml:"fee_vbytes"`
+	LogLevel         string   `json:"log_level" yaml:"log_level"`
+	DefaultCurrency  string   `json:"default_currency" yaml:"default_currency"`
+	MetricsAddr      string   `json:"metrics_addr" yaml:"metrics_addr"`
+	CacheTTL         Duration `json:"cache_ttl" yaml:"cache_ttl"`
+	MaxStale         Duration `json:"max_stale" yaml:"max_stale"`
+	RequestTimeout   Duration `json:"request_timeout" yaml:"request_timeout"`
+	ToolTimeout      Duration `json:"tool_timeout" yaml:"tool_timeout"`
+	RateLimit        float64  `json:"rate_limit" yaml:"rate_limit"`
+	RateBurst        int      `json:"rate_burst" yaml:"rate_burst"`
+	MaxConcurrency   int      `json:"max_concurrency" yaml:"max_concurrency"`
+	BreakerThreshold int      `json:"breaker_threshold" yaml:"breaker_threshold"`
+	BreakerCooldown  Duration `json:"breaker_cooldown" yaml:"breaker_cooldown"`
+	ToolsDir         string   `json:"tools_dir" yaml:"tools_dir"`
+	ManifestHosts    []string `json:"manifest_hosts" yaml:"manifest_hosts"`
+	HTTPGetHosts     []string `json:"http_get_hosts" yaml:"http_get_hosts"`
+	EnabledTools     []string `json:"enabled_tools" yaml:"enabled_tools"`
+	DisabledTools    []string `json:"disabled_tools" yaml:"disabled_tools"`
+	Debug            bool     `json:"debug" yaml:"debug"`
+	Experimental     bool     `json:"experimental" yaml:"experimental"`
+	UserAgent        string   `json:"user_agent" yaml:"user_agent"`
+	Offline          bool     `json:"offline" yaml:"offline"`
+	CacheFile        string   `json:"cache_file" yaml:"cache_file"`
+	WSPath           string   `json:"ws_path" yaml:"ws_path"`
+	PriceTemplate    string   `json:"price_template" yaml:"price_template"`
+	LogBufferLines   int      `json:"log_buffer_lines" yaml:"log_buffer_lines"`
+	CallHistorySize  int      `json:"call_history_size" yaml:"call_history_size"`
+	Warmup           bool     `json:"warmup" yaml:"warmup"`
+	MaxBodySize      int64    `json:"max_body_size" yaml:"max_body_size"`
+	Reconnect        bool     `json:"reconnect" yaml:"reconnect"`
+}
+
+// defaultConfig returns the configuration used when nothing overrides it.
+func defaultConfig() Config {
+	return Config{
+		Transport:        transportStdio,
+		Addr:             ":8080",
+		WSPath:           httpEndpoint,
+		CoinGeckoBaseURL: defaultCoinGeckoBaseURL,
+		CoinCapBaseURL:   defaultCoinCapBaseURL,
+		MempoolBaseURL:   defaultMempoolBaseURL,
+		FeeVBytes:        defaultFeeVBytes,
+		LogLevel:         "info",
+		DefaultCurrency:  fallbackCurrency,
+		CacheTTL:         Duration(defaultCacheTTL),
+		MaxStale:         Duration(defaultMaxStale),
+		RequestTimeout:   Duration(defaultRequestTimeout),
+		ToolTimeout:      Duration(defaultToolTimeout),
+		RateLimit:        1,
+		RateBurst:        5,
+		MaxConcurrency:   defaultMaxConcurrency,
+		BreakerThreshold: defaultBreakerThreshold,
+		BreakerCooldown:  Duration(defaultBreakerCooldown),
+		UserAgent:        defaultUserAgent(),
+		LogBufferLines:   defaultLogBufferLines,
+		CallHistorySize:  defaultCallHistorySize,
+		MaxBodySize:      defaultMaxBodySize,
+	}
+}
+
+// redactedValue replaces secrets when the configuration is shown to clients.
+const redactedValue = "***"
+
+// redacted returns a copy of cfg that is safe to show to clients, with secrets such as the API key masked.
+func (cfg Config) redacted() Config {
+	if cfg.CoinGeckoAPIKey != "" {
+		cfg.CoinGeckoAPIKey = redactedValue
+	}
+	return cfg
+}
+
+// LoadConfig reads a JSON config file, or YAML when the extension is .yaml or .yml, on top of the defaults.
+func LoadConfig(path string) (Config, error) {
+	cfg := defaultConfig()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("error reading config file: %w", err)
+	}
+
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &cfg)
+	default:
+		err = json.Unmarshal(data, &cfg)
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+	cfg.ManifestHosts = splitList(strings.Join(cfg.ManifestHosts, ","))
+	cfg.HTTPGetHosts = splitList(strings.Join(cfg.HTTPGetHosts, ","))
+	cfg.EnabledTools = splitList(strings.Join(cfg.EnabledTools, ","))
+	cfg.DisabledTools = splitList(strings.Join(cfg.DisabledTools, ","))
+	return cfg, nil
+}
+
+// applyEnv overrides cfg with any configuration environment variables that are set.
+func applyEnv(cfg *Config) error {
+	stringVars := map[string]*string{
+		"MCP_TRANSPORT":      &cfg.Transport,
+		"MCP_ADDR":           &cfg.Addr,
+		"COINGECKO_BASE_URL": &cfg.CoinGeckoBaseURL,
+		"COINGECKO_API_KEY":  &cfg.CoinGeckoAPIKey,
+		"COINCAP_BASE_URL":   &cfg.CoinCapBaseURL,
+		"MEMPOOL_BASE_URL":   &cfg.MempoolBaseURL,
+		"LOG_LEVEL":          &cfg.LogLevel,
+		"DEFAULT_CURRENCY":   &cfg.DefaultCurrency,
+		"METRICS_ADDR":       &cfg.MetricsAddr,
+		"TOOLS_DIR":          &cfg.ToolsDir,
+		"MCP_USER_AGENT":     &cfg.UserAgent,
+		"CACHE_FILE":         &cfg.CacheFile,
+		"MCP_WS_PATH":        &cfg.WSPath,
+		"PRICE_TEMPLATE":     &cfg.PriceTemplate,
+	}
+	for key, field := range stringVars {
+		if v := os.Getenv(key); v != "" {
+			*field = v
+		}
+	}
+
+	durations := map[string]*Duration{
+		"CACHE_TTL":        &cfg.CacheTTL,
+		"MAX_STALE":        &cfg.MaxStale,
+		"REQUEST_TIMEOUT":  &cfg.RequestTimeout,
+		"TOOL_TIMEOUT":     &cfg.ToolTimeout,
+		"BREAKER_COOLDOWN": &cfg.BreakerCooldown,
+	}
+	for key, field := range durations {
+		if v := os.Getenv(key); v != "" {
+			if err := field.UnmarshalText([]byte(v)); err != nil {
+				return fmt.Errorf("invalid %s: %w", key, err)
+			}
+		}
+	}
+
+	if v := os.Getenv("RATE_LIMIT"); v != "" {
+		rps, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("invalid RATE_LIMIT: %w", err)
+		}
+		cfg.RateLimit = rps
+	}
+	if v := os.Getenv("RATE_BURST"); v != "" {
+		burst, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid RATE_BURST: %w", err)
+		}
+		cfg.RateBurst = burst
+	}
+	if v := os.Getenv("MAX_CONCURRENCY"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid MAX_CONCURRENCY: %w", err)
+		}
+		cfg.MaxConcurrency = n
+	}
+	if v := os.Getenv("BREAKER_THRESHOLD"); v != "" {
+		threshold, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid BREAKER_THRESHOLD: %w", err)
+		}
+		cfg.BreakerThreshold = threshold
+	}
+	if v := os.Getenv("FEE_VBYTES"); v != "" {
+		vbytes, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid FEE_VBYTES: %w", err)
+		}
+		cfg.FeeVBytes = vbytes
+	}
+	if v := os.Getenv("LOG_BUFFER_LINES"); v != "" {
+		lines, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid LOG_BUFFER_LINES: %w", err)
+		}
+		cfg.LogBufferLines = lines
+	}
+	if v := os.Getenv("CALL_HISTORY_SIZE"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid CALL_HISTORY_SIZE: %w", err)
+		}
+		cfg.CallHistorySize = size
+	}
+	if v := os.Getenv("MAX_BODY_SIZE"); v != "" {
+		size, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid MAX_BODY_SIZE: %w", err)
+		}
+		cfg.MaxBodySize = size
+	}
+	if v := os.Getenv("MCP_DEBUG"); v != "" {
+		debug, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid MCP_DEBUG: %w", err)
+		}
+		cfg.Debug = debug
+	}
+	if v := os.Getenv("MCP_EXPERIMENTAL"); v != "" {
+		experimental, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid MCP_EXPERIMENTAL: %w", err)
+		}
+		cfg.Experimental = experimental
+	}
+	if v := os.Getenv("MCP_OFFLINE"); v != "" {
+		offline, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid MCP_OFFLINE: %w", err)
+		}
+		cfg.Offline = offline
+	}
+	if v := os.Getenv("MCP_WARMUP"); v != "" {
+		warmup, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid MCP_WARMUP: %w", err)
+		}
+		cfg.Warmup = warmup
+	}
+	if v := os.Getenv("MCP_RECONNECT"); v != "" {
+		reconnect, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid MCP_RECONNECT: %w", err)
+		}
+		cfg.Reconnect = reconnect
+	}
+	if v := os.Getenv("MANIFEST_HOSTS"); v != "" {
+		cfg.ManifestHosts = splitList(v)
+	}
+	if v := os.Getenv("HTTP_GET_HOSTS"); v != "" {
+		cfg.HTTPGetHosts = splitList(v)
+	}
+	if v := os.Getenv("MCP_ENABLE"); v != "" {
+		cfg.EnabledTools = splitList(v)
+	}
+	if v := os.Getenv("MCP_DISABLE"); v != "" {
+		cfg.DisabledTools = splitList(v)
+	}
+	return nil
+}
+
+// splitList splits a comma-separated list, trimming whitespace, lowercasing and dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// loadConfig builds the server configuration from the command line arguments, the environment and an optional config file.
+// Flags override environment variables, which override the config file.
+func loadConfig(args []string) (Config, error) {
+	fs := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ExitOnError)
+
+	defaults := defaultConfig()
+	flags := defaults
+	configPath := fs.String("config", "", "Path to a JSON or YAML config file")
+	fs.StringVar(&flags.Transport, "transport", defaults.Transport, "Transport to serve MCP over: stdio, sse or ws (env MCP_TRANSPORT)")
+	fs.StringVar(&flags.Addr, "addr", defaults.Addr, "Address to listen on when using the sse or ws transport (env MCP_ADDR)")
+	fs.BoolVar(&flags.Reconnect, "reconnect", defaults.Reconnect, fmt.Sprintf("With the stdio transport, reopen stdin with backoff when it ends, up to %d times in a row, instead of shutting down (env MCP_RECONNECT)", maxReconnectAttempts))
+	fs.StringVar(&flags.WSPath, "ws-path", defaults.WSPath, "Path on which the ws transport accepts WebSocket connections (env MCP_WS_PATH)")
+	fs.StringVar(&flags.CoinGeckoBaseURL, "coingecko-url", defaults.CoinGeckoBaseURL, "CoinGecko API base URL (env COINGECKO_BASE_URL)")
+	fs.StringVar(&flags.CoinCapBaseURL, "coincap-url", defaults.CoinCapBaseURL, "CoinCap API base URL, the fallback price source while CoinGecko is down; empty disables the fallback (env COINCAP_BASE_URL)")
+	fs.StringVar(&flags.MempoolBaseURL, "mempool-url", defaults.MempoolBaseURL, "mempool.space API base URL, where btc_fee_estimate and btc_network get their data (env MEMPOOL_BASE_URL)")
+	fs.IntVar(&flags.FeeVBytes, "fee-vbytes", defaults.FeeVBytes, "Transaction size in vbytes that btc_fee_estimate assumes unless the call gives one (env FEE_VBYTES)")
+	fs.StringVar(&flags.LogLevel, "log-level", defaults.LogLevel, "Log verbosity: debug, info, warn or error (env LOG_LEVEL)")
+	fs.IntVar(&flags.LogBufferLines, "log-buffer", defaults.LogBufferLines, "How many recent log lines to keep for the logs://recent resource, 0 disables (env LOG_BUFFER_LINES)")
+	fs.IntVar(&flags.CallHistorySize, "call-history", defaults.CallHistorySize, "How many recent tool calls to keep for the call_history tool, 0 disables (env CALL_HISTORY_SIZE)")
+	fs.StringVar(&flags.DefaultCurrency, "default-currency", defaults.DefaultCurrency, "Currency used when a tool call doesn't name one, one of "+strings.Join(supportedCurrencyList(), ", ")+" (env DEFAULT_CURRENCY)")
+	fs.StringVar(&flags.MetricsAddr, "metrics-addr", defaults.MetricsAddr, "Address to serve Prometheus metrics on at /metrics, disabled when empty (env METRICS_ADDR)")
+	fs.DurationVar((*time.Duration)(&flags.CacheTTL), "cache-ttl", time.Duration(defaults.CacheTTL), "How long fetched prices are cached (env CACHE_TTL)")
+	fs.StringVar(&flags.PriceTemplate, "price-template", defaults.PriceTemplate, "Go text/template for the bitcoin_price text, with fields .Price, .Currency, .Time and .Source (env PRICE_TEMPLATE)")
+	fs.StringVar(&flags.CacheFile, "cache-file", defaults.CacheFile, "JSON file the price cache is saved to on shutdown and restored from on startup (env CACHE_FILE)")
+	fs.DurationVar((*time.Duration)(&flags.MaxStale), "max-stale", time.Duration(defaults.MaxStale), "How long past its TTL a cached price may be served while CoinGecko is unavailable, 0 disables (env MAX_STALE)")
+	fs.DurationVar((*time.Duration)(&flags.RequestTimeout), "timeout", time.Duration(defaults.RequestTimeout), "Timeout for upstream API requests (env REQUEST_TIMEOUT)")
+	fs.DurationVar((*time.Duration)(&flags.ToolTimeout), "tool-timeout", time.Duration(defaults.ToolTimeout), "Overall deadline of each tool call, shared by all its upstream requests, 0 disables (env TOOL_TIMEOUT)")
+	fs.Int64Var(&flags.MaxBodySize, "max-body-size", defaults.MaxBodySize, "Largest upstream response body to read, in bytes; larger responses are rejected (env MAX_BODY_SIZE)")
+	fs.Float64Var(&flags.RateLimit, "rate-limit", defaults.RateLimit, "Requests per second allowed for each CoinGecko-backed tool, 0 disables limiting (env RATE_LIMIT)")
+	fs.IntVar(&flags.RateBurst, "rate-burst", defaults.RateBurst, "Burst size for the per-tool rate limiter (env RATE_BURST)")
+	fs.IntVar(&flags.MaxConcurrency, "max-concurrency", defaults.MaxConcurrency, fmt.Sprintf("Most tool calls run at once over the sse and ws transports; further calls wait up to %s, then fail as busy. 0 removes the limit (env MAX_CONCURRENCY)", queueTimeout))
+	fs.IntVar(&flags.BreakerThreshold, "breaker-threshold", defaults.BreakerThreshold, "Consecutive failures of CoinGecko, or of a manifest tool's API, after which its calls are paused for -breaker-cooldown, 0 disables the circuit breaker (env BREAKER_THRESHOLD)")
+	fs.DurationVar((*time.Duration)(&flags.BreakerCooldown), "breaker-cooldown", time.Duration(defaults.BreakerCooldown), "How long calls are paused once a circuit breaker opens, before a trial request (env BREAKER_COOLDOWN)")
+	fs.StringVar(&flags.ToolsDir, "tools-dir", defaults.ToolsDir, "Directory of JSON tool manifests to load, disabled when empty (env TOOLS_DIR)")
+	fs.StringVar(&flags.UserAgent, "user-agent", defaults.UserAgent, "User-Agent header sent to upstream APIs (env MCP_USER_AGENT)")
+	fs.BoolVar(&flags.Warmup, "warmup", defaults.Warmup, "Prefetch the Bitcoin price in "+strings.Join(warmupCurrencies, ", ")+" into the cache on startup (env MCP_WARMUP)")
+	fs.BoolVar(&flags.Offline, "offline", defaults.Offline, "Serve simulated fixture prices and never call upstream APIs, for demos and CI (env MCP_OFFLINE)")
+	fs.BoolVar(&flags.Debug, "debug", defaults.Debug, "Expose debugging tools such as echo; don't enable in production (env MCP_DEBUG)")
+	fs.BoolVar(&flags.Experimental, "experimental", defaults.Experimental, "Register experimental tools such as dca_simulate and portfolio_value (env MCP_EXPERIMENTAL)")
+	manifestHosts := fs.String("manifest-hosts", "", "Comma-separated hosts that manifest tools may call (env MANIFEST_HOSTS)")
+	httpGetHosts := fs.String("http-get-hosts", "", "Comma-separated hosts the http_get tool may fetch, which is only registered when set (env HTTP_GET_HOSTS)")
+	enable := fs.String("enable", "", "Comma-separated tool names to register, leaving out every other tool; can't be combined with -disable (env MCP_ENABLE)")
+	disable := fs.String("disable", "", "Comma-separated tool names not to register (env MCP_DISABLE)")
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	cfg := defaults
+	if *configPath != "" {
+		var err error
+		cfg, err = LoadConfig(*configPath)
+		if err != nil {
+			return Config{}, err
+		}
+	}
+	if err := applyEnv(&cfg); err != nil {
+		return Config{}, err
+	}
+
+	// Only flags given explicitly on the command line take precedence
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "transport":
+			cfg.Transport = flags.Transport
+		case "addr":
+			cfg.Addr = flags.Addr
+		case "reconnect":
+			cfg.Reconnect = flags.Reconnect
+		case "ws-path":
+			cfg.WSPath = flags.WSPath
+		case "coingecko-url":
+			cfg.CoinGeckoBaseURL = flags.CoinGeckoBaseURL
+		case "coincap-url":
+			cfg.CoinCapBaseURL = flags.CoinCapBaseURL
+		case "mempool-url":
+			cfg.MempoolBaseURL = flags.MempoolBaseURL
+		case "fee-vbytes":
+			cfg.FeeVBytes = flags.FeeVBytes
+		case "log-level":
+			cfg.LogLevel = flags.LogLevel
+		case "log-buffer":
+			cfg.LogBufferLines = flags.LogBufferLines
+		case "call-history":
+			cfg.CallHistorySize = flags.CallHistorySize
+		case "default-currency":
+			cfg.DefaultCurrency = flags.DefaultCurrency
+		case "metrics-addr":
+			cfg.MetricsAddr = flags.MetricsAddr
+		case "cache-ttl":
+			cfg.CacheTTL = flags.CacheTTL
+		case "max-stale":
+			cfg.MaxStale = flags.MaxStale
+		case "cache-file":
+			cfg.CacheFile = flags.CacheFile
+		case "price-template":
+			cfg.PriceTemplate = flags.PriceTemplate
+		case "timeout":
+			cfg.RequestTimeout = flags.RequestTimeout
+		case "tool-timeout":
+			cfg.ToolTimeout = flags.ToolTimeout
+		case "max-body-size":
+			cfg.MaxBodySize = flags.MaxBodySize
+		case "rate-limit":
+			cfg.RateLimit = flags.RateLimit
+		case "rate-burst":
+			cfg.RateBurst = flags.RateBurst
+		case "max-concurrency":
+			cfg.MaxConcurrency = flags.MaxConcurrency
+		case "breaker-threshold":
+			cfg.BreakerThreshold = flags.BreakerThreshold
+		case "breaker-cooldown":
+			cfg.BreakerCooldown = flags.BreakerCooldown
+		case "tools-dir":
+			cfg.ToolsDir = flags.ToolsDir
+		case "user-agent":
+			cfg.UserAgent = flags.UserAgent
+		case "warmup":
+			cfg.Warmup = flags.Warmup
+		case "offline":
+			cfg.Offline = flags.Offline
+		case "debug":
+			cfg.Debug = flags.Debug
+		case "experimental":
+			cfg.Experimental = flags.Experimental
+		case "manifest-hosts":
+			cfg.ManifestHosts = splitList(*manifestHosts)
+		case "http-get-hosts":
+			cfg.HTTPGetHosts = splitList(*httpGetHosts)
+		case "enable":
+			cfg.EnabledTools = splitList(*enable)
+		case "disable":
+			cfg.DisabledTools = splitList(*disable)
+		}
+	})
+
+	if len(cfg.EnabledTools) > 0 && len(cfg.DisabledTools) > 0 {
+		return Config{}, fmt.Errorf("-enable and -disable can't be combined; list only the tools to register with -enable")
+	}
+	return cfg, nil
+}
+
+// buildTransport constructs the MCP transport selected by cfg.
+// mcp-golang v0.8.0 ships its SSE server transport disabled, so "sse" is served by the library's Gin transport;
+// newHTTPRouter mounts it so JSON-RPC messages are accepted as POST requests on /mcp. "ws" is served by wsTransport,
+// which newWSRouter mounts on the configured WebSocket path. "stdio" is served by stdioTransport, which notices when
+// stdin ends and, with -reconnect, reopens it.
+func buildTransport(cfg Config) (transport.Transport, error) {
+	switch cfg.Transport {
+	case transportStdio, "":
+		attempts := 0
+		if cfg.Reconnect {
+			attempts = maxReconnectAttempts
+		}
+		return newStdioTransport((&stdinSource{}).open, os.Stdout, attempts, reconnectBaseDelay), nil
+	case transportSSE:
+		return mcphttp.NewGinTransport(), nil
+	case transportWS:
+		if !strings.HasPrefix(cfg.WSPath, "/") {
+			return nil, fmt.Errorf("invalid WebSocket path %q, it must start with /", cfg.WSPath)
+		}
+		return newWSTransport(), nil
+	default:
+		return nil, fmt.Errorf("unknown transport %q (expected %s, %s or %s)", cfg.Transport, transportStdio, transportSSE, transportWS)
+	}
+}
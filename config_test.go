@@ -0,0 +1,104 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	mcphttp "github.com/metoro-io/mcp-golang/transport/http"
+)
+
+func TestBuildTransport(t *testing.T) {
+	cfg := defaultConfig()
+	for _, name := range []string{"", transportStdio} {
+		cfg.Transport = name
+		tr, err := buildTransport(cfg)
+		if err != nil {
+			t.Fatalf("transport %q: %v", name, err)
+		}
+		if _, ok := tr.(*stdioTransport); !ok {
+			t.Errorf("transport %q built a %T, want *stdioTransport", name, tr)
+		}
+	}
+
+	cfg.Transport = transportSSE
+	if tr, err := buildTransport(cfg); err != nil {
+		t.Errorf("transport sse: %v", err)
+	} else if _, ok := tr.(*mcphttp.GinTransport); !ok {
+		t.Errorf("transport sse built a %T, want *http.GinTransport", tr)
+	}
+
+	cfg.Transport = transportWS
+	if tr, err := buildTransport(cfg); err != nil {
+		t.Errorf("transport ws: %v", err)
+	} else if _, ok := tr.(*wsTransport); !ok {
+		t.Errorf("transport ws built a %T, want *wsTransport", tr)
+	}
+	cfg.WSPath = "ws"
+	if _, err := buildTransport(cfg); err == nil {
+		t.Error("WebSocket path without a leading slash was accepted")
+	}
+
+	cfg.Transport = "carrier-pigeon"
+	if _, err := buildTransport(cfg); err == nil {
+		t.Error("unknown transport was accepted")
+	}
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	yaml := "addr: file:1\nuser_agent: file-agent\ncache_ttl: 5m\n"
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The file alone overrides the defaults
+	cfg, err := loadConfig([]string{"-config", path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Addr != "file:1" || cfg.UserAgent != "file-agent" || time.Duration(cfg.CacheTTL) != 5*time.Minute {
+		t.Errorf("file values not applied: addr %q, user agent %q, cache TTL %v", cfg.Addr, cfg.UserAgent, time.Duration(cfg.CacheTTL))
+	}
+	if cfg.Transport != defaultConfig().Transport {
+		t.Errorf("transport %q, want the default %q", cfg.Transport, defaultConfig().Transport)
+	}
+
+	// The environment overrides the file, and flags override both
+	t.Setenv("MCP_ADDR", "env:2")
+	t.Setenv("MCP_USER_AGENT", "env-agent")
+	cfg, err = loadConfig([]string{"-config", path, "-addr", "flag:3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Addr != "flag:3" {
+		t.Errorf("addr %q, want the flag value flag:3", cfg.Addr)
+	}
+	if cfg.UserAgent != "env-agent" {
+		t.Errorf("user agent %q, want the env value env-agent", cfg.UserAgent)
+	}
+	if time.Duration(cfg.CacheTTL) != 5*time.Minute {
+		t.Errorf("cache TTL %v, want the file value 5m", time.Duration(cfg.CacheTTL))
+	}
+}
+
+func TestLoadConfigEnableAndDisableAreExclusive(t *testing.T) {
+	cfg, err := loadConfig([]string{"-enable", "hello, bitcoin_price"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(cfg.EnabledTools, []string{"hello", "bitcoin_price"}) {
+		t.Errorf("enabled tools %v, want hello and bitcoin_price", cfg.EnabledTools)
+	}
+
+	if _, err := loadConfig([]string{"-enable", "hello", "-disable", "bitcoin_price"}); err == nil || !strings.Contains(err.Error(), "can't be combined") {
+		t.Errorf("got error %v, want -enable and -disable rejected together", err)
+	}
+	t.Setenv("MCP_DISABLE", "bitcoin_price")
+	if _, err := loadConfig([]string{"-enable", "hello"}); err == nil {
+		t.Error("-enable was combined with a disable list from the environment")
+	}
+}
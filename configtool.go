@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/invopop/jsonschema"
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// ConfigArguments defines the (empty) arguments of the config tool.
+type ConfigArguments struct{}
+
+// JSONSchema describes Duration as the string it is encoded as, such as "30s".
+func (Duration) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{Type: "string", Description: "A Go duration such as 30s or 1m"}
+}
+
+// configTool returns the handler for the config tool, which reports cfg after flags, environment variables and
+// the config file have been applied. Secrets are redacted before the configuration is encoded.
+func configTool(cfg Config) func(context.Context, ConfigArguments) (*mcp_golang.ToolResponse, error) {
+	safe := cfg.redacted()
+	return func(ctx context.Context, _ ConfigArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", "config")
+		return NewJSONToolResponse(safe)
+	}
+}
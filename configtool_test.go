@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestConfigToolRedactsAPIKey(t *testing.T) {
+	const key = "CG-secret-key-1234"
+	cfg := defaultConfig()
+	cfg.CoinGeckoAPIKey = key
+	resp, err := configTool(cfg)(context.Background(), ConfigArguments{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := toolText(t, resp)
+	if strings.Contains(text, key) {
+		t.Errorf("config output leaks the API key: %s", text)
+	}
+	if !strings.Contains(text, `"***"`) {
+		t.Errorf("config output has no redacted value: %s", text)
+	}
+	if cfg.CoinGeckoAPIKey != key {
+		t.Error("redacting the output changed the configuration itself")
+	}
+}
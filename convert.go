@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/big"
+	"strings"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// bitcoinCode is the currency code accepted by the convert tool to denote Bitcoin itself.
+const bitcoinCode = "BTC"
+
+// ConvertArguments defines the structure for arguments used to convert an amount between Bitcoin and fiat currencies.
+type ConvertArguments struct {
+	Amount float64 `json:"amount" jsonschema:"required,description=The non-negative amount to convert"`
+	From   string  `json:"from" jsonschema:"required,description=The currency to convert from (BTC, USD, EUR, etc)"`
+	To     string  `json:"to" jsonschema:"required,description=The currency to convert to (BTC, USD, EUR, etc)"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting numeric arguments given as numeric strings too.
+func (a *ConvertArguments) UnmarshalJSON(data []byte) error {
+	type plain ConvertArguments
+	return decodeArguments(data, (*plain)(a))
+}
+
+// ConversionResult is the structured payload returned by the convert tool. Amounts are JSON numbers rounded to the
+// decimals conventional for their currency, so they match what the text tools show.
+type ConversionResult struct {
+	Amount    json.Number `json:"amount" jsonschema:"type=number,description=The amount converted in the from currency"`
+	From      string      `json:"from" jsonschema:"description=The currency converted from: BTC or an ISO 4217 code"`
+	Converted json.Number `json:"converted" jsonschema:"type=number,description=The amount in the to currency"`
+	To        string      `json:"to" jsonschema:"description=The currency converted to: BTC or an ISO 4217 code"`
+	Rate      float64     `json:"rate" jsonschema:"description=How many units of the to currency one unit of the from currency is worth"`
+	Simulated bool        `json:"simulated,omitempty" jsonschema:"description=Set when the server runs in offline mode and the prices are fixture data"`
+}
+
+// normalizeConvertCurrency accepts BTC in addition to the supported fiat currencies.
+func normalizeConvertCurrency(in string) (string, error) {
+	if strings.EqualFold(strings.TrimSpace(in), bitcoinCode) {
+		return bitcoinCode, nil
+	}
+	return NormalizeCurrency(in)
+}
+
+// conversionRate returns how many units of to one unit of from is worth, given Bitcoin prices keyed by fiat code.
+func conversionRate(from, to string, btcPrices map[string]float64) (*big.Rat, error) {
+	price := func(code string) (*big.Rat, error) {
+		p, ok := btcPrices[code]
+		if !ok || p == 0 {
+			return nil, priceUnavailableError(code)
+		}
+		return decimalOf(p), nil
+	}
+
+	switch {
+	case from == to:
+		return big.NewRat(1, 1), nil
+	case from == bitcoinCode:
+		return price(to)
+	case to == bitcoinCode:
+		p, err := price(from)
+		if err != nil {
+			return nil, err
+		}
+		return p.Inv(p), nil
+	default:
+		// Both fiat: go through Bitcoin as the intermediary
+		pFrom, err := price(from)
+		if err != nil {
+			return nil, err
+		}
+		pTo, err := price(to)
+		if err != nil {
+			return nil, err
+		}
+		return pTo.Quo(pTo, pFrom), nil
+	}
+}
+
+// convertTool returns the handler for the convert tool, fetching Bitcoin prices with client and reporting the
+// result as a JSON object.
+func convertTool(client *CryptoClient) func(context.Context, ConvertArguments) (*mcp_golang.ToolResponse, error) {
+	return func(ctx context.Context, arguments ConvertArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", "convert", "amount", arguments.Amount, "from", arguments.From, "to", arguments.To)
+
+		if arguments.Amount < 0 || math.IsNaN(arguments.Amount) || math.IsInf(arguments.Amount, 0) {
+			return toolFailure("error converting", fmt.Errorf("amount must be a non-negative number, got %v", arguments.Amount))
+		}
+		from, err := normalizeConvertCurrency(arguments.From)
+		if err != nil {
+			return toolFailure("error converting", err)
+		}
+		to, err := normalizeConvertCurrency(arguments.To)
+		if err != nil {
+			return toolFailure("error converting", err)
+		}
+
+		// Fetch the Bitcoin price in every fiat currency involved with a single call
+		var fiat []string
+		for _, code := range []string{from, to} {
+			if code != bitcoinCode && (len(fiat) == 0 || fiat[0] != code) {
+				fiat = append(fiat, code)
+			}
+		}
+		btcPrices := map[string]float64{}
+		if len(fiat) > 0 {
+			btcPrices, err = client.CryptoPrices(ctx, "bitcoin", fiat)
+			if err != nil {
+				slog.ErrorContext(ctx, "Error fetching Bitcoin prices", "tool", "convert", "currencies", fiat, "error", err)
+				return toolFailure("error converting", err)
+			}
+		}
+
+		rate, err := conversionRate(from, to, btcPrices)
+		if err != nil {
+			return toolFailure("error converting", err)
+		}
+		amount := decimalOf(arguments.Amount)
+		rateFloat, _ := rate.Float64()
+
+		return NewJSONToolResponse(ConversionResult{
+			Amount:    json.Number(formatDecimal(amount, from)),
+			From:      from,
+			Converted: json.Number(formatDecimal(new(big.Rat).Mul(amount, rate), to)),
+			To:        to,
+			Rate:      rateFloat,
+			Simulated: client.Offline(),
+		})
+	}
+}
+
+// FiatConvertArguments defines the structure for arguments used to convert an amount between two fiat currencies.
+type FiatConvertArguments struct {
+	Amount float64 `json:"amount" jsonschema:"required,description=The non-negative amount to convert"`
+	From   string  `json:"from" jsonschema:"required,description=The fiat currency to convert from (USD, EUR, GBP, etc)"`
+	To     string  `json:"to" jsonschema:"required,description=The fiat currency to convert to (USD, EUR, GBP, etc)"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting numeric arguments given as numeric strings too.
+func (a *FiatConvertArguments) UnmarshalJSON(data []byte) error {
+	type plain FiatConvertArguments
+	return decodeArguments(data, (*plain)(a))
+}
+
+// fiatConvertTool returns the handler for the fiat_convert tool, deriving the cross rate from Bitcoin prices fetched with client.
+func fiatConvertTool(client *CryptoClient) func(context.Context, FiatConvertArguments) (*mcp_golang.ToolResponse, error) {
+	return func(ctx context.Context, arguments FiatConvertArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", "fiat_convert", "amount", arguments.Amount, "from", arguments.From, "to", arguments.To)
+
+		if arguments.Amount < 0 || math.IsNaN(arguments.Amount) || math.IsInf(arguments.Amount, 0) {
+			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Error converting: amount must be a non-negative number, got %v", arguments.Amount))), nil
+		}
+		from, err := NormalizeCurrency(arguments.From)
+		if err != nil {
+			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Error converting: %v", err))), nil
+		}
+		to, err := NormalizeCurrency(arguments.To)
+		if err != nil {
+			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Error converting: %v", err))), nil
+		}
+
+		// One call prices Bitcoin in both currencies; the cross rate is their ratio
+		currencies := []string{from}
+		if to != from {
+			currencies = append(currencies, to)
+		}
+		btcPrices, err := client.CryptoPrices(ctx, "bitcoin", currencies)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error fetching Bitcoin prices", "tool", "fiat_convert", "currencies", currencies, "error", err)
+			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Error converting: %s", describeError(err)))), nil
+		}
+
+		rate, err := conversionRate(from, to, btcPrices)
+		if err != nil {
+			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Error converting: %v", err))), nil
+		}
+		amount := decimalOf(arguments.Amount)
+		rateFloat, _ := rate.Float64()
+
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(markSimulated(client, fmt.Sprintf("%s %s = %s %s (rate: 1 %s = %g %s, derived from Bitcoin prices)",
+			formatDecimal(amount, from), from,
+			formatDecimal(new(big.Rat).Mul(amount, rate), to), to,
+			from, rateFloat, to)))), nil
+	}
+}
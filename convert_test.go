@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestConvertFiatToBitcoin(t *testing.T) {
+	tool := convertTool(testCryptoClient(cannedJSON(`{"bitcoin":{"usd":50000}}`)))
+	resp, err := tool(context.Background(), ConvertArguments{Amount: 100, From: "USD", To: "BTC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got ConversionResult
+	if err := json.Unmarshal([]byte(toolText(t, resp)), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := ConversionResult{Amount: "100.00", From: "USD", Converted: "0.00200000", To: "BTC", Rate: 0.00002}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestConvertBitcoinToFiat(t *testing.T) {
+	tool := convertTool(testCryptoClient(cannedJSON(`{"bitcoin":{"jpy":9000000}}`)))
+	resp, err := tool(context.Background(), ConvertArguments{Amount: 0.5, From: "btc", To: "JPY"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got ConversionResult
+	if err := json.Unmarshal([]byte(toolText(t, resp)), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Amount != "0.50000000" || got.From != "BTC" || got.Converted != "4500000" || got.To != "JPY" {
+		t.Errorf("got %+v, want 0.50000000 BTC = 4500000 JPY", got)
+	}
+
+	// The amounts are JSON numbers, not strings
+	if text := toolText(t, resp); !strings.Contains(text, `"converted":4500000`) {
+		t.Errorf("got %s, want converted as a number", text)
+	}
+}
+
+func TestConvertRejectsBadArguments(t *testing.T) {
+	tool := convertTool(testCryptoClient(offlineTransport()))
+	for _, arguments := range []ConvertArguments{{Amount: -1, From: "USD", To: "BTC"}, {Amount: 1, From: "XXX", To: "BTC"}, {Amount: 1, From: "USD", To: "BTC"}} {
+		if _, err := tool(context.Background(), arguments); err == nil || !strings.HasPrefix(err.Error(), "error converting: ") {
+			t.Errorf("%+v: got error %v, want the conversion to fail", arguments, err)
+		}
+	}
+}
+
+func TestFiatConvertUsesCrossRate(t *testing.T) {
+	transport := cannedJSON(`{"bitcoin":{"usd":50000,"eur":40000}}`)
+	tool := fiatConvertTool(testCryptoClient(transport))
+	resp, err := tool(context.Background(), FiatConvertArguments{Amount: 100, From: "USD", To: "EUR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "100.00 USD = 80.00 EUR (rate: 1 USD = 0.8 EUR"
+	if text := toolText(t, resp); !strings.HasPrefix(text, want) {
+		t.Errorf("got %q, want it to start with %q", text, want)
+	}
+	if n := transport.requests.Load(); n != 1 {
+		t.Errorf("made %d requests, want both prices from one call", n)
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// defaultCoinGeckoBaseURL is the public CoinGecko API endpoint used when no base URL is configured.
+const defaultCoinGeckoBaseURL = "https://api.coingecko.com/api/v3"
+
+// coinGeckoProBaseURL is the CoinGecko Pro API endpoint, used instead of the public one when an API key is configured.
+const coinGeckoProBaseURL = "https://pro-api.coingecko.com/api/v3"
+
+// coinGeckoAPIKeyHeader is the header CoinGecko Pro reads the API key from.
+const coinGeckoAPIKeyHeader = "x-cg-pro-api-key"
+
+// CoinGeckoPriceResponse represents the response of the CoinGecko simple/price endpoint, keyed by coin id and then by lowercase currency code.
+type CoinGeckoPriceResponse map[string]map[string]float64
+
+// CryptoClient fetches cryptocurrency prices from the CoinGecko API using a shared HTTP client.
+// Single prices fail over to a fallback source, if one is set, while CoinGecko is unavailable.
+type CryptoClient struct {
+	apiClient
+	fallback PriceProvider
+}
+
+// NewCryptoClient creates a CryptoClient with a 10 second timeout, 3 retries and the public CoinGecko base URL, then applies the given options.
+func NewCryptoClient(opts ...ClientOption) *CryptoClient {
+	return &CryptoClient{
+		apiClient: newAPIClient("CoinGecko", defaultCoinGeckoBaseURL, opts...),
+	}
+}
+
+// WithAPIKey authenticates every request with a CoinGecko Pro API key. An empty key leaves the client unauthenticated.
+// Pro keys only work against the pro host, so a client still pointing at the public endpoint is switched over;
+// a custom base URL set earlier with WithBaseURL is kept as is.
+func WithAPIKey(key string) ClientOption {
+	return func(c *apiClient) {
+		if key == "" {
+			return
+		}
+		if c.baseURL == defaultCoinGeckoBaseURL {
+			c.baseURL = coinGeckoProBaseURL
+		}
+		c.headers.Set(coinGeckoAPIKeyHeader, key)
+	}
+}
+
+// SetFallback sets the source asked for single prices while CoinGecko is unavailable or rate limiting. A nil
+// provider turns failover off.
+func (c *CryptoClient) SetFallback(p PriceProvider) {
+	c.fallback = p
+}
+
+// Name implements PriceProvider.
+func (c *CryptoClient) Name() string {
+	return c.name
+}
+
+// Price implements PriceProvider with CoinGecko alone; CryptoPrice and QuotePrice also fail over to the fallback source.
+func (c *CryptoClient) Price(ctx context.Context, coinID, currency string) (float64, error) {
+	p, err := c.coinGeckoPrice(ctx, coinID, currency)
+	return p.Price, err
+}
+
+// BitcoinPrice retrieves the current Bitcoin price in the specified currency, as QuotePrice does.
+func (c *CryptoClient) BitcoinPrice(ctx context.Context, currency string) (SourcedPrice, error) {
+	return c.QuotePrice(ctx, "bitcoin", currency)
+}
+
+// CryptoPrice retrieves the current price of the coin identified by its CoinGecko id (e.g. "ethereum") in the specified currency.
+// The method returns an error if the coin id is unknown, the currency is unsupported, or the API call fails.
+func (c *CryptoClient) CryptoPrice(ctx context.Context, coinID, currency string) (float64, error) {
+	p, err := c.QuotePrice(ctx, coinID, currency)
+	return p.Price, err
+}
+
+// QuotePrice retrieves the current price of the coin identified by its CoinGecko id in the specified currency,
+// reporting which source served it. CoinGecko is asked first; while it is unavailable or rate limiting, the fallback
+// source is asked instead. When both fail the CoinGecko error is returned, with the fallback's failure added.
+func (c *CryptoClient) QuotePrice(ctx context.Context, coinID, currency string) (SourcedPrice, error) {
+	p, err := c.coinGeckoPrice(ctx, coinID, currency)
+	if err == nil || c.fallback == nil || !shouldFailOver(err) || ctx.Err() != nil {
+		if err == nil {
+			slog.DebugContext(ctx, "Served price", "source", p.Source, "coin_id", coinID, "currency", currency)
+		}
+		return p, err
+	}
+
+	price, fallbackErr := c.fallback.Price(ctx, coinID, currency)
+	if fallbackErr != nil {
+		return SourcedPrice{}, fmt.Errorf("%w; %s fallback also failed: %v", err, c.fallback.Name(), fallbackErr)
+	}
+	slog.WarnContext(ctx, "Served price from fallback source", "source", c.fallback.Name(), "coin_id", coinID, "currency", currency, "primary_error", err)
+	return SourcedPrice{Price: price, Source: c.fallback.Name()}, nil
+}
+
+// coinGeckoPrice retrieves the current price of a coin in one currency from CoinGecko, together with how long
+// CoinGecko allows it to be cached for, or 0 when it doesn't say.
+func (c *CryptoClient) coinGeckoPrice(ctx context.Context, coinID, currency string) (SourcedPrice, error) {
+	prices, maxAge, err := c.cryptoPrices(ctx, coinID, []string{currency})
+	if err != nil {
+		return SourcedPrice{}, err
+	}
+
+	// CoinGecko silently omits currencies it has no price for, so a missing key must not read as a zero price
+	price, ok := prices[currency]
+	if !ok {
+		return SourcedPrice{}, priceUnavailableError(currency)
+	}
+	return SourcedPrice{Price: price, MaxAge: maxAge, Source: c.name}, nil
+}
+
+// CryptoPrices retrieves the current price of a coin in several currencies with a single CoinGecko request.
+// The returned map is keyed by the currencies as passed in; currencies CoinGecko did not return are absent.
+func (c *CryptoClient) CryptoPrices(ctx context.Context, coinID string, currencies []string) (map[string]float64, error) {
+	prices, _, err := c.cryptoPrices(ctx, coinID, currencies)
+	return prices, err
+}
+
+// cryptoPrices is CryptoPrices that also returns the max-age CoinGecko sent with the prices.
+func (c *CryptoClient) cryptoPrices(ctx context.Context, coinID string, currencies []string) (map[string]float64, time.Duration, error) {
+	coinID = strings.ToLower(coinID)
+	data, maxAge, err := c.simplePrice(ctx, []string{coinID}, currencies)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// CoinGecko answers unknown ids with an empty object rather than an error
+	coinPrices, ok := data[coinID]
+	if !ok {
+		return nil, 0, fmt.Errorf("%w: %s", ErrCoinNotFound, coinID)
+	}
+
+	prices := make(map[string]float64, len(currencies))
+	for _, currency := range currencies {
+		if price, ok := coinPrices[strings.ToLower(currency)]; ok {
+			prices[currency] = price
+		}
+	}
+	return prices, maxAge, nil
+}
+
+// CoinPrices retrieves the current price of several coins in one currency with a single CoinGecko request.
+// The returned map is keyed by lowercase coin id; coins CoinGecko doesn't know are absent.
+func (c *CryptoClient) CoinPrices(ctx context.Context, coinIDs []string, currency string) (map[string]float64, error) {
+	data, _, err := c.simplePrice(ctx, coinIDs, []string{currency})
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make(map[string]float64, len(coinIDs))
+	for _, coinID := range coinIDs {
+		if price, ok := data[coinID][strings.ToLower(currency)]; ok {
+			prices[coinID] = price
+		}
+	}
+	return prices, nil
+}
+
+// priceUnavailableError reports that CoinGecko answered without a price for the requested currency.
+func priceUnavailableError(currency string) error {
+	return fmt.Errorf("price for %s not available from upstream", currency)
+}
+
+// simplePrice calls the CoinGecko simple/price endpoint for the given coin ids and currencies, returning the prices
+// and the max-age of the response. Only the coins and currencies we actually need are requested. In offline mode the
+// fixtures answer instead.
+func (c *CryptoClient) simplePrice(ctx context.Context, coinIDs, currencies []string) (CoinGeckoPriceResponse, time.Duration, error) {
+	if c.offline {
+		return offlineSimplePrice(coinIDs, currencies), 0, nil
+	}
+
+	vsCurrencies := make([]string, len(currencies))
+	for i, currency := range currencies {
+		vsCurrencies[i] = strings.ToLower(currency)
+	}
+
+	query := url.Values{}
+	query.Set("ids", strings.Join(coinIDs, ","))
+	query.Set("vs_currencies", strings.Join(vsCurrencies, ","))
+
+	var data CoinGeckoPriceResponse
+	resp, err := c.getJSONResponse(ctx, "/simple/price", query, &data)
+	if err != nil {
+		return nil, 0, err
+	}
+	return data, resp.MaxAge, nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCoinGeckoBaseURLFromEnv(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/simple/price" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"bitcoin":{"eur":12345.67}}`))
+	}))
+	defer server.Close()
+	t.Setenv("COINGECKO_BASE_URL", server.URL)
+
+	cfg, err := loadConfig(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := parsePriceTemplate("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tool := bitcoinPriceTool(NewCryptoClient(WithBaseURL(cfg.CoinGeckoBaseURL)), newPriceCache(defaultCacheTTL, 0), tmpl)
+	resp, err := tool(context.Background(), BitcoinPriceArguments{Currency: "EUR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := toolText(t, resp); !strings.Contains(text, "12,345.67 EUR") {
+		t.Errorf("got %q, want the stubbed price 12,345.67 EUR", text)
+	}
+}
+
+func TestAPIKeyHeaderOnlyWhenConfigured(t *testing.T) {
+	for _, key := range []string{"", "secret-key"} {
+		var header http.Header
+		var host string
+		transport := &countingTransport{respond: func(req *http.Request) (*http.Response, error) {
+			header, host = req.Header, req.URL.Host
+			return cannedResponse(req, http.StatusOK, `{"bitcoin":{"usd":50000}}`), nil
+		}}
+		if _, err := testCryptoClient(transport, WithAPIKey(key)).Price(context.Background(), "bitcoin", "USD"); err != nil {
+			t.Fatal(err)
+		}
+
+		got, sent := header[http.CanonicalHeaderKey(coinGeckoAPIKeyHeader)]
+		switch {
+		case key == "" && sent:
+			t.Errorf("sent %s %q without a key configured", coinGeckoAPIKeyHeader, got)
+		case key != "" && (len(got) != 1 || got[0] != key):
+			t.Errorf("sent %s %q, want %q", coinGeckoAPIKeyHeader, got, key)
+		}
+		if wantPro := key != ""; strings.HasPrefix(host, "pro-api.") != wantPro {
+			t.Errorf("key %q: sent the request to %s", key, host)
+		}
+	}
+}
+
+func TestCoinPricesBatch(t *testing.T) {
+	var ids string
+	transport := &countingTransport{respond: func(req *http.Request) (*http.Response, error) {
+		ids = req.URL.Query().Get("ids")
+		return cannedResponse(req, http.StatusOK, `{"bitcoin":{"usd":50000},"ethereum":{"usd":3000.5},"solana":{"usd":150}}`), nil
+	}}
+	prices, err := testCryptoClient(transport).CoinPrices(context.Background(), []string{"bitcoin", "ethereum", "solana", "notacoin"}, "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]float64{"bitcoin": 50000, "ethereum": 3000.5, "solana": 150}
+	if len(prices) != len(want) {
+		t.Errorf("got %v, want %v", prices, want)
+	}
+	for coin, price := range want {
+		if prices[coin] != price {
+			t.Errorf("%s: got %v, want %v", coin, prices[coin], price)
+		}
+	}
+	if n := transport.requests.Load(); n != 1 {
+		t.Errorf("made %d requests for one batch, want 1", n)
+	}
+	if ids != "bitcoin,ethereum,solana,notacoin" {
+		t.Errorf("requested ids %q", ids)
+	}
+}
+
+func TestPriceMissingCurrency(t *testing.T) {
+	_, err := testCryptoClient(cannedJSON(`{"bitcoin":{"usd":50000}}`)).Price(context.Background(), "bitcoin", "EUR")
+	if err == nil || err.Error() != "price for EUR not available from upstream" {
+		t.Errorf("got error %v, want the EUR price reported as not available", err)
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SupportedCurrencies is the canonical set of fiat currency codes the price tools accept.
+var SupportedCurrencies = map[string]struct{}{
+	"AUD": {},
+	"BRL": {},
+	"CAD": {},
+	"CHF": {},
+	"CNY": {},
+	"DKK": {},
+	"EUR": {},
+	"GBP": {},
+	"HKD": {},
+	"INR": {},
+	"JPY": {},
+	"KRW": {},
+	"MXN": {},
+	"NOK": {},
+	"NZD": {},
+	"PLN": {},
+	"RUB": {},
+	"SEK": {},
+	"SGD": {},
+	"TRY": {},
+	"USD": {},
+	"ZAR": {},
+}
+
+// defaultDecimals is how many decimal places prices are shown with, unless currencyDecimals says otherwise.
+const defaultDecimals = 2
+
+// currencyDecimals lists the currencies conventionally written with other than two decimal places. Bitcoin is
+// divisible down to the satoshi, so its amounts keep all eight places.
+var currencyDecimals = map[string]int{
+	"JPY":       0,
+	"KRW":       0,
+	bitcoinCode: 8,
+}
+
+// decimalsFor returns how many decimal places amounts in currency are shown with.
+func decimalsFor(currency string) int {
+	if d, ok := currencyDecimals[strings.ToUpper(currency)]; ok {
+		return d
+	}
+	return defaultDecimals
+}
+
+// formatAmount formats an amount with the number of decimals conventional for currency.
+func formatAmount(amount float64, currency string) string {
+	return fmt.Sprintf("%.*f", decimalsFor(currency), amount)
+}
+
+// fallbackCurrency is the default currency when none is configured.
+const fallbackCurrency = "USD"
+
+// defaultCurrency is the currency used when a tool call doesn't specify one; it is set from the config in main.
+var defaultCurrency = fallbackCurrency
+
+// currencyOrDefault returns currency, or the default currency if it is empty.
+func currencyOrDefault(currency string) string {
+	if strings.TrimSpace(currency) == "" {
+		return defaultCurrency
+	}
+	return currency
+}
+
+// supportedCurrencyList returns the codes in SupportedCurrencies in alphabetical order.
+func supportedCurrencyList() []string {
+	codes := make([]string, 0, len(SupportedCurrencies))
+	for code := range SupportedCurrencies {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// dollarCurrencies are the supported currencies called dollars, most commonly meant first.
+var dollarCurrencies = []string{"USD", "AUD", "CAD", "HKD", "NZD", "SGD"}
+
+// currencyNames maps lowercase currency names and symbols to the supported codes they can mean, most commonly meant
+// first. Names that fit more than one currency, such as "dollar", list all of them.
+var currencyNames = map[string][]string{
+	"$": dollarCurrencies, "dollar": dollarCurrencies, "buck": {"USD"},
+	"us$": {"USD"}, "us dollar": {"USD"}, "american dollar": {"USD"},
+	"a$": {"AUD"}, "australian dollar": {"AUD"},
+	"c$": {"CAD"}, "canadian dollar": {"CAD"},
+	"hk$": {"HKD"}, "hong kong dollar": {"HKD"},
+	"nz$": {"NZD"}, "new zealand dollar": {"NZD"},
+	"s$": {"SGD"}, "singapore dollar": {"SGD"},
+	"€": {"EUR"}, "euro": {"EUR"},
+	"£": {"GBP"}, "pound": {"GBP"}, "pound sterling": {"GBP"}, "sterling": {"GBP"}, "british pound": {"GBP"}, "quid": {"GBP"},
+	"¥": {"JPY", "CNY"}, "yen": {"JPY"}, "japanese yen": {"JPY"},
+	"yuan": {"CNY"}, "renminbi": {"CNY"}, "rmb": {"CNY"}, "chinese yuan": {"CNY"},
+	"kr": {"SEK", "NOK", "DKK"}, "krona": {"SEK"}, "kronor": {"SEK"}, "swedish krona": {"SEK"},
+	"krone": {"NOK", "DKK"}, "kroner": {"NOK", "DKK"}, "norwegian krone": {"NOK"}, "danish krone": {"DKK"},
+	"franc": {"CHF"}, "swiss franc": {"CHF"},
+	"₹": {"INR"}, "rupee": {"INR"}, "indian rupee": {"INR"},
+	"₩": {"KRW"}, "won": {"KRW"}, "korean won": {"KRW"}, "south korean won": {"KRW"},
+	"r$": {"BRL"}, "real": {"BRL"}, "reais": {"BRL"}, "brazilian real": {"BRL"},
+	"peso": {"MXN"}, "mexican peso": {"MXN"},
+	"zł": {"PLN"}, "zloty": {"PLN"}, "polish zloty": {"PLN"},
+	"₽": {"RUB"}, "ruble": {"RUB"}, "rouble": {"RUB"}, "russian ruble": {"RUB"},
+	"₺": {"TRY"}, "lira": {"TRY"}, "turkish lira": {"TRY"},
+	"rand": {"ZAR"}, "south african rand": {"ZAR"},
+}
+
+// resolveCurrency returns the supported codes that in could mean, given a code or a name or symbol from currencyNames.
+// Matching ignores case and extra whitespace, and a plural such as "euros" matches its singular. Unknown input
+// resolves to nothing.
+func resolveCurrency(in string) []string {
+	term := strings.ToLower(strings.Join(strings.Fields(in), " "))
+	if code := strings.ToUpper(term); code != "" {
+		if _, ok := SupportedCurrencies[code]; ok {
+			return []string{code}
+		}
+	}
+	if codes, ok := currencyNames[term]; ok {
+		return codes
+	}
+	if singular, ok := strings.CutSuffix(term, "s"); ok {
+		return currencyNames[singular]
+	}
+	return nil
+}
+
+// NormalizeCurrency resolves a currency code, or a name or symbol such as "euro" or "€", to a code in
+// SupportedCurrencies. Names that could mean several currencies, such as "dollar", are rejected with the candidates.
+func NormalizeCurrency(in string) (string, error) {
+	switch codes := resolveCurrency(in); len(codes) {
+	case 0:
+		return "", fmt.Errorf("%w %q, valid codes are: %s (also listed by the %s resource)", ErrUnsupportedCurrency, in, strings.Join(supportedCurrencyList(), ", "), currenciesResourceURI)
+	case 1:
+		return codes[0], nil
+	default:
+		return "", fmt.Errorf("%w %q, it could mean %s; pass one of these codes instead", ErrUnsupportedCurrency, in, strings.Join(codes, ", "))
+	}
+}
+
+// currenciesResourceURI is the URI of the resource listing the supported currencies.
+const currenciesResourceURI = "crypto://currencies"
+
+// currenciesResource returns the content of crypto://currencies: the codes in SupportedCurrencies as a sorted JSON array.
+// It reads the same map NormalizeCurrency validates against, so the resource and the validation can't drift apart.
+func currenciesResource() (string, error) {
+	data, err := json.Marshal(supportedCurrencyList())
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
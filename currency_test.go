@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestNormalizeCurrency(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"USD", "USD"},
+		{"eur", "EUR"},
+		{"  gbp ", "GBP"},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeCurrency(tt.in)
+		if err != nil {
+			t.Errorf("NormalizeCurrency(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeCurrency(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := NormalizeCurrency("XYZ"); !errors.Is(err, ErrUnsupportedCurrency) {
+		t.Errorf("NormalizeCurrency(%q) error = %v, want ErrUnsupportedCurrency", "XYZ", err)
+	}
+}
+
+func TestCurrenciesResource(t *testing.T) {
+	text, err := currenciesResource()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var codes []string
+	if err := json.Unmarshal([]byte(text), &codes); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(codes, "USD") {
+		t.Errorf("USD is missing from %v", codes)
+	}
+	if len(codes) != len(SupportedCurrencies) || !slices.IsSorted(codes) {
+		t.Errorf("got %v, want every supported currency in order", codes)
+	}
+}
+
+func TestFormatAmountUsesCurrencyDecimals(t *testing.T) {
+	tests := []struct {
+		currency string
+		want     string
+	}{
+		{"JPY", "7500001"},
+		{"KRW", "7500001"},
+		{"USD", "7500000.60"},
+		{"EUR", "7500000.60"},
+		{"BTC", "7500000.60000000"},
+	}
+	for _, tt := range tests {
+		if got := formatAmount(7500000.6, tt.currency); got != tt.want {
+			t.Errorf("formatAmount(7500000.6, %s) = %q, want %q", tt.currency, got, tt.want)
+		}
+	}
+
+	p, err := localePrinter("en-US")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := formatPrice(p, 7500000.6, "JPY"); got != "7,500,001" {
+		t.Errorf("JPY price formatted as %q, want 7,500,001", got)
+	}
+	if got := formatPrice(p, 7500000.6, "USD"); got != "7,500,000.60" {
+		t.Errorf("USD price formatted as %q, want 7,500,000.60", got)
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sort"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// maxDCASampleGap is how far the nearest price sample may be from a simulated purchase before the data is too
+// sparse to price it.
+const maxDCASampleGap = 3 * 24 * time.Hour
+
+// DCASimulateArguments defines the structure for arguments used to simulate dollar-cost averaging into Bitcoin.
+type DCASimulateArguments struct {
+	Currency        string  `json:"currency" jsonschema:"description=The currency the purchases are made in (USD, EUR, GBP, etc)"`
+	AmountPerPeriod float64 `json:"amount_per_period" jsonschema:"required,description=How much is spent on Bitcoin at each purchase"`
+	Days            int     `json:"days" jsonschema:"required,description=How many days back the first purchase is made, from 1 to 365"`
+	PeriodDays      int     `json:"period_days" jsonschema:"required,description=How many days apart the purchases are; at most days"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting numeric arguments given as numeric strings too.
+func (a *DCASimulateArguments) UnmarshalJSON(data []byte) error {
+	type plain DCASimulateArguments
+	return decodeArguments(data, (*plain)(a))
+}
+
+// DCAResult is the outcome of a dollar-cost averaging simulation, with the money amounts kept exact.
+type DCAResult struct {
+	Purchases    int
+	Invested     *big.Rat
+	Coins        *big.Rat
+	CurrentValue *big.Rat
+}
+
+// nearestPricePoint returns the point closest in time to t. points must be sorted by time and not empty.
+func nearestPricePoint(points []PricePoint, t time.Time) PricePoint {
+	i := sort.Search(len(points), func(i int) bool {
+		return !points[i].Time.Before(t)
+	})
+	switch {
+	case i == 0:
+		return points[0]
+	case i == len(points):
+		return points[len(points)-1]
+	case t.Sub(points[i-1].Time) <= points[i].Time.Sub(t):
+		return points[i-1]
+	default:
+		return points[i]
+	}
+}
+
+// simulateDCA buys amount worth of coins every period, starting days before the last point and ending before it,
+// each at the price of the nearest sample, and values the coins at the last point's price. points must be
+// sorted by time.
+func simulateDCA(points []PricePoint, amount float64, days, period int) (DCAResult, error) {
+	if len(points) == 0 {
+		return DCAResult{}, fmt.Errorf("no price data is available")
+	}
+	last := points[len(points)-1]
+	start := last.Time.Add(-time.Duration(days) * 24 * time.Hour)
+
+	result := DCAResult{Invested: new(big.Rat), Coins: new(big.Rat), CurrentValue: new(big.Rat)}
+	spend := decimalOf(amount)
+	for t := start; t.Before(last.Time); t = t.Add(time.Duration(period) * 24 * time.Hour) {
+		p := nearestPricePoint(points, t)
+		if gap := p.Time.Sub(t).Abs(); gap > maxDCASampleGap {
+			return DCAResult{}, fmt.Errorf("no price data within %d days of the purchase on %s", int(maxDCASampleGap.Hours()/24), t.Format(isoDateLayout))
+		}
+		if p.Price <= 0 {
+			return DCAResult{}, fmt.Errorf("price on %s is not positive", p.Time.Format(isoDateLayout))
+		}
+		result.Purchases++
+		result.Invested.Add(result.Invested, spend)
+		result.Coins.Add(result.Coins, new(big.Rat).Quo(spend, decimalOf(p.Price)))
+	}
+	result.CurrentValue.Mul(result.Coins, decimalOf(last.Price))
+	return result, nil
+}
+
+// dcaSimulateTool returns the handler for the dca_simulate tool, fetching the price history with client.
+func dcaSimulateTool(client *CryptoClient) func(context.Context, DCASimulateArguments) (*mcp_golang.ToolResponse, error) {
+	return func(ctx context.Context, arguments DCASimulateArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", "dca_simulate", "currency", arguments.Currency, "amount_per_period", arguments.AmountPerPeriod, "days", arguments.Days, "period_days", arguments.PeriodDays)
+
+		// Fall back to the configured default currency if none is specified
+		currency, err := NormalizeCurrency(currencyOrDefault(arguments.Currency))
+		if err != nil {
+			return toolFailure("error simulating DCA", err)
+		}
+		if arguments.AmountPerPeriod <= 0 {
+			return toolFailure("error simulating DCA", fmt.Errorf("amount_per_period must be positive, got %v", arguments.AmountPerPeriod))
+		}
+		if arguments.Days < 1 || arguments.Days > maxMarketChartDays {
+			return toolFailure("error simulating DCA", fmt.Errorf("days must be between 1 and %d, got %d", maxMarketChartDays, arguments.Days))
+		}
+		if arguments.PeriodDays < 1 || arguments.PeriodDays > arguments.Days {
+			return toolFailure("error simulating DCA", fmt.Errorf("period_days must be between 1 and days (%d), got %d", arguments.Days, arguments.PeriodDays))
+		}
+
+		points, err := client.MarketChart(ctx, "bitcoin", currency, arguments.Days)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error fetching Bitcoin market chart", "tool", "dca_simulate", "currency", currency, "days", arguments.Days, "error", err)
+			return toolFailure("error simulating DCA", err)
+		}
+		result, err := simulateDCA(points, arguments.AmountPerPeriod, arguments.Days, arguments.PeriodDays)
+		if err != nil {
+			return toolFailure("error simulating DCA", err)
+		}
+		// The percentage is only shown to two decimals, so float64 is plenty for it
+		gain := new(big.Rat).Sub(result.CurrentValue, result.Invested)
+		change, _ := gain.Quo(gain, result.Invested).Float64()
+		change *= 100
+
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Buying %s %s of Bitcoin every %d day(s) over the last %d day(s) means %d purchase(s) totalling %s %s, accumulating %s BTC now worth %s %s (%+.2f%%)",
+			formatDecimal(decimalOf(arguments.AmountPerPeriod), currency),
+			currency,
+			arguments.PeriodDays,
+			arguments.Days,
+			result.Purchases,
+			formatDecimal(result.Invested, currency),
+			currency,
+			result.Coins.FloatString(8),
+			formatDecimal(result.CurrentValue, currency),
+			currency,
+			change))), nil
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSimulateDCA(t *testing.T) {
+	day := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	var points []PricePoint
+	for i, price := range []float64{100, 200, 100, 200, 400} {
+		points = append(points, PricePoint{Time: day.Add(time.Duration(i) * 24 * time.Hour), Price: price})
+	}
+	tests := []struct {
+		period    int
+		purchases int
+		invested  string
+		coins     string
+		value     string
+	}{
+		// 100 buys 1, 0.5, 1 and 0.5 coins, worth 400 each at the end
+		{1, 4, "400.00", "3.00000000", "1200.00"},
+		{2, 2, "200.00", "2.00000000", "800.00"},
+		{3, 2, "200.00", "1.50000000", "600.00"},
+	}
+	for _, tt := range tests {
+		result, err := simulateDCA(points, 100, 4, tt.period)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Purchases != tt.purchases ||
+			formatDecimal(result.Invested, "USD") != tt.invested ||
+			formatDecimal(result.Coins, bitcoinCode) != tt.coins ||
+			formatDecimal(result.CurrentValue, "USD") != tt.value {
+			t.Errorf("every %d days: got %d purchases, %s invested, %s coins worth %s; want %d, %s, %s, %s",
+				tt.period, result.Purchases, result.Invested.FloatString(2), result.Coins.FloatString(8), result.CurrentValue.FloatString(2),
+				tt.purchases, tt.invested, tt.coins, tt.value)
+		}
+	}
+
+	// A purchase far from any sample can't be priced
+	sparse := []PricePoint{points[0], {Time: day.Add(30 * 24 * time.Hour), Price: 400}}
+	if _, err := simulateDCA(sparse, 100, 30, 10); err == nil {
+		t.Error("purchases 10 days from the nearest sample were priced")
+	}
+}
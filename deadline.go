@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// defaultToolTimeout bounds each tool invocation, every upstream request it makes included, unless overridden.
+const defaultToolTimeout = 15 * time.Second
+
+// toolTimeout is the overall deadline of each tool invocation; it is set from the config in main before the tools
+// are registered. 0 disables the deadline.
+var toolTimeout = defaultToolTimeout
+
+// defaultPhase describes what a tool is doing before it reports a phase of its own.
+const defaultPhase = "handling the call"
+
+// phaseKey is the context key under which WithDeadline stores the phase of the running tool call.
+type phaseKey struct{}
+
+// toolPhase records what a tool call is currently doing, so a timeout can say where the time went.
+type toolPhase struct {
+	mu   sync.Mutex
+	name string
+}
+
+// setPhase records what the tool call running with ctx is doing now, such as "waiting for the CoinGecko API".
+// It does nothing outside a call wrapped by WithDeadline.
+func setPhase(ctx context.Context, phase string) {
+	if p, ok := ctx.Value(phaseKey{}).(*toolPhase); ok {
+		p.mu.Lock()
+		p.name = phase
+		p.mu.Unlock()
+	}
+}
+
+// WithDeadline gives every invocation of the named tool one overall deadline of toolTimeout, shared by all the
+// upstream requests it makes. A call that runs out of time fails with a timeout error naming the phase it was in,
+// instead of whatever the interrupted request happened to produce, so that clients see an isError response.
+func WithDeadline(name string) Middleware {
+	return func(next ToolHandler) ToolHandler {
+		timeout := toolTimeout
+		if timeout <= 0 {
+			return next
+		}
+		return func(ctx context.Context, arguments any) (*mcp_golang.ToolResponse, error) {
+			phase := &toolPhase{name: defaultPhase}
+			callCtx, cancel := context.WithTimeout(context.WithValue(ctx, phaseKey{}, phase), timeout)
+			defer cancel()
+
+			resp, err := next(callCtx, arguments)
+			// Only our own deadline is reported; a caller that gave up has nobody left to tell
+			if !errors.Is(callCtx.Err(), context.DeadlineExceeded) || ctx.Err() != nil {
+				return resp, err
+			}
+
+			phase.mu.Lock()
+			during := phase.name
+			phase.mu.Unlock()
+			slog.WarnContext(ctx, "Tool call timed out", "tool", name, "timeout", timeout, "phase", during)
+			return toolFailure(fmt.Sprintf("the %s tool timed out after %s while %s", name, timeout, during), context.DeadlineExceeded)
+		}
+	}
+}
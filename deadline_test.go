@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithDeadlineTimesOutSlowUpstream(t *testing.T) {
+	previous := toolTimeout
+	toolTimeout = 50 * time.Millisecond
+	t.Cleanup(func() { toolTimeout = previous })
+
+	// The stub only answers once the request is given up on
+	slow := &countingTransport{respond: func(req *http.Request) (*http.Response, error) {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	}}
+	tool := wrapTool("portfolio_value", portfolioValueTool(testCryptoClient(slow)))
+
+	start := time.Now()
+	resp, err := tool(context.Background(), PortfolioValueArguments{Holdings: []Holding{{CoinID: "bitcoin", Amount: 1}}, Currency: "USD"})
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("call took %v, want it cut short by the budget", elapsed)
+	}
+	// Returning the error is what makes mcp-golang flag the response with isError
+	if err == nil {
+		t.Fatalf("got response %+v and no error, want the timeout returned as an error", resp)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want it to wrap context.DeadlineExceeded", err)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "the portfolio_value tool timed out after 50ms while waiting for the CoinGecko API") {
+		t.Errorf("got error %q, want it to name the tool, budget and phase", msg)
+	}
+}
+
+func TestWithDeadlinePassesThroughFastCalls(t *testing.T) {
+	tool := wrapTool("portfolio_value", portfolioValueTool(testCryptoClient(cannedJSON(`{"bitcoin":{"usd":40000}}`))))
+	resp, err := tool(context.Background(), PortfolioValueArguments{Holdings: []Holding{{CoinID: "bitcoin", Amount: 1}}, Currency: "USD"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := toolText(t, resp); !strings.Contains(text, "Total: 40,000.00 USD") {
+		t.Errorf("got %q, want the portfolio total", text)
+	}
+}
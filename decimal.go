@@ -0,0 +1,32 @@
+package main
+
+import (
+	"math/big"
+	"strconv"
+
+	"golang.org/x/text/message"
+)
+
+// Money arithmetic in convert, fiat_convert, portfolio_value and dca_simulate is done on big.Rat rather than
+// float64, so sums and products don't pick up binary rounding errors along the way, and amounts are only rounded
+// once, when formatted. big.Float wouldn't do: it is binary too, so it can't hold 1.005 exactly either.
+
+// decimalOf returns f as the decimal it is written as, such as exactly 1.005 for the float64 nearest to 1.005,
+// rather than that float64's binary value. f must be finite.
+func decimalOf(f float64) *big.Rat {
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	return r
+}
+
+// formatDecimal formats x with the number of decimals conventional for currency, rounding halves away from zero.
+func formatDecimal(x *big.Rat, currency string) string {
+	return x.FloatString(decimalsFor(currency))
+}
+
+// formatDecimalPrice is formatPrice for a decimal: x is rounded as formatDecimal does, then formatted for the
+// printer's locale.
+func formatDecimalPrice(p *message.Printer, x *big.Rat, currency string) string {
+	// The float64 nearest to the rounded value prints back as the same digits
+	rounded, _ := strconv.ParseFloat(formatDecimal(x, currency), 64)
+	return formatPrice(p, rounded, currency)
+}
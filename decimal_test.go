@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestDecimalArithmeticAvoidsFloatMisrounding(t *testing.T) {
+	tests := []struct {
+		amount, price float64
+		currency      string
+		want          string
+	}{
+		// 0.5 * 2.01 is 1.00499999… in float64, which rounds down to two decimals
+		{0.5, 2.01, "USD", "1.01"},
+		{0.5, 2.01, "EUR", "1.01"},
+		// 2.5 is exact in float64, but %.0f rounds halves to even
+		{0.5, 5, "JPY", "3"},
+	}
+	for _, tt := range tests {
+		exact := decimalOf(tt.amount)
+		exact.Mul(exact, decimalOf(tt.price))
+		if got := formatDecimal(exact, tt.currency); got != tt.want {
+			t.Errorf("%v * %v in %s = %s, want %s", tt.amount, tt.price, tt.currency, got, tt.want)
+		}
+		// The same sum in float64, rounded to the currency's own precision, comes out wrong
+		if got := formatAmount(tt.amount*tt.price, tt.currency); got == tt.want {
+			t.Errorf("%v * %v in %s: float64 gave %s too, so the case shows nothing", tt.amount, tt.price, tt.currency, got)
+		}
+	}
+}
+
+func TestConvertRoundsExactly(t *testing.T) {
+	tool := convertTool(testCryptoClient(cannedJSON(`{"bitcoin":{"usd":2.01}}`)))
+	resp, err := tool(context.Background(), ConvertArguments{Amount: 0.5, From: "BTC", To: "USD"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got ConversionResult
+	if err := json.Unmarshal([]byte(toolText(t, resp)), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Converted != "1.01" {
+		t.Errorf("got %s USD, want 1.01 USD", got.Converted)
+	}
+}
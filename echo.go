@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// EchoArguments accepts any JSON object so the echo tool can show exactly what a client sent.
+type EchoArguments map[string]any
+
+// EchoResult is the payload returned by the echo tool.
+type EchoResult struct {
+	Arguments EchoArguments     `json:"arguments"`
+	Types     map[string]string `json:"types"`
+}
+
+// echoTool handles the echo tool, returning the decoded arguments and the Go type of each top-level field.
+// It is only registered with -debug, to help client developers check how their arguments are encoded.
+func echoTool(ctx context.Context, arguments EchoArguments) (*mcp_golang.ToolResponse, error) {
+	slog.DebugContext(ctx, "Received tool request", "tool", "echo")
+
+	types := make(map[string]string, len(arguments))
+	for key, value := range arguments {
+		types[key] = fmt.Sprintf("%T", value)
+	}
+
+	data, err := json.MarshalIndent(EchoResult{Arguments: arguments, Types: types}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(string(data))), nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+
+	"github.com/metoro-io/mcp-golang/transport"
+)
+
+// argumentsEnvelopeKey is the key some clients nest the tool arguments under, sending {"arguments": {...}} as the
+// arguments instead of the arguments themselves.
+const argumentsEnvelopeKey = "arguments"
+
+// envelopeTools are the registered tools whose arguments are unwrapped from an envelope. It is filled in by
+// registerTool before the server starts serving.
+var envelopeTools = make(map[string]bool)
+
+// acceptsEnvelope reports whether arguments of type t can be told apart from an envelope: t must be a struct,
+// since a map such as echo's takes any key, without an argument named like the envelope key.
+func acceptsEnvelope(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if !acceptsEnvelope(field.Type) {
+				return false
+			}
+			continue
+		}
+		if field.IsExported() && jsonFieldName(field) == argumentsEnvelopeKey {
+			return false
+		}
+	}
+	return true
+}
+
+// envelopeTransport passes messages through from the wrapped transport, unwrapping the arguments of tool calls that
+// arrive in an envelope. Without it the library would bind the envelope to the arguments struct, leaving every
+// argument empty, and the tool would answer as if none had been given.
+type envelopeTransport struct {
+	transport.Transport
+}
+
+// unwrapEnvelopes returns t with tool call arguments unwrapped from any envelope before the server sees them.
+func unwrapEnvelopes(t transport.Transport) transport.Transport {
+	return envelopeTransport{Transport: t}
+}
+
+// SetMessageHandler implements transport.Transport.
+func (t envelopeTransport) SetMessageHandler(handler func(ctx context.Context, message *transport.BaseJsonRpcMessage)) {
+	t.Transport.SetMessageHandler(func(ctx context.Context, message *transport.BaseJsonRpcMessage) {
+		unwrapArguments(ctx, message)
+		handler(ctx, message)
+	})
+}
+
+// unwrapArguments replaces the arguments of a tool call with the object nested in them when they consist of an
+// envelope alone. Flat arguments, other messages and tools outside envelopeTools are left untouched.
+func unwrapArguments(ctx context.Context, message *transport.BaseJsonRpcMessage) {
+	if message.Type != transport.BaseMessageTypeJSONRPCRequestType || message.JsonRpcRequest.Method != "tools/call" {
+		return
+	}
+	var params map[string]json.RawMessage
+	if err := json.Unmarshal(message.JsonRpcRequest.Params, &params); err != nil {
+		return
+	}
+	var name string
+	if err := json.Unmarshal(params["name"], &name); err != nil || !envelopeTools[name] {
+		return
+	}
+	var arguments map[string]json.RawMessage
+	if err := json.Unmarshal(params["arguments"], &arguments); err != nil || len(arguments) != 1 {
+		return
+	}
+	inner, ok := arguments[argumentsEnvelopeKey]
+	if !ok || !bytes.HasPrefix(bytes.TrimSpace(inner), []byte("{")) {
+		return
+	}
+
+	params["arguments"] = inner
+	data, err := json.Marshal(params)
+	if err != nil {
+		return
+	}
+	message.JsonRpcRequest.Params = data
+	slog.DebugContext(ctx, "Unwrapped tool arguments from an envelope", "tool", name)
+}
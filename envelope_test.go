@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/metoro-io/mcp-golang/transport"
+)
+
+// boundArguments decodes the arguments of a tools/call message the way the server binds them to a handler.
+func boundArguments[T any](t *testing.T, message *transport.BaseJsonRpcMessage) T {
+	t.Helper()
+	var params struct {
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(message.JsonRpcRequest.Params, &params); err != nil {
+		t.Fatal(err)
+	}
+	var args T
+	if err := json.Unmarshal(params.Arguments, &args); err != nil {
+		t.Fatal(err)
+	}
+	return args
+}
+
+// toolCallWith returns a tools/call request for the named tool with the given raw arguments.
+func toolCallWith(name, arguments string) *transport.BaseJsonRpcMessage {
+	params := `{"name":"` + name + `","arguments":` + arguments + `}`
+	return transport.NewBaseMessageRequest(&transport.BaseJSONRPCRequest{Id: 1, Jsonrpc: "2.0", Method: "tools/call", Params: json.RawMessage(params)})
+}
+
+func TestWrappedAndFlatArgumentsBindTheSame(t *testing.T) {
+	if _, err := registerTestServer(t, defaultConfig()); err != nil {
+		t.Fatal(err)
+	}
+	if !envelopeTools["bitcoin_price"] {
+		t.Fatal("bitcoin_price does not accept an envelope")
+	}
+
+	want := BitcoinPriceArguments{Currency: "EUR", Locale: "de-DE"}
+	for _, arguments := range []string{
+		`{"currency":"EUR","locale":"de-DE"}`,
+		`{"arguments":{"currency":"EUR","locale":"de-DE"}}`,
+		`{ "arguments" : { "currency" : "EUR", "locale" : "de-DE" } }`,
+	} {
+		message := toolCallWith("bitcoin_price", arguments)
+		unwrapArguments(context.Background(), message)
+		if got := boundArguments[BitcoinPriceArguments](t, message); got != want {
+			t.Errorf("arguments %s bound as %+v, want %+v", arguments, got, want)
+		}
+	}
+
+	// The wrapped transport unwraps before the server's handler sees the message
+	var seen *transport.BaseJsonRpcMessage
+	inner := &handlerTransport{}
+	unwrapEnvelopes(inner).SetMessageHandler(func(ctx context.Context, message *transport.BaseJsonRpcMessage) { seen = message })
+	inner.handler(context.Background(), toolCallWith("bitcoin_price", `{"arguments":{"currency":"EUR","locale":"de-DE"}}`))
+	if got := boundArguments[BitcoinPriceArguments](t, seen); got != want {
+		t.Errorf("through the transport the arguments bound as %+v, want %+v", got, want)
+	}
+}
+
+func TestUnwrapArgumentsLeavesOtherPayloadsAlone(t *testing.T) {
+	if _, err := registerTestServer(t, defaultConfig()); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct{ name, arguments string }{
+		// Other keys beside the envelope mean these are flat arguments
+		{"bitcoin_price", `{"arguments":{"currency":"EUR"},"locale":"de-DE"}`},
+		// An envelope must hold an object
+		{"bitcoin_price", `{"arguments":"EUR"}`},
+		// Tools that weren't registered for it are never unwrapped
+		{"no_such_tool", `{"arguments":{"currency":"EUR"}}`},
+	} {
+		message := toolCallWith(tt.name, tt.arguments)
+		before := string(message.JsonRpcRequest.Params)
+		unwrapArguments(context.Background(), message)
+		if after := string(message.JsonRpcRequest.Params); after != before {
+			t.Errorf("%s with %s was rewritten to %s", tt.name, tt.arguments, after)
+		}
+	}
+}
+
+func TestAcceptsEnvelope(t *testing.T) {
+	type withArgumentsField struct {
+		Arguments string `json:"arguments"`
+	}
+	tests := []struct {
+		t    reflect.Type
+		want bool
+	}{
+		{reflect.TypeFor[BitcoinPriceArguments](), true},
+		{reflect.TypeFor[UptimeArguments](), true},
+		{reflect.TypeFor[EchoArguments](), false},
+		{reflect.TypeFor[withArgumentsField](), false},
+	}
+	for _, tt := range tests {
+		if got := acceptsEnvelope(tt.t); got != tt.want {
+			t.Errorf("acceptsEnvelope(%v) = %v, want %v", tt.t, got, tt.want)
+		}
+	}
+}
+
+// handlerTransport is a transport that only keeps the message handler set on it, so a test can deliver messages.
+type handlerTransport struct {
+	transport.Transport
+	handler func(ctx context.Context, message *transport.BaseJsonRpcMessage)
+}
+
+// SetMessageHandler implements transport.Transport.
+func (t *handlerTransport) SetMessageHandler(handler func(ctx context.Context, message *transport.BaseJsonRpcMessage)) {
+	t.handler = handler
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Errors that callers can check for with errors.Is to tell why a lookup failed.
+var (
+	// ErrUnsupportedCurrency means a currency code is not one of SupportedCurrencies.
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	// ErrUpstreamUnavailable means an upstream API could not be reached or answered with a server error.
+	ErrUpstreamUnavailable = errors.New("upstream API unavailable")
+	// ErrRateLimited means an upstream API answered 429 Too Many Requests, even after retrying.
+	ErrRateLimited = errors.New("rate limited by upstream API")
+	// ErrCoinNotFound means CoinGecko does not know the requested coin id.
+	ErrCoinNotFound = errors.New("unknown coin id")
+	// ErrResponseTooLarge means an upstream API sent a response body larger than the client's limit.
+	ErrResponseTooLarge = errors.New("response too large")
+	// ErrServerBusy means this server turned a tool call away because it is at its concurrency or rate limit.
+	ErrServerBusy = errors.New("server busy")
+	// ErrShuttingDown means this server turned a tool call away because it is shutting down.
+	ErrShuttingDown = errors.New("server is shutting down")
+)
+
+// StatusError reports a non-2xx response from an upstream API. It matches ErrRateLimited for 429 responses and
+// ErrUpstreamUnavailable for 5xx responses.
+type StatusError struct {
+	API     string
+	Status  int
+	Snippet string
+}
+
+// Error implements error.
+func (e *StatusError) Error() string {
+	if e.Snippet == "" {
+		return fmt.Sprintf("%s API returned status %d", e.API, e.Status)
+	}
+	return fmt.Sprintf("%s API returned status %d: %s", e.API, e.Status, e.Snippet)
+}
+
+// Unwrap returns the sentinel error matching the status, if any.
+func (e *StatusError) Unwrap() error {
+	switch {
+	case e.Status == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.Status >= 500:
+		return ErrUpstreamUnavailable
+	default:
+		return nil
+	}
+}
+
+// isStatus reports whether err is, or wraps, a StatusError with the given status.
+func isStatus(err error, status int) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.Status == status
+}
+
+// unreachableError wraps a failure to reach an upstream API so that it matches ErrUpstreamUnavailable while
+// keeping the underlying error and its message.
+type unreachableError struct {
+	err error
+}
+
+// Error implements error.
+func (e *unreachableError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error and ErrUpstreamUnavailable.
+func (e *unreachableError) Unwrap() []error {
+	return []error{e.err, ErrUpstreamUnavailable}
+}
+
+// errorHint returns advice to append to err's message for the tool caller, or "" when its cause suggests none.
+func errorHint(err error) string {
+	switch {
+	case errors.Is(err, ErrRateLimited):
+		return " (the upstream API is rate limiting requests; try again in a minute)"
+	case errors.Is(err, ErrUpstreamUnavailable):
+		return " (the upstream API is unavailable right now; try again later)"
+	case errors.Is(err, ErrCoinNotFound):
+		return " (use search_coins to find the coin's id)"
+	case errors.Is(err, ErrServerBusy):
+		return " (try again in a few seconds)"
+	default:
+		return ""
+	}
+}
+
+// describeError returns err's message followed by any hint for the tool caller.
+func describeError(err error) string {
+	return err.Error() + errorHint(err)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestUpstreamErrorsMatchSentinels(t *testing.T) {
+	respond := func(status int, body string) *countingTransport {
+		return &countingTransport{respond: func(req *http.Request) (*http.Response, error) {
+			return cannedResponse(req, status, body), nil
+		}}
+	}
+	tests := []struct {
+		name      string
+		transport http.RoundTripper
+		want      error
+		hint      string
+	}{
+		{"429", respond(http.StatusTooManyRequests, `{}`), ErrRateLimited, "try again in a minute"},
+		{"503", respond(http.StatusServiceUnavailable, `{}`), ErrUpstreamUnavailable, "try again later"},
+		{"unreachable", offlineTransport(), ErrUpstreamUnavailable, "try again later"},
+		{"unknown coin", cannedJSON(`{}`), ErrCoinNotFound, "use search_coins"},
+	}
+	for _, tt := range tests {
+		_, err := testCryptoClient(tt.transport).CryptoPrice(context.Background(), "not-a-coin", "USD")
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: got error %v, want %v", tt.name, err, tt.want)
+			continue
+		}
+		if description := describeError(err); !strings.Contains(description, tt.hint) {
+			t.Errorf("%s: %q has no hint %q", tt.name, description, tt.hint)
+		}
+	}
+
+	// A 404 is neither a rate limit nor an outage
+	_, err := testCryptoClient(respond(http.StatusNotFound, `{}`)).CryptoPrice(context.Background(), "bitcoin", "USD")
+	if errors.Is(err, ErrRateLimited) || errors.Is(err, ErrUpstreamUnavailable) || !isStatus(err, http.StatusNotFound) {
+		t.Errorf("404: got error %v, want a plain status error", err)
+	}
+}
+
+func TestUnsupportedCurrencySentinel(t *testing.T) {
+	transport := cannedJSON(`{"bitcoin":{"usd":50000}}`)
+	tmpl, err := parsePriceTemplate("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = bitcoinPriceTool(testCryptoClient(transport), newPriceCache(defaultCacheTTL, 0), tmpl)(context.Background(), BitcoinPriceArguments{Currency: "XYZ"})
+	if !errors.Is(err, ErrUnsupportedCurrency) {
+		t.Errorf("got error %v, want ErrUnsupportedCurrency", err)
+	}
+	if n := transport.requests.Load(); n != 0 {
+		t.Errorf("made %d requests for an unsupported currency, want none", n)
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// tickersPerPage is how many tickers CoinGecko returns per page of exchanges/{id}/tickers.
+const tickersPerPage = 100
+
+// maxTickerPages bounds how many pages of tickers are read looking for a pair, so a huge exchange can't
+// cost an unbounded number of requests.
+const maxTickerPages = 5
+
+// exchangeIDPattern matches CoinGecko exchange ids such as binance, gdax or crypto_com.
+var exchangeIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// errExchangeNotFound means CoinGecko does not know the requested exchange id.
+var errExchangeNotFound = errors.New("unknown exchange id")
+
+// BitcoinPriceOnExchangeArguments defines the structure for arguments used to request the Bitcoin price on one exchange.
+type BitcoinPriceOnExchangeArguments struct {
+	Exchange string `json:"exchange" jsonschema:"required,description=The CoinGecko id of the exchange (binance, gdax, kraken, etc)"`
+	Currency string `json:"currency" jsonschema:"description=The currency of the BTC pair to look up (USD, EUR, GBP, etc)"`
+	Locale   string `json:"locale" jsonschema:"default=en-US,description=The locale to format the price for (en-US, de-DE, etc)"`
+}
+
+// CoinGeckoTicker represents the parts of a ticker in the CoinGecko exchanges/{id}/tickers response we use.
+type CoinGeckoTicker struct {
+	Base         string  `json:"base"`
+	Target       string  `json:"target"`
+	CoinID       string  `json:"coin_id"`
+	Last         float64 `json:"last"`
+	LastTradedAt string  `json:"last_traded_at"`
+	IsStale      bool    `json:"is_stale"`
+}
+
+// CoinGeckoTickersResponse represents one page of the CoinGecko exchanges/{id}/tickers response.
+type CoinGeckoTickersResponse struct {
+	Name    string            `json:"name"`
+	Tickers []CoinGeckoTicker `json:"tickers"`
+}
+
+// ExchangePrice is the Bitcoin price in one currency on one exchange.
+type ExchangePrice struct {
+	Exchange     string
+	Pair         string
+	Price        float64
+	LastTradedAt time.Time
+}
+
+// BitcoinPriceOnExchange retrieves the last traded price of the BTC/currency pair on the exchange with the given
+// CoinGecko id. Tickers are paginated, so pages are read until the pair turns up, the exchange runs out of
+// tickers or maxTickerPages have been read.
+func (c *CryptoClient) BitcoinPriceOnExchange(ctx context.Context, exchangeID, currency string) (ExchangePrice, error) {
+	exchangeID = strings.ToLower(strings.TrimSpace(exchangeID))
+	if !exchangeIDPattern.MatchString(exchangeID) {
+		return ExchangePrice{}, fmt.Errorf("invalid exchange id %q, expected a CoinGecko id such as binance", exchangeID)
+	}
+
+	name := exchangeID
+	// A stale ticker is only used when no page has a fresh one
+	var stale *CoinGeckoTicker
+	for page := 1; page <= maxTickerPages; page++ {
+		query := url.Values{}
+		query.Set("coin_ids", "bitcoin")
+		query.Set("page", strconv.Itoa(page))
+
+		var data CoinGeckoTickersResponse
+		err := c.getJSON(ctx, "/exchanges/"+url.PathEscape(exchangeID)+"/tickers", query, &data)
+		if isStatus(err, http.StatusNotFound) {
+			return ExchangePrice{}, fmt.Errorf("%w: %s", errExchangeNotFound, exchangeID)
+		}
+		if err != nil {
+			return ExchangePrice{}, err
+		}
+		if data.Name != "" {
+			name = data.Name
+		}
+
+		if ticker, ok := findBitcoinTicker(data.Tickers, currency); ok {
+			if !ticker.IsStale {
+				return exchangePrice(name, ticker), nil
+			}
+			if stale == nil {
+				stale = &ticker
+			}
+		}
+		if len(data.Tickers) < tickersPerPage {
+			break
+		}
+	}
+	if stale != nil {
+		return exchangePrice(name, *stale), nil
+	}
+	return ExchangePrice{}, fmt.Errorf("%s does not list a BTC/%s pair", name, strings.ToUpper(currency))
+}
+
+// exchangePrice builds the ExchangePrice of ticker on the named exchange.
+func exchangePrice(exchange string, ticker CoinGeckoTicker) ExchangePrice {
+	// The trade time is only informative, so a missing or odd one is left out rather than failing the lookup
+	tradedAt, _ := time.Parse(time.RFC3339, ticker.LastTradedAt)
+	return ExchangePrice{
+		Exchange:     exchange,
+		Pair:         ticker.Base + "/" + ticker.Target,
+		Price:        ticker.Last,
+		LastTradedAt: tradedAt,
+	}
+}
+
+// findBitcoinTicker picks the BTC/currency ticker out of tickers, preferring one CoinGecko doesn't flag as stale.
+func findBitcoinTicker(tickers []CoinGeckoTicker, currency string) (CoinGeckoTicker, bool) {
+	var found CoinGeckoTicker
+	ok := false
+	for _, t := range tickers {
+		isBitcoin := t.CoinID == "bitcoin" || strings.EqualFold(t.Base, "BTC")
+		if !isBitcoin || !strings.EqualFold(t.Target, currency) || t.Last <= 0 {
+			continue
+		}
+		if !t.IsStale {
+			return t, true
+		}
+		if !ok {
+			found, ok = t, true
+		}
+	}
+	return found, ok
+}
+
+// bitcoinPriceOnExchangeTool returns the handler for the bitcoin_price_on_exchange tool, fetching the tickers with client.
+func bitcoinPriceOnExchangeTool(client *CryptoClient) func(context.Context, BitcoinPriceOnExchangeArguments) (*mcp_golang.ToolResponse, error) {
+	return func(ctx context.Context, arguments BitcoinPriceOnExchangeArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", "bitcoin_price_on_exchange", "exchange", arguments.Exchange, "currency", arguments.Currency, "locale", arguments.Locale)
+
+		printer, err := localePrinter(arguments.Locale)
+		if err != nil {
+			return toolFailure("error fetching exchange price", err)
+		}
+		// Fall back to the configured default currency if none is specified
+		currency, err := NormalizeCurrency(currencyOrDefault(arguments.Currency))
+		if err != nil {
+			return toolFailure("error fetching exchange price", err)
+		}
+
+		price, err := client.BitcoinPriceOnExchange(ctx, arguments.Exchange, currency)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error fetching exchange price", "tool", "bitcoin_price_on_exchange", "exchange", arguments.Exchange, "currency", currency, "error", err)
+			return toolFailure("error fetching exchange price", err)
+		}
+
+		text := fmt.Sprintf("The Bitcoin price on %s is %s %s (%s)", price.Exchange, formatPrice(printer, price.Price, currency), currency, price.Pair)
+		if !price.LastTradedAt.IsZero() {
+			text += fmt.Sprintf(", last traded at %s", price.LastTradedAt.Format(time.RFC1123))
+		}
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(text)), nil
+	}
+}
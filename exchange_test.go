@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// cannedTickers is a page of exchanges/{id}/tickers with a stale and a fresh BTC/USD pair among others.
+const cannedTickers = `{
+	"name": "Kraken",
+	"tickers": [
+		{"base":"BTC","target":"USDT","coin_id":"bitcoin","last":50010,"last_traded_at":"2024-03-01T09:30:00+00:00","is_stale":false},
+		{"base":"ETH","target":"USD","coin_id":"ethereum","last":3000,"last_traded_at":"2024-03-01T09:30:00+00:00","is_stale":false},
+		{"base":"XBT","target":"USD","coin_id":"bitcoin","last":49000,"last_traded_at":"2024-02-01T09:30:00+00:00","is_stale":true},
+		{"base":"XBT","target":"USD","coin_id":"bitcoin","last":50000.5,"last_traded_at":"2024-03-01T09:30:00+00:00","is_stale":false}
+	]
+}`
+
+func TestBitcoinPriceOnExchangeParsesTickers(t *testing.T) {
+	var path, coinIDs string
+	transport := &countingTransport{respond: func(req *http.Request) (*http.Response, error) {
+		path, coinIDs = req.URL.Path, req.URL.Query().Get("coin_ids")
+		return cannedResponse(req, http.StatusOK, cannedTickers), nil
+	}}
+	price, err := testCryptoClient(transport).BitcoinPriceOnExchange(context.Background(), " Kraken ", "usd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(path, "/exchanges/kraken/tickers") || coinIDs != "bitcoin" {
+		t.Errorf("requested %s for coins %q, want kraken's bitcoin tickers", path, coinIDs)
+	}
+	want := ExchangePrice{Exchange: "Kraken", Pair: "XBT/USD", Price: 50000.5, LastTradedAt: time.Date(2024, time.March, 1, 9, 30, 0, 0, time.UTC)}
+	if price.Exchange != want.Exchange || price.Pair != want.Pair || price.Price != want.Price || !price.LastTradedAt.Equal(want.LastTradedAt) {
+		t.Errorf("got %+v, want the fresh XBT/USD ticker %+v", price, want)
+	}
+
+	if _, err := testCryptoClient(transport).BitcoinPriceOnExchange(context.Background(), "kraken", "JPY"); err == nil || err.Error() != "Kraken does not list a BTC/JPY pair" {
+		t.Errorf("got error %v, want the missing pair reported", err)
+	}
+}
+
+func TestBitcoinPriceOnExchangeReadsLaterPages(t *testing.T) {
+	// The first page is full of other pairs, so the second has to be read
+	var full strings.Builder
+	for i := range tickersPerPage {
+		if i > 0 {
+			full.WriteString(",")
+		}
+		fmt.Fprintf(&full, `{"base":"BTC","target":"T%d","coin_id":"bitcoin","last":1}`, i)
+	}
+	transport := &countingTransport{respond: func(req *http.Request) (*http.Response, error) {
+		if req.URL.Query().Get("page") == "1" {
+			return cannedResponse(req, http.StatusOK, `{"name":"Binance","tickers":[`+full.String()+`]}`), nil
+		}
+		return cannedResponse(req, http.StatusOK, `{"name":"Binance","tickers":[{"base":"BTC","target":"EUR","coin_id":"bitcoin","last":46000}]}`), nil
+	}}
+	price, err := testCryptoClient(transport).BitcoinPriceOnExchange(context.Background(), "binance", "EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if price.Price != 46000 || price.Pair != "BTC/EUR" {
+		t.Errorf("got %+v, want BTC/EUR at 46000 from page 2", price)
+	}
+	if n := transport.requests.Load(); n != 2 {
+		t.Errorf("made %d requests, want 2 pages", n)
+	}
+}
+
+func TestBitcoinPriceOnExchangeRejectsBadExchanges(t *testing.T) {
+	transport := offlineTransport()
+	if _, err := testCryptoClient(transport).BitcoinPriceOnExchange(context.Background(), "../coins", "USD"); err == nil || !strings.Contains(err.Error(), "invalid exchange id") {
+		t.Errorf("got error %v, want the exchange id rejected", err)
+	}
+	if n := transport.requests.Load(); n != 0 {
+		t.Errorf("made %d requests for an invalid exchange id", n)
+	}
+
+	notFound := &countingTransport{respond: func(req *http.Request) (*http.Response, error) {
+		return cannedResponse(req, http.StatusNotFound, `{"error":"exchange not found"}`), nil
+	}}
+	if _, err := testCryptoClient(notFound).BitcoinPriceOnExchange(context.Background(), "nowhere", "USD"); !errors.Is(err, errExchangeNotFound) {
+		t.Errorf("got error %v, want errExchangeNotFound", err)
+	}
+}
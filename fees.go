@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// defaultMempoolBaseURL is the public mempool.space API endpoint the fee rates are fetched from unless configured otherwise.
+const defaultMempoolBaseURL = "https://mempool.space/api"
+
+// defaultFeeVBytes is the size btc_fee_estimate assumes unless told otherwise: about a transaction spending one
+// native SegWit input to two outputs, a payment and its change.
+const defaultFeeVBytes = 140
+
+// maxFeeVBytes bounds the transaction size btc_fee_estimate accepts, the standardness limit of 100,000 vbytes.
+const maxFeeVBytes = 100_000
+
+// satsPerBTC is the number of satoshis in one bitcoin.
+const satsPerBTC = 100_000_000
+
+// Confirmation targets accepted by btc_fee_estimate's speed argument.
+const (
+	feeSpeedFastest  = "fastest"
+	feeSpeedHalfHour = "half_hour"
+	feeSpeedHour     = "hour"
+	feeSpeedEconomy  = "economy"
+)
+
+// feeSpeedDescriptions describe each confirmation target in the tool output.
+var feeSpeedDescriptions = map[string]string{
+	feeSpeedFastest:  "in the next block",
+	feeSpeedHalfHour: "within about 30 minutes",
+	feeSpeedHour:     "within about an hour",
+	feeSpeedEconomy:  "when the mempool clears",
+}
+
+// RecommendedFees represents the mempool.space fees/recommended response, in sat/vB for each confirmation target.
+type RecommendedFees struct {
+	FastestFee  float64 `json:"fastestFee"`
+	HalfHourFee float64 `json:"halfHourFee"`
+	HourFee     float64 `json:"hourFee"`
+	EconomyFee  float64 `json:"economyFee"`
+	MinimumFee  float64 `json:"minimumFee"`
+}
+
+// rate returns the fee rate recommended for speed, one of the feeSpeed constants.
+func (f RecommendedFees) rate(speed string) float64 {
+	switch speed {
+	case feeSpeedFastest:
+		return f.FastestFee
+	case feeSpeedHour:
+		return f.HourFee
+	case feeSpeedEconomy:
+		return f.EconomyFee
+	default:
+		return f.HalfHourFee
+	}
+}
+
+// MempoolClient fetches Bitcoin fee rates from the mempool.space API.
+type MempoolClient struct {
+	apiClient
+}
+
+// NewMempoolClient creates a MempoolClient using the public mempool.space endpoint, then applies the given options.
+func NewMempoolClient(opts ...ClientOption) *MempoolClient {
+	return &MempoolClient{
+		apiClient: newAPIClient("mempool.space", defaultMempoolBaseURL, opts...),
+	}
+}
+
+// RecommendedFees retrieves the currently recommended fee rates.
+func (c *MempoolClient) RecommendedFees(ctx context.Context) (RecommendedFees, error) {
+	var fees RecommendedFees
+	if err := c.getJSON(ctx, "/v1/fees/recommended", nil, &fees); err != nil {
+		return RecommendedFees{}, err
+	}
+	if fees.FastestFee <= 0 {
+		return RecommendedFees{}, fmt.Errorf("%s returned no fee rates", c.name)
+	}
+	return fees, nil
+}
+
+// BTCFeeEstimateArguments defines the structure for arguments used to estimate the fee of a Bitcoin transaction.
+type BTCFeeEstimateArguments struct {
+	Currency string `json:"currency" jsonschema:"description=The currency to show the fee in (USD, EUR, GBP, etc)"`
+	Speed    string `json:"speed" jsonschema:"enum=fastest,enum=half_hour,enum=hour,enum=economy,default=half_hour,description=How soon the transaction should confirm"`
+	VBytes   int    `json:"vbytes" jsonschema:"description=The transaction size in virtual bytes; defaults to the server's typical transaction size"`
+	Locale   string `json:"locale" jsonschema:"default=en-US,description=The locale to format the fee for (en-US, de-DE, etc)"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting numeric arguments given as numeric strings too.
+func (a *BTCFeeEstimateArguments) UnmarshalJSON(data []byte) error {
+	type plain BTCFeeEstimateArguments
+	return decodeArguments(data, (*plain)(a))
+}
+
+// feeSats returns the fee in satoshis for a transaction of vbytes at satPerVByte.
+func feeSats(satPerVByte float64, vbytes int) *big.Rat {
+	return new(big.Rat).Mul(decimalOf(satPerVByte), new(big.Rat).SetInt64(int64(vbytes)))
+}
+
+// satsToBTC converts an amount in satoshis to bitcoin.
+func satsToBTC(sats *big.Rat) *big.Rat {
+	return new(big.Rat).Quo(sats, new(big.Rat).SetInt64(satsPerBTC))
+}
+
+// feeFiat returns the value of a fee of btc bitcoin at btcPrice, the price of one bitcoin.
+func feeFiat(btc *big.Rat, btcPrice float64) *big.Rat {
+	return new(big.Rat).Mul(btc, decimalOf(btcPrice))
+}
+
+// btcFeeEstimateTool returns the handler for the btc_fee_estimate tool, fetching the fee rate with mempool and the
+// Bitcoin price with crypto. Transactions are assumed to be defaultVBytes in size unless the call says otherwise.
+func btcFeeEstimateTool(mempool *MempoolClient, crypto *CryptoClient, defaultVBytes int) func(context.Context, BTCFeeEstimateArguments) (*mcp_golang.ToolResponse, error) {
+	return func(ctx context.Context, arguments BTCFeeEstimateArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", "btc_fee_estimate", "currency", arguments.Currency, "speed", arguments.Speed, "vbytes", arguments.VBytes, "locale", arguments.Locale)
+
+		printer, err := localePrinter(arguments.Locale)
+		if err != nil {
+			return toolFailure("error estimating fee", err)
+		}
+		// Fall back to the configured default currency if none is specified
+		currency, err := NormalizeCurrency(currencyOrDefault(arguments.Currency))
+		if err != nil {
+			return toolFailure("error estimating fee", err)
+		}
+		speed := strings.ToLower(strings.TrimSpace(arguments.Speed))
+		if speed == "" {
+			speed = feeSpeedHalfHour
+		}
+		if _, ok := feeSpeedDescriptions[speed]; !ok {
+			return toolFailure("error estimating fee", fmt.Errorf("unknown speed %q, expected %s, %s, %s or %s", arguments.Speed, feeSpeedFastest, feeSpeedHalfHour, feeSpeedHour, feeSpeedEconomy))
+		}
+		vbytes := arguments.VBytes
+		if vbytes == 0 {
+			vbytes = defaultVBytes
+		}
+		if vbytes < 1 || vbytes > maxFeeVBytes {
+			return toolFailure("error estimating fee", fmt.Errorf("vbytes must be between 1 and %d, got %d", maxFeeVBytes, vbytes))
+		}
+
+		// Without a fee rate there is nothing to estimate, so this failure ends the call
+		fees, err := mempool.RecommendedFees(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error fetching fee rates", "tool", "btc_fee_estimate", "error", err)
+			return toolFailure("error estimating fee", err)
+		}
+		rate := fees.rate(speed)
+		sats := feeSats(rate, vbytes)
+		btc := satsToBTC(sats)
+		wholeSats, _ := sats.Float64()
+
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "Estimated fee for a %s vB transaction confirming %s, at %s sat/vB: %s sats (%s BTC)",
+			printer.Sprintf("%d", vbytes), feeSpeedDescriptions[speed], printer.Sprintf("%v", rate), printer.Sprintf("%.0f", wholeSats), btc.FloatString(8))
+
+		// The fee in sats still answers the question when the price is unavailable, so only note the failure
+		price, err := crypto.BitcoinPrice(ctx, currency)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error fetching Bitcoin price", "tool", "btc_fee_estimate", "currency", currency, "error", err)
+			fmt.Fprintf(&sb, "\nThe value in %s is not available: %s", currency, describeError(err))
+		} else {
+			fmt.Fprintf(&sb, ", about %s %s at 1 BTC = %s %s", formatDecimalPrice(printer, feeFiat(btc, price.Price), currency), currency, formatPrice(printer, price.Price, currency), currency)
+		}
+
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(sb.String())), nil
+	}
+}
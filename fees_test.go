@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// cannedFees is a mempool.space fees/recommended payload.
+const cannedFees = `{"fastestFee":25,"halfHourFee":12,"hourFee":8,"economyFee":2.5,"minimumFee":1}`
+
+// testMempoolClient returns a MempoolClient sending its requests with rt and never retrying.
+func testMempoolClient(rt http.RoundTripper) *MempoolClient {
+	return NewMempoolClient(WithTransport(rt), WithRetries(0), WithRetryBaseDelay(0))
+}
+
+func TestFeeFiat(t *testing.T) {
+	tests := []struct {
+		rate     float64
+		vbytes   int
+		price    float64
+		currency string
+		sats     string
+		btc      string
+		fiat     string
+	}{
+		{12, 140, 50000, "USD", "1680", "0.00001680", "0.84"},
+		{2.5, 141, 50000, "USD", "352.5", "0.00000353", "0.18"},
+		{25, 250, 7500000, "JPY", "6250", "0.00006250", "469"},
+	}
+	for _, tt := range tests {
+		sats := feeSats(tt.rate, tt.vbytes)
+		btc := satsToBTC(sats)
+		if got := sats.FloatString(1); strings.TrimSuffix(got, ".0") != tt.sats {
+			t.Errorf("%v sat/vB * %d vB = %s sats, want %s", tt.rate, tt.vbytes, got, tt.sats)
+		}
+		if got := btc.FloatString(8); got != tt.btc {
+			t.Errorf("%v sat/vB * %d vB = %s BTC, want %s", tt.rate, tt.vbytes, got, tt.btc)
+		}
+		if got := formatDecimal(feeFiat(btc, tt.price), tt.currency); got != tt.fiat {
+			t.Errorf("%s BTC at %v %s = %s, want %s", btc.FloatString(8), tt.price, tt.currency, got, tt.fiat)
+		}
+	}
+}
+
+func TestBTCFeeEstimate(t *testing.T) {
+	tool := btcFeeEstimateTool(testMempoolClient(cannedJSON(cannedFees)), testCryptoClient(cannedJSON(`{"bitcoin":{"usd":50000}}`)), defaultFeeVBytes)
+	resp, err := tool(context.Background(), BTCFeeEstimateArguments{Currency: "USD", Locale: "en-US"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Estimated fee for a 140 vB transaction confirming within about 30 minutes, at 12 sat/vB: 1,680 sats (0.00001680 BTC), about 0.84 USD at 1 BTC = 50,000.00 USD"
+	if got := toolText(t, resp); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestBTCFeeEstimateHandlesEachSourceFailing(t *testing.T) {
+	down := &countingTransport{respond: func(req *http.Request) (*http.Response, error) {
+		return cannedResponse(req, http.StatusServiceUnavailable, `{"error":"maintenance"}`), nil
+	}}
+
+	// Without the price the fee is still given in sats
+	tool := btcFeeEstimateTool(testMempoolClient(cannedJSON(cannedFees)), testCryptoClient(down), defaultFeeVBytes)
+	resp, err := tool(context.Background(), BTCFeeEstimateArguments{Currency: "EUR", Speed: "fastest", VBytes: 200})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := toolText(t, resp)
+	if !strings.Contains(text, "5,000 sats (0.00005000 BTC)") || !strings.Contains(text, "\nThe value in EUR is not available: ") {
+		t.Errorf("got %q, want the fee in sats and the price failure noted", text)
+	}
+
+	// Without the fee rate there is nothing to estimate, and the price is never fetched
+	prices := cannedJSON(`{"bitcoin":{"usd":50000}}`)
+	tool = btcFeeEstimateTool(testMempoolClient(down), testCryptoClient(prices), defaultFeeVBytes)
+	if _, err := tool(context.Background(), BTCFeeEstimateArguments{Currency: "USD"}); !errors.Is(err, ErrUpstreamUnavailable) || !strings.HasPrefix(err.Error(), "error estimating fee: ") {
+		t.Errorf("got error %v, want the fee rate failure returned", err)
+	}
+	if n := prices.requests.Load(); n != 0 {
+		t.Errorf("made %d price requests after the fee rate failed", n)
+	}
+}
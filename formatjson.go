@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// maxFormatJSONSize caps the size of the document format_json accepts, in bytes.
+const maxFormatJSONSize = 1 << 20
+
+// FormatJSONArguments defines the structure for arguments used to validate and pretty-print a JSON document.
+type FormatJSONArguments struct {
+	JSON string `json:"json" jsonschema:"required,description=The JSON document to validate and indent"`
+}
+
+// indentJSON parses a single JSON document and re-encodes it indented by two spaces. Numbers are kept as written
+// rather than going through float64, so large integers and long decimals survive unchanged.
+func indentJSON(in string) (string, error) {
+	if len(in) > maxFormatJSONSize {
+		return "", fmt.Errorf("document is %d bytes, at most %d are allowed", len(in), maxFormatJSONSize)
+	}
+
+	dec := json.NewDecoder(strings.NewReader(in))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return "", describeJSONError(in, err)
+	}
+	// Point at the stray data itself rather than the whitespace before it; Token would move past it
+	offset := dec.InputOffset()
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		rest := in[offset:]
+		offset += int64(len(rest) - len(strings.TrimLeft(rest, " \t\r\n")))
+		line, col := lineColumn(in, offset)
+		return "", fmt.Errorf("unexpected data after the JSON value at offset %d (line %d, column %d)", offset, line, col)
+	}
+
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("error encoding JSON: %w", err)
+	}
+	return string(out), nil
+}
+
+// describeJSONError adds the position of a syntax error to the decoder's message.
+func describeJSONError(in string, err error) error {
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &syntaxErr):
+		// The decoder counts the offending byte as read; report where it is
+		offset := max(syntaxErr.Offset-1, 0)
+		line, col := lineColumn(in, offset)
+		return fmt.Errorf("%v at offset %d (line %d, column %d)", syntaxErr, offset, line, col)
+	case errors.Is(err, io.EOF):
+		return fmt.Errorf("document is empty")
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return fmt.Errorf("document ends before the JSON value is complete")
+	default:
+		return err
+	}
+}
+
+// lineColumn converts a 0-based byte offset into in to the 1-based line and column of that byte.
+func lineColumn(in string, offset int64) (int, int) {
+	if offset > int64(len(in)) {
+		offset = int64(len(in))
+	}
+	before := in[:offset]
+	line := strings.Count(before, "\n") + 1
+	col := len(before) - strings.LastIndex(before, "\n")
+	return line, col
+}
+
+// formatJSONTool handles the format_json tool.
+func formatJSONTool(ctx context.Context, arguments FormatJSONArguments) (*mcp_golang.ToolResponse, error) {
+	slog.DebugContext(ctx, "Received tool request", "tool", "format_json", "size", len(arguments.JSON))
+
+	out, err := indentJSON(arguments.JSON)
+	if err != nil {
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Error: invalid JSON: %v", err))), nil
+	}
+	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(out)), nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestIndentJSON(t *testing.T) {
+	got, err := indentJSON(`{"b":1,"a":[true,null]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n  \"a\": [\n    true,\n    null\n  ],\n  \"b\": 1\n}"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestIndentJSONKeepsLargeNumbers(t *testing.T) {
+	got, err := indentJSON(`[12345678901234567890, 0.10000000000000000555, -1e400]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[\n  12345678901234567890,\n  0.10000000000000000555,\n  -1e400\n]"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestIndentJSONReportsErrorPosition(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"{\n  \"a\": }", "invalid character '}' looking for beginning of value at offset 9 (line 2, column 8)"},
+		{`{"a": 1} {"b": 2}`, "unexpected data after the JSON value at offset 9 (line 1, column 10)"},
+		{`{"a": [1, 2`, "document ends before the JSON value is complete"},
+		{"  ", "document is empty"},
+	}
+	for _, tt := range tests {
+		_, err := indentJSON(tt.in)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("indentJSON(%q): got error %v, want %q", tt.in, err, tt.want)
+		}
+	}
+}
+
+func TestFormatJSONToolReportsInvalidJSON(t *testing.T) {
+	resp, err := formatJSONTool(context.Background(), FormatJSONArguments{JSON: `{"a":}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := toolText(t, resp); !strings.HasPrefix(text, "Error: invalid JSON: ") || !strings.Contains(text, "line 1") {
+		t.Errorf("got %q, want the syntax error and its position", text)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// GlobalMarketArguments defines the structure for arguments used to request the global cryptocurrency market totals.
+type GlobalMarketArguments struct {
+	Currency string `json:"currency" jsonschema:"description=The currency to report the totals in (USD, EUR, GBP, etc)"`
+	Locale   string `json:"locale" jsonschema:"default=en-US,description=The locale to format the amounts for (en-US, de-DE, etc)"`
+}
+
+// CoinGeckoGlobalResponse represents the parts of the CoinGecko global response we use. The totals map lowercase
+// currency codes to a value and the dominance map lowercase coin symbols to a share of the total market cap in percent.
+type CoinGeckoGlobalResponse struct {
+	Data *struct {
+		ActiveCryptocurrencies int                `json:"active_cryptocurrencies"`
+		TotalMarketCap         map[string]float64 `json:"total_market_cap"`
+		TotalVolume            map[string]float64 `json:"total_volume"`
+		MarketCapPercentage    map[string]float64 `json:"market_cap_percentage"`
+	} `json:"data"`
+}
+
+// GlobalMarket are the totals across the whole cryptocurrency market in one currency.
+type GlobalMarket struct {
+	TotalMarketCap         float64
+	TotalVolume24h         float64
+	BTCDominance           float64
+	ActiveCryptocurrencies int
+}
+
+// GlobalMarket retrieves the total market cap, 24h trading volume, Bitcoin dominance and number of active
+// cryptocurrencies across the market.
+func (c *CryptoClient) GlobalMarket(ctx context.Context, currency string) (GlobalMarket, error) {
+	var data CoinGeckoGlobalResponse
+	if err := c.getJSON(ctx, "/global", nil, &data); err != nil {
+		return GlobalMarket{}, err
+	}
+	return parseGlobalMarket(data, currency)
+}
+
+// parseGlobalMarket picks the totals for currency out of the global data. CoinGecko reports the totals in its own
+// set of currencies, so a currency missing from the market cap totals is reported rather than shown as zero.
+func parseGlobalMarket(data CoinGeckoGlobalResponse, currency string) (GlobalMarket, error) {
+	if data.Data == nil {
+		return GlobalMarket{}, fmt.Errorf("CoinGecko returned no global market data")
+	}
+	key := strings.ToLower(currency)
+	marketCap, ok := data.Data.TotalMarketCap[key]
+	if !ok {
+		return GlobalMarket{}, fmt.Errorf("global market totals in %s not available from upstream", currency)
+	}
+	volume, ok := data.Data.TotalVolume[key]
+	if !ok {
+		return GlobalMarket{}, fmt.Errorf("global 24h volume in %s not available from upstream", currency)
+	}
+	dominance, ok := data.Data.MarketCapPercentage["btc"]
+	if !ok {
+		return GlobalMarket{}, fmt.Errorf("Bitcoin dominance not available from upstream")
+	}
+	return GlobalMarket{
+		TotalMarketCap:         marketCap,
+		TotalVolume24h:         volume,
+		BTCDominance:           dominance,
+		ActiveCryptocurrencies: data.Data.ActiveCryptocurrencies,
+	}, nil
+}
+
+// globalMarketTool returns the handler for the global_market tool, fetching the market totals with client.
+func globalMarketTool(client *CryptoClient) func(context.Context, GlobalMarketArguments) (*mcp_golang.ToolResponse, error) {
+	return func(ctx context.Context, arguments GlobalMarketArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", "global_market", "currency", arguments.Currency, "locale", arguments.Locale)
+
+		printer, err := localePrinter(arguments.Locale)
+		if err != nil {
+			return toolFailure("error fetching global market data", err)
+		}
+		// Fall back to the configured default currency if none is specified
+		currency, err := NormalizeCurrency(currencyOrDefault(arguments.Currency))
+		if err != nil {
+			return toolFailure("error fetching global market data", err)
+		}
+
+		market, err := client.GlobalMarket(ctx, currency)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error fetching global market data", "tool", "global_market", "currency", currency, "error", err)
+			return toolFailure("error fetching global market data", err)
+		}
+
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Global cryptocurrency market in %s: total market cap %s, 24h volume %s, Bitcoin dominance %s%%, %s active cryptocurrencies",
+			currency,
+			formatPrice(printer, market.TotalMarketCap, currency),
+			formatPrice(printer, market.TotalVolume24h, currency),
+			printer.Sprintf("%.2f", market.BTCDominance),
+			printer.Sprintf("%d", market.ActiveCryptocurrencies)))), nil
+	}
+}
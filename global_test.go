@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// cannedGlobal is a trimmed /global payload; CoinGecko sends many more currencies and fields.
+const cannedGlobal = `{"data":{
+	"active_cryptocurrencies": 12345,
+	"markets": 1050,
+	"total_market_cap": {"usd": 2500000000000, "eur": 2300000000000},
+	"total_volume": {"usd": 95000000000},
+	"market_cap_percentage": {"btc": 52.123, "eth": 16.5},
+	"market_cap_change_percentage_24h_usd": -1.2,
+	"updated_at": 1709285400
+}}`
+
+func TestGlobalMarketParsesPayload(t *testing.T) {
+	tool := globalMarketTool(testCryptoClient(cannedJSON(cannedGlobal)))
+	resp, err := tool(context.Background(), GlobalMarketArguments{Currency: "USD", Locale: "en-US"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Global cryptocurrency market in USD: total market cap 2,500,000,000,000.00, 24h volume 95,000,000,000.00, Bitcoin dominance 52.12%, 12,345 active cryptocurrencies"
+	if text := toolText(t, resp); text != want {
+		t.Errorf("got %q, want %q", text, want)
+	}
+
+	// The EUR totals lack a volume
+	if _, err := tool(context.Background(), GlobalMarketArguments{Currency: "EUR", Locale: "en-US"}); err == nil || !strings.Contains(err.Error(), "global 24h volume in EUR not available from upstream") {
+		t.Errorf("got error %v, want the missing volume reported", err)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// healthProbeTimeout bounds how long a deep health check waits for CoinGecko.
+const healthProbeTimeout = 3 * time.Second
+
+// Health status values.
+const (
+	statusOK       = "ok"
+	statusDegraded = "degraded"
+)
+
+// startTime is when the server started; it is set at the top of main.
+var startTime time.Time
+
+// HealthArguments defines the structure for arguments used to request the server health.
+type HealthArguments struct {
+	Deep bool `json:"deep" jsonschema:"description=Also check that the CoinGecko API is reachable"`
+}
+
+// HealthStatus is the health report returned by the health tool and the /healthz endpoint.
+type HealthStatus struct {
+	Status   string `json:"status" jsonschema:"description=ok or degraded"`
+	Uptime   string `json:"uptime" jsonschema:"description=How long the server has been running"`
+	Upstream string `json:"upstream,omitempty" jsonschema:"description=CoinGecko reachability; only set on deep checks"`
+}
+
+// uptime returns how long the server has been running, rounded to the second.
+func uptime() time.Duration {
+	return time.Since(startTime).Round(time.Second)
+}
+
+// Ping checks that the CoinGecko API is reachable.
+func (c *CryptoClient) Ping(ctx context.Context) error {
+	var data map[string]any
+	return c.getJSON(ctx, "/ping", nil, &data)
+}
+
+// healthTool returns the handler for the health tool, probing CoinGecko with client on deep checks.
+func healthTool(client *CryptoClient) func(context.Context, HealthArguments) (*mcp_golang.ToolResponse, error) {
+	return func(ctx context.Context, arguments HealthArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", "health", "deep", arguments.Deep)
+
+		status := HealthStatus{Status: statusOK, Uptime: uptime().String()}
+		if arguments.Deep {
+			probeCtx, cancel := context.WithTimeout(ctx, healthProbeTimeout)
+			defer cancel()
+
+			if err := client.Ping(probeCtx); err != nil {
+				slog.WarnContext(ctx, "CoinGecko health probe failed", "error", err)
+				status.Status = statusDegraded
+				status.Upstream = "unreachable: " + err.Error()
+			} else {
+				status.Upstream = "reachable"
+			}
+		}
+
+		return NewJSONToolResponse(status)
+	}
+}
+
+// healthzHandler reports liveness over HTTP for the network transports.
+func healthzHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(HealthStatus{Status: statusOK, Uptime: uptime().String()})
+}
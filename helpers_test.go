@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	mcphttp "github.com/metoro-io/mcp-golang/transport/http"
+)
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper.
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// countingTransport answers every request with respond and counts how many it was sent.
+type countingTransport struct {
+	requests atomic.Int32
+	respond  func(*http.Request) (*http.Response, error)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	return t.respond(req)
+}
+
+// cannedResponse returns a response with the given status and body, sent as JSON.
+func cannedResponse(req *http.Request, status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}
+}
+
+// cannedJSON returns a transport answering every request with status 200 and body.
+func cannedJSON(body string) *countingTransport {
+	return &countingTransport{respond: func(req *http.Request) (*http.Response, error) {
+		return cannedResponse(req, http.StatusOK, body), nil
+	}}
+}
+
+// testCryptoClient returns a CryptoClient sending its requests with rt and never retrying or waiting.
+func testCryptoClient(rt http.RoundTripper, opts ...ClientOption) *CryptoClient {
+	return NewCryptoClient(append([]ClientOption{WithTransport(rt), WithRetries(0), WithRetryBaseDelay(0)}, opts...)...)
+}
+
+// toolText returns the text of every content item of resp, one after another.
+func toolText(t *testing.T, resp *mcp_golang.ToolResponse) string {
+	t.Helper()
+	if resp == nil {
+		t.Fatal("tool returned no response")
+	}
+	var sb strings.Builder
+	for _, content := range resp.Content {
+		if content.TextContent == nil {
+			t.Fatalf("content of type %s is not text", content.Type)
+		}
+		sb.WriteString(content.TextContent.Text)
+	}
+	return sb.String()
+}
+
+// errNoNetwork is what offlineTransport answers every request with.
+var errNoNetwork = errors.New("tests don't touch the network")
+
+// offlineTransport returns a transport failing every request, counting how many were attempted.
+func offlineTransport() *countingTransport {
+	return &countingTransport{respond: func(*http.Request) (*http.Response, error) {
+		return nil, errNoNetwork
+	}}
+}
+
+// resetRegistration clears the package state registerAll fills in, now and when the test ends, so each test
+// registers its tools from scratch.
+func resetRegistration(t *testing.T) {
+	reset := func() {
+		registry, registeredPrompts, registeredResources = nil, nil, nil
+		enabledTools, disabledTools = nil, nil
+		experimentalEnabled, offlineMode = false, false
+		gatedTools, offlineTools, skippedTools = nil, nil, nil
+		envelopeTools = make(map[string]bool)
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+// testServices returns the services main would build for cfg, with every upstream client sending its requests with rt.
+func testServices(t *testing.T, cfg Config, rt http.RoundTripper) *services {
+	t.Helper()
+	tmpl, err := parsePriceTemplate(cfg.PriceTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := []ClientOption{WithTransport(rt), WithRetries(0)}
+	if cfg.Offline {
+		opts = append(opts, WithOffline())
+	}
+	crypto := NewCryptoClient(opts...)
+	return &services{
+		config:        cfg,
+		crypto:        crypto,
+		weather:       NewWeatherClient(opts...),
+		mempool:       NewMempoolClient(opts...),
+		clientOpts:    opts,
+		cache:         newPriceCache(defaultCacheTTL, 0),
+		coins:         newCoinIndex(crypto, coinListTTL),
+		limiters:      newToolLimiters(0, 0),
+		debug:         cfg.Debug,
+		priceTemplate: tmpl,
+	}
+}
+
+// registerTestServer registers every tool, prompt and resource for cfg with a new server, as main does, and
+// returns the server and the error registerAll reported.
+func registerTestServer(t *testing.T, cfg Config) (*mcp_golang.Server, error) {
+	t.Helper()
+	resetRegistration(t)
+	server := mcp_golang.NewServer(mcphttp.NewGinTransport())
+	return server, registerAll(server, testServices(t, cfg, offlineTransport()))
+}
+
+// registeredNames returns the names of the tools in the registry.
+func registeredNames() []string {
+	names := make([]string, len(registry))
+	for i, tool := range registry {
+		names[i] = tool.Name
+	}
+	return names
+}
+
+// captureLogs sends the default logger's output, as JSON lines tagged with request IDs like newLogger's, to a
+// buffer instead of stderr until the test ends.
+func captureLogs(t *testing.T) *logBuffer {
+	buffer := newLogBuffer(1000)
+	previous := slog.Default()
+	slog.SetDefault(slog.New(requestIDHandler{slog.NewJSONHandler(buffer, &slog.HandlerOptions{Level: slog.LevelDebug})}))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return buffer
+}
+
+// resourceText returns the text of the single content item of resp.
+func resourceText(t *testing.T, resp *mcp_golang.ResourceResponse) string {
+	t.Helper()
+	if resp == nil || len(resp.Contents) != 1 || resp.Contents[0].TextResourceContents == nil {
+		t.Fatalf("got resource response %+v, want one text item", resp)
+	}
+	return resp.Contents[0].TextResourceContents.Text
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// isoDateLayout is the date format accepted from tool callers.
+const isoDateLayout = "2006-01-02"
+
+// coinGeckoDateLayout is the date format expected by the CoinGecko history endpoint.
+const coinGeckoDateLayout = "02-01-2006"
+
+// BitcoinPriceOnArguments defines the structure for arguments used to request the Bitcoin price on a past date.
+type BitcoinPriceOnArguments struct {
+	Date     string `json:"date" jsonschema:"required,description=The date to get the price for in YYYY-MM-DD format"`
+	Currency string `json:"currency" jsonschema:"required,description=The currency to get the Bitcoin price in (USD, EUR, GBP, etc)"`
+}
+
+// CoinGeckoHistoryResponse represents the parts of the CoinGecko coins/{id}/history response we use.
+type CoinGeckoHistoryResponse struct {
+	MarketData *struct {
+		CurrentPrice map[string]float64 `json:"current_price"`
+	} `json:"market_data"`
+}
+
+// parseHistoryDate parses an ISO date and rejects dates after today (UTC).
+func parseHistoryDate(in string, now time.Time) (time.Time, error) {
+	date, err := time.Parse(isoDateLayout, strings.TrimSpace(in))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", in)
+	}
+	if date.After(now.UTC()) {
+		return time.Time{}, fmt.Errorf("date %s is in the future", date.Format(isoDateLayout))
+	}
+	return date, nil
+}
+
+// HistoricalPrice retrieves the price of a coin in the specified currency on the given date.
+func (c *CryptoClient) HistoricalPrice(ctx context.Context, coinID string, date time.Time, currency string) (float64, error) {
+	coinID = strings.ToLower(coinID)
+	query := url.Values{}
+	query.Set("date", date.Format(coinGeckoDateLayout))
+	query.Set("localization", "false")
+
+	var data CoinGeckoHistoryResponse
+	err := c.getJSON(ctx, "/coins/"+url.PathEscape(coinID)+"/history", query, &data)
+	if isStatus(err, http.StatusNotFound) {
+		return 0, fmt.Errorf("%w: %s", ErrCoinNotFound, coinID)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// CoinGecko omits market data for dates before the coin was listed
+	if data.MarketData == nil {
+		return 0, fmt.Errorf("CoinGecko has no %s market data for %s", coinID, date.Format(isoDateLayout))
+	}
+	price, ok := data.MarketData.CurrentPrice[strings.ToLower(currency)]
+	if !ok {
+		return 0, fmt.Errorf("%w on %s", priceUnavailableError(currency), date.Format(isoDateLayout))
+	}
+	return price, nil
+}
+
+// bitcoinPriceOnTool returns the handler for the bitcoin_price_on tool, fetching history with client.
+func bitcoinPriceOnTool(client *CryptoClient) func(context.Context, BitcoinPriceOnArguments) (*mcp_golang.ToolResponse, error) {
+	return func(ctx context.Context, arguments BitcoinPriceOnArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", "bitcoin_price_on", "date", arguments.Date, "currency", arguments.Currency)
+
+		// Fall back to the configured default currency if none is specified
+		currency := currencyOrDefault(arguments.Currency)
+		currency, err := NormalizeCurrency(currency)
+		if err != nil {
+			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Error fetching historical Bitcoin price: %v", err))), nil
+		}
+		date, err := parseHistoryDate(arguments.Date, time.Now())
+		if err != nil {
+			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Error fetching historical Bitcoin price: %v", err))), nil
+		}
+
+		price, err := client.HistoricalPrice(ctx, "bitcoin", date, currency)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error fetching historical Bitcoin price", "tool", "bitcoin_price_on", "date", arguments.Date, "currency", currency, "error", err)
+			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Error fetching historical Bitcoin price: %s", describeError(err)))), nil
+		}
+
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("The Bitcoin price on %s was %s %s",
+			date.Format(isoDateLayout),
+			formatAmount(price, currency),
+			currency))), nil
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHistoricalPrice(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/coins/bitcoin/history" {
+			http.NotFound(w, r)
+			return
+		}
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"bitcoin","market_data":{"current_price":{"usd":42000.5,"eur":38000.25}}}`))
+	}))
+	defer server.Close()
+
+	client := NewCryptoClient(WithBaseURL(server.URL))
+	date := time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC)
+	price, err := client.HistoricalPrice(context.Background(), "bitcoin", date, "EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if price != 38000.25 {
+		t.Errorf("got price %v, want 38000.25", price)
+	}
+	if want := "date=15-01-2024&localization=false"; query != want {
+		t.Errorf("sent query %q, want %q", query, want)
+	}
+
+	if _, err := client.HistoricalPrice(context.Background(), "bitcoin", date, "JPY"); err == nil {
+		t.Error("currency missing from the history payload gave no error")
+	}
+}
+
+func TestParseHistoryDate(t *testing.T) {
+	now := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := parseHistoryDate("2024-06-02", now); err == nil {
+		t.Error("future date was accepted")
+	}
+	if _, err := parseHistoryDate("01/06/2024", now); err == nil {
+		t.Error("date in the wrong format was accepted")
+	}
+	if got, err := parseHistoryDate(" 2024-05-31 ", now); err != nil || !got.Equal(time.Date(2024, time.May, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("got %v, %v, want 2024-05-31", got, err)
+	}
+}
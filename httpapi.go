@@ -0,0 +1,324 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// defaultRequestTimeout is the HTTP timeout applied to upstream API requests unless overridden.
+const defaultRequestTimeout = 10 * time.Second
+
+// defaultMaxRetries is how many times a request is retried after a 429 or 5xx response.
+const defaultMaxRetries = 3
+
+// defaultRetryBaseDelay is the first backoff delay; each further retry doubles it.
+const defaultRetryBaseDelay = 200 * time.Millisecond
+
+// maxRetryDelay caps how long a Retry-After header can make the client wait before retrying.
+const maxRetryDelay = 30 * time.Second
+
+// defaultMaxBodySize is the largest response body, in bytes, read from an upstream API unless overridden.
+const defaultMaxBodySize = 1 << 20
+
+// apiClient holds the HTTP plumbing shared by the upstream API clients: timeout, base URL and retry policy.
+type apiClient struct {
+	name           string
+	httpClient     *http.Client
+	baseURL        string
+	maxRetries     int
+	retryBaseDelay time.Duration
+	headers        http.Header
+	offline        bool
+	maxBodySize    int64
+	breaker        *circuitBreaker
+}
+
+// ClientOption configures an upstream API client such as CryptoClient or WeatherClient.
+type ClientOption func(*apiClient)
+
+// WithTimeout sets the timeout used for every request made by the client.
+func WithTimeout(d time.Duration) ClientOption {
+	return func(c *apiClient) {
+		c.httpClient.Timeout = d
+	}
+}
+
+// WithTransport sets the RoundTripper requests are sent with, for example to count or fake them in tests.
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(c *apiClient) {
+		c.httpClient.Transport = rt
+	}
+}
+
+// WithBaseURL sets the API base URL, for example to point the client at a local stub.
+// A trailing slash is trimmed so paths can be appended directly.
+func WithBaseURL(u string) ClientOption {
+	return func(c *apiClient) {
+		c.baseURL = strings.TrimSuffix(u, "/")
+	}
+}
+
+// WithRetries sets how many times a request is retried after a 429 or 5xx response. Zero disables retries.
+func WithRetries(n int) ClientOption {
+	return func(c *apiClient) {
+		c.maxRetries = n
+	}
+}
+
+// WithRetryBaseDelay sets the initial backoff delay between retries, which doubles on every attempt.
+func WithRetryBaseDelay(d time.Duration) ClientOption {
+	return func(c *apiClient) {
+		c.retryBaseDelay = d
+	}
+}
+
+// WithMaxBodySize sets the largest response body, in bytes, the client reads; larger responses are rejected
+// with ErrResponseTooLarge so a misbehaving upstream can't exhaust memory. A non-positive size keeps the default.
+func WithMaxBodySize(n int64) ClientOption {
+	return func(c *apiClient) {
+		if n > 0 {
+			c.maxBodySize = n
+		}
+	}
+}
+
+// WithHeader adds a header that is sent with every request made by the client.
+func WithHeader(key, value string) ClientOption {
+	return func(c *apiClient) {
+		c.headers.Set(key, value)
+	}
+}
+
+// newAPIClient creates an apiClient for the named API with the default timeout, retry policy, body size limit and
+// user agent, then applies opts.
+func newAPIClient(name, baseURL string, opts ...ClientOption) apiClient {
+	c := apiClient{
+		name: name,
+		httpClient: &http.Client{
+			Timeout: defaultRequestTimeout,
+		},
+		baseURL:        baseURL,
+		maxRetries:     defaultMaxRetries,
+		retryBaseDelay: defaultRetryBaseDelay,
+		maxBodySize:    defaultMaxBodySize,
+		headers:        http.Header{"User-Agent": {userAgent}},
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// maxSnippetLength bounds how much of an unexpected response body is quoted in error messages.
+const maxSnippetLength = 200
+
+// apiResponse is a successful response from an upstream API.
+type apiResponse struct {
+	Status      int
+	ContentType string
+	Body        []byte
+	// MaxAge is how long the response may be cached for according to its Cache-Control header, or 0 if it doesn't say.
+	MaxAge time.Duration
+}
+
+// getJSON performs a GET request against the API and decodes the JSON response into v.
+// Bodies that aren't JSON, such as a proxy's HTML error page, are reported with their status and a snippet.
+func (c *apiClient) getJSON(ctx context.Context, path string, query url.Values, v any) error {
+	_, err := c.getJSONResponse(ctx, path, query, v)
+	return err
+}
+
+// getJSONResponse is getJSON that also returns the response, for callers that need more than the decoded body.
+func (c *apiClient) getJSONResponse(ctx context.Context, path string, query url.Values, v any) (apiResponse, error) {
+	resp, err := c.get(ctx, path, query)
+	if err != nil {
+		return apiResponse{}, err
+	}
+	if !looksLikeJSON(resp.ContentType, resp.Body) {
+		return apiResponse{}, fmt.Errorf("%s API returned a non-JSON response (status %d, content type %q): %s", c.name, resp.Status, resp.ContentType, bodySnippet(resp.Body))
+	}
+
+	// Parse JSON response
+	err = json.Unmarshal(resp.Body, v)
+	if err != nil {
+		return apiResponse{}, fmt.Errorf("error parsing JSON response from %s API: %w", c.name, err)
+	}
+	return resp, nil
+}
+
+// get performs a GET request against the API and returns the response. Non-2xx responses are returned as errors.
+// Responses with status 429 or 5xx are retried with exponential backoff, honoring the Retry-After header when present
+// up to maxRetryDelay. A retry that would have to wait past the context's deadline is not made.
+// While the client's circuit breaker is open, get fails straight away instead.
+func (c *apiClient) get(ctx context.Context, path string, query url.Values) (apiResponse, error) {
+	if c.offline {
+		return apiResponse{}, fmt.Errorf("%s API is not available in offline mode", c.name)
+	}
+	trial, err := c.breaker.allow()
+	if err != nil {
+		return apiResponse{}, err
+	}
+	resp, err := c.fetch(ctx, path, query)
+	c.breaker.record(ctx, trial, err)
+	return resp, err
+}
+
+// fetch is get without the offline check and circuit breaker, doing the requests and retries.
+func (c *apiClient) fetch(ctx context.Context, path string, query url.Values) (apiResponse, error) {
+	endpoint := c.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	delay := c.retryBaseDelay
+	for attempt := 0; ; attempt++ {
+		setPhase(ctx, fmt.Sprintf("waiting for the %s API (%s)", c.name, path))
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return apiResponse{}, fmt.Errorf("error creating request: %w", err)
+		}
+		for key, values := range c.headers {
+			req.Header[key] = values
+		}
+
+		// Make request to the API
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			err = fmt.Errorf("error making request to %s API: %w", c.name, err)
+			if ctx.Err() != nil {
+				// The caller gave up; that says nothing about the upstream API
+				return apiResponse{}, err
+			}
+			return apiResponse{}, &unreachableError{err}
+		}
+
+		if isRetryableStatus(resp.StatusCode) && attempt < c.maxRetries {
+			wait := retryAfter(resp.Header.Get("Retry-After"), delay)
+			// Waiting past the caller's deadline can only end in a timeout, so report this response instead
+			if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) > wait {
+				resp.Body.Close()
+
+				setPhase(ctx, fmt.Sprintf("waiting to retry the %s API (%s) after status %d", c.name, path, resp.StatusCode))
+				select {
+				case <-ctx.Done():
+					return apiResponse{}, ctx.Err()
+				case <-time.After(wait):
+				}
+				delay *= 2
+				continue
+			}
+		}
+
+		// Read response body, refusing to buffer more than the limit
+		defer resp.Body.Close()
+		body, truncated, err := readBody(resp.Body, c.maxBodySize)
+		if err != nil {
+			return apiResponse{}, fmt.Errorf("error reading response body: %w", err)
+		}
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			// The start of an oversized error page is still enough for the snippet
+			return apiResponse{}, &StatusError{API: c.name, Status: resp.StatusCode, Snippet: bodySnippet(body)}
+		}
+		if truncated {
+			return apiResponse{}, fmt.Errorf("%w: %s API sent more than %d bytes", ErrResponseTooLarge, c.name, c.maxBodySize)
+		}
+		return apiResponse{
+			Status:      resp.StatusCode,
+			ContentType: resp.Header.Get("Content-Type"),
+			Body:        body,
+			MaxAge:      maxAge(resp.Header.Get("Cache-Control")),
+		}, nil
+	}
+}
+
+// maxAge returns the max-age directive of a Cache-Control header, or 0 when the header has none or it can't be parsed.
+func maxAge(header string) time.Duration {
+	for _, directive := range strings.Split(header, ",") {
+		v, ok := strings.CutPrefix(strings.ToLower(strings.TrimSpace(directive)), "max-age=")
+		if !ok {
+			continue
+		}
+		seconds, err := strconv.Atoi(strings.Trim(v, `"`))
+		if err != nil || seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	return 0
+}
+
+// readBody reads at most limit bytes from r and reports whether r had more to give.
+func readBody(r io.Reader, limit int64) (body []byte, truncated bool, err error) {
+	// Read one byte past the limit to tell a body that fits exactly from one that doesn't
+	body, err = io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, false, err
+	}
+	if int64(len(body)) > limit {
+		return body[:limit], true, nil
+	}
+	return body, false, nil
+}
+
+// looksLikeJSON reports whether a response body can plausibly be decoded as JSON: it must not be declared as HTML,
+// and must start, after any whitespace, with a character that can begin a JSON value.
+func looksLikeJSON(contentType string, body []byte) bool {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && (mediaType == "text/html" || mediaType == "application/xhtml+xml") {
+		return false
+	}
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return false
+	}
+	switch c := trimmed[0]; {
+	case c == '{', c == '[', c == '"', c == '-', c >= '0' && c <= '9', c == 't', c == 'f', c == 'n':
+		return true
+	default:
+		return false
+	}
+}
+
+// bodySnippet returns the start of body with whitespace collapsed and at most maxSnippetLength bytes, for error messages.
+func bodySnippet(body []byte) string {
+	snippet := strings.Join(strings.Fields(string(body)), " ")
+	if len(snippet) <= maxSnippetLength {
+		return snippet
+	}
+	cut := maxSnippetLength
+	for cut > 0 && !utf8.RuneStart(snippet[cut]) {
+		cut--
+	}
+	return snippet[:cut] + "..."
+}
+
+// isRetryableStatus reports whether a response with the given status code is worth retrying.
+func isRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// retryAfter returns the delay requested by a Retry-After header, given either in seconds or as an HTTP date.
+// The fallback is returned when the header is missing or cannot be parsed, and either is capped at maxRetryDelay so
+// an upstream can't stall a call indefinitely.
+func retryAfter(header string, fallback time.Duration) time.Duration {
+	if header == "" {
+		return min(fallback, maxRetryDelay)
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return min(time.Duration(seconds)*time.Second, maxRetryDelay)
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		return min(max(time.Until(t), 0), maxRetryDelay)
+	}
+	return min(fallback, maxRetryDelay)
+}
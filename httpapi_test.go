@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchRetriesUntilSuccess(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= 2 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"bitcoin":{"usd":50000}}`))
+	}))
+	defer server.Close()
+
+	client := NewCryptoClient(WithBaseURL(server.URL), WithRetryBaseDelay(time.Millisecond))
+	price, err := client.Price(context.Background(), "bitcoin", "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if price != 50000 {
+		t.Errorf("got price %v, want 50000", price)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Errorf("made %d requests, want 3", n)
+	}
+}
+
+func TestFetchStopsRetryingWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		cancel()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewCryptoClient(WithBaseURL(server.URL), WithRetryBaseDelay(time.Hour))
+	start := time.Now()
+	_, err := client.Price(ctx, "bitcoin", "USD")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("cancelled request took %v to return", elapsed)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("made %d requests after cancelling, want 1", n)
+	}
+}
+
+func TestFetchGivesUpWhenRetryAfterOutlastsDeadline(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client := NewCryptoClient(WithBaseURL(server.URL))
+	start := time.Now()
+	_, err := client.Price(ctx, "bitcoin", "USD")
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("got error %v, want ErrRateLimited", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("took %v to give up, want no wait", elapsed)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("made %d requests, want 1", n)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		header string
+		want   time.Duration
+	}{
+		{"", time.Second},
+		{"5", 5 * time.Second},
+		{"3600", maxRetryDelay},
+		{"soon", time.Second},
+		{"Mon, 02 Jan 2006 15:04:05 GMT", 0},
+	}
+	for _, tt := range tests {
+		if got := retryAfter(tt.header, time.Second); got != tt.want {
+			t.Errorf("retryAfter(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestUserAgentSentUpstream(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("User-Agent")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"bitcoin":{"usd":50000}}`))
+	}))
+	defer server.Close()
+
+	for _, agent := range []string{defaultUserAgent(), "custom-agent/1.0"} {
+		previous := userAgent
+		userAgent = agent
+		_, err := NewCryptoClient(WithBaseURL(server.URL)).Price(context.Background(), "bitcoin", "USD")
+		userAgent = previous
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != agent {
+			t.Errorf("stub saw User-Agent %q, want %q", got, agent)
+		}
+	}
+}
+
+func TestHTMLErrorPageReportsStatusAndSnippet(t *testing.T) {
+	const page = `<!DOCTYPE html>
+<html><head><title>503 Service Unavailable</title></head>
+<body><h1>Service Unavailable</h1></body></html>`
+	for _, status := range []int{http.StatusServiceUnavailable, http.StatusOK} {
+		transport := &countingTransport{respond: func(req *http.Request) (*http.Response, error) {
+			resp := cannedResponse(req, status, page)
+			resp.Header.Set("Content-Type", "text/html; charset=utf-8")
+			return resp, nil
+		}}
+		_, err := testCryptoClient(transport).Price(context.Background(), "bitcoin", "USD")
+		if err == nil {
+			t.Fatalf("status %d: HTML page was accepted as a price", status)
+		}
+		for _, want := range []string{fmt.Sprintf("status %d", status), "<html><head><title>503 Service Unavailable"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("status %d: error %q doesn't mention %q", status, err, want)
+			}
+		}
+		if strings.Contains(err.Error(), "invalid character") {
+			t.Errorf("status %d: error %q leaks the JSON decoder's message", status, err)
+		}
+	}
+}
+
+func TestMaxAge(t *testing.T) {
+	tests := map[string]time.Duration{
+		"max-age=30":                    30 * time.Second,
+		"public, Max-Age=120":           2 * time.Minute,
+		`max-age="15", must-revalidate`: 15 * time.Second,
+		"no-cache":                      0,
+		"max-age=-5":                    0,
+		"max-age=soon":                  0,
+		"":                              0,
+	}
+	for header, want := range tests {
+		if got := maxAge(header); got != want {
+			t.Errorf("maxAge(%q) = %v, want %v", header, got, want)
+		}
+	}
+}
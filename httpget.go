@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// maxHTTPGetBody is how many bytes of a response body the http_get tool returns; the rest is cut off.
+const maxHTTPGetBody = 16 << 10
+
+// httpGetHeaders are the response headers the http_get tool reports, in this order, when present.
+var httpGetHeaders = []string{"Content-Type", "Content-Length", "Last-Modified", "ETag", "Cache-Control", "Location"}
+
+// HTTPGetArguments defines the structure for arguments used to fetch a URL with the http_get tool.
+type HTTPGetArguments struct {
+	URL string `json:"url" jsonschema:"required,maxLength=2048,description=The http or https URL to fetch; its host must be on the server's allowlist"`
+}
+
+// HTTPGetResult is a response fetched by the http_get tool.
+type HTTPGetResult struct {
+	Status    string
+	Headers   http.Header
+	Body      string
+	Truncated bool
+}
+
+// httpGet fetches rawURL with client, which must only follow redirects to allowed hosts, and returns the status,
+// the headers listed in httpGetHeaders and at most limit bytes of the body. Unlike apiClient.get, non-2xx responses
+// are results rather than errors and nothing is retried.
+func httpGet(ctx context.Context, client *http.Client, headers http.Header, rawURL string, limit int) (HTTPGetResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return HTTPGetResult{}, fmt.Errorf("error creating request: %w", err)
+	}
+	for key, values := range headers {
+		req.Header[key] = values
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		err = fmt.Errorf("error requesting %s: %w", req.URL.Redacted(), err)
+		if ctx.Err() != nil || errors.Is(err, errRedirectRefused) {
+			// Neither says anything about whether the host is up
+			return HTTPGetResult{}, err
+		}
+		return HTTPGetResult{}, &unreachableError{err}
+	}
+	defer resp.Body.Close()
+
+	body, truncated, err := readBody(resp.Body, int64(limit))
+	if err != nil {
+		return HTTPGetResult{}, fmt.Errorf("error reading response body: %w", err)
+	}
+	result := HTTPGetResult{Status: resp.Status, Headers: http.Header{}, Truncated: truncated}
+	if truncated {
+		// Drop a multi-byte character the limit cut in half
+		for i := len(body) - 1; i >= 0 && i >= len(body)-utf8.UTFMax; i-- {
+			if utf8.RuneStart(body[i]) {
+				if !utf8.FullRune(body[i:]) {
+					body = body[:i]
+				}
+				break
+			}
+		}
+	}
+	result.Body = string(body)
+	for _, key := range httpGetHeaders {
+		if v := resp.Header.Get(key); v != "" {
+			result.Headers.Set(key, v)
+		}
+	}
+	return result, nil
+}
+
+// formatHTTPGetResult renders a result as the status line, the selected headers, a blank line and the body.
+func formatHTTPGetResult(rawURL string, result HTTPGetResult) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "GET %s: %s\n", rawURL, result.Status)
+	for _, key := range httpGetHeaders {
+		if v := result.Headers.Get(key); v != "" {
+			fmt.Fprintf(&sb, "%s: %s\n", key, v)
+		}
+	}
+	sb.WriteString("\n")
+	sb.WriteString(result.Body)
+	if result.Truncated {
+		fmt.Fprintf(&sb, "\n[body truncated to %d bytes]", maxHTTPGetBody)
+	}
+	return sb.String()
+}
+
+// httpGetTool returns the handler for the http_get tool, which may only fetch URLs on allowedHosts.
+// The options configure the HTTP client the same way as the upstream API clients, including offline mode.
+func httpGetTool(allowedHosts []string, opts ...ClientOption) func(context.Context, HTTPGetArguments) (*mcp_golang.ToolResponse, error) {
+	client := newAPIClient("http_get", "", opts...)
+	restrictRedirects(client.httpClient, allowedHosts)
+
+	return func(ctx context.Context, arguments HTTPGetArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", "http_get", "url", arguments.URL)
+		if err := validateRequired(arguments); err != nil {
+			return nil, err
+		}
+
+		rawURL := strings.TrimSpace(arguments.URL)
+		u, err := url.Parse(rawURL)
+		if err == nil {
+			err = checkAllowedURL(u, allowedHosts)
+		}
+		if err != nil {
+			return toolFailure("error fetching URL", err)
+		}
+		if client.offline {
+			return toolFailure("error fetching URL", errors.New("http_get is not available in offline mode"))
+		}
+
+		result, err := httpGet(ctx, client.httpClient, client.headers, u.String(), maxHTTPGetBody)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error fetching URL", "tool", "http_get", "host", u.Hostname(), "error", err)
+			return toolFailure("error fetching URL", err)
+		}
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(formatHTTPGetResult(u.Redacted(), result))), nil
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestHTTPGetTool(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("X-Internal", "not reported")
+		if r.URL.Path == "/large" {
+			w.Write([]byte(strings.Repeat("x", maxHTTPGetBody+100)))
+			return
+		}
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+	tool := httpGetTool([]string{"127.0.0.1"})
+	get := func(rawURL string) string {
+		t.Helper()
+		resp, err := tool(context.Background(), HTTPGetArguments{URL: rawURL})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return toolText(t, resp)
+	}
+
+	want := "GET " + server.URL + "/hello: 200 OK\nContent-Type: text/plain\nContent-Length: 5\n\nhello"
+	if text := get(server.URL + "/hello"); text != want {
+		t.Errorf("allowed host: got %q, want %q", text, want)
+	}
+
+	text := get(server.URL + "/large")
+	if !strings.HasSuffix(text, "\n"+strings.Repeat("x", maxHTTPGetBody)+"\n[body truncated to 16384 bytes]") {
+		t.Errorf("large body: got %d bytes ending %q, want the body cut at %d bytes", len(text), text[len(text)-40:], maxHTTPGetBody)
+	}
+
+	requests.Store(0)
+	for _, rawURL := range []string{"http://example.com/", strings.Replace(server.URL, "127.0.0.1", "localhost", 1), "file:///etc/passwd"} {
+		if _, err := tool(context.Background(), HTTPGetArguments{URL: rawURL}); err == nil || !strings.HasPrefix(err.Error(), "error fetching URL: ") {
+			t.Errorf("%s: got error %v, want it refused", rawURL, err)
+		}
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("made %d requests to hosts off the allowlist", n)
+	}
+}
+
+func TestHTTPGetToolValidatesArguments(t *testing.T) {
+	tool := httpGetTool([]string{"example.com"}, WithTransport(offlineTransport()))
+	for _, rawURL := range []string{"", "http://example.com/" + strings.Repeat("a", 2048)} {
+		if _, err := tool(context.Background(), HTTPGetArguments{URL: rawURL}); err == nil {
+			t.Errorf("URL of %d characters was accepted", len(rawURL))
+		}
+	}
+}
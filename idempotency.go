@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// defaultIdempotencyTTL is how long the result of a call made with an idempotency key is replayed for.
+const defaultIdempotencyTTL = 10 * time.Minute
+
+// Idempotent is embedded in the arguments of tools that opt into WithIdempotency, adding the idempotency_key argument.
+type Idempotent struct {
+	IdempotencyKey string `json:"idempotency_key" jsonschema:"maxLength=256,description=Optional key identifying this call; repeating a call with the same key returns the first result instead of running it again"`
+}
+
+// idempotencyKey implements idempotencyKeyer.
+func (i Idempotent) idempotencyKey() string {
+	return i.IdempotencyKey
+}
+
+// idempotencyKeyer is implemented by argument types that embed Idempotent.
+type idempotencyKeyer interface {
+	idempotencyKey() string
+}
+
+// idempotentCall is a call recorded under an idempotency key; done is closed once resp and err are set.
+type idempotentCall struct {
+	done    chan struct{}
+	resp    *mcp_golang.ToolResponse
+	err     error
+	expires time.Time
+}
+
+// idempotencyStore remembers the results of calls by key for a TTL, safe for concurrent use.
+type idempotencyStore struct {
+	ttl   time.Duration
+	mu    sync.Mutex
+	calls map[string]*idempotentCall
+}
+
+// newIdempotencyStore creates an idempotencyStore that replays results for ttl.
+func newIdempotencyStore(ttl time.Duration) *idempotencyStore {
+	return &idempotencyStore{ttl: ttl, calls: make(map[string]*idempotentCall)}
+}
+
+// Do runs fn unless a call with the same key has completed within the TTL or is still running, in which case that
+// call's result is returned instead and replayed reports true. Failed calls are forgotten so that they can be retried;
+// if fn panics, callers waiting on it get an error and the panic carries on up to WithRecover.
+func (s *idempotencyStore) Do(ctx context.Context, key string, fn func() (*mcp_golang.ToolResponse, error)) (resp *mcp_golang.ToolResponse, replayed bool, err error) {
+	now := time.Now()
+	s.mu.Lock()
+	for k, call := range s.calls {
+		if !call.expires.IsZero() && now.After(call.expires) {
+			delete(s.calls, k)
+		}
+	}
+	if call, ok := s.calls[key]; ok {
+		s.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.resp, true, call.err
+		case <-ctx.Done():
+			return nil, true, ctx.Err()
+		}
+	}
+	call := &idempotentCall{done: make(chan struct{})}
+	s.calls[key] = call
+	s.mu.Unlock()
+
+	// Release the waiters and forget the key even if fn panics, so neither they nor a retry hang on it
+	defer func() {
+		r := recover()
+		if r != nil {
+			call.err = fmt.Errorf("the call with this idempotency key failed unexpectedly: %v", r)
+		}
+		s.mu.Lock()
+		if call.err != nil {
+			delete(s.calls, key)
+		} else {
+			call.expires = time.Now().Add(s.ttl)
+		}
+		s.mu.Unlock()
+		close(call.done)
+		if r != nil {
+			panic(r)
+		}
+	}()
+	call.resp, call.err = fn()
+	return call.resp, false, call.err
+}
+
+// WithIdempotency makes the named tool replay the first result for calls that repeat an idempotency key within the
+// store's TTL, so a client retrying a call doesn't repeat its side effects. The tool's arguments must embed
+// Idempotent; calls without a key always run.
+func WithIdempotency(name string, store *idempotencyStore) Middleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, arguments any) (*mcp_golang.ToolResponse, error) {
+			keyer, ok := arguments.(idempotencyKeyer)
+			if !ok || keyer.idempotencyKey() == "" {
+				return next(ctx, arguments)
+			}
+
+			// Keys are scoped to the tool, so the same key sent to two tools runs both
+			resp, replayed, err := store.Do(ctx, name+"\x00"+keyer.idempotencyKey(), func() (*mcp_golang.ToolResponse, error) {
+				return next(ctx, arguments)
+			})
+			if replayed {
+				slog.InfoContext(ctx, "Replaying idempotent tool call", "tool", name, "idempotency_key", keyer.idempotencyKey())
+			}
+			return resp, err
+		}
+	}
+}
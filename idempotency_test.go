@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+func TestIdempotencyStoreRunsOnce(t *testing.T) {
+	store := newIdempotencyStore(defaultIdempotencyTTL)
+	var runs atomic.Int32
+	fn := func() (*mcp_golang.ToolResponse, error) {
+		runs.Add(1)
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent("done")), nil
+	}
+
+	for i, wantReplayed := range []bool{false, true, true} {
+		resp, replayed, err := store.Do(context.Background(), "key", fn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if replayed != wantReplayed || toolText(t, resp) != "done" {
+			t.Errorf("call %d: got %q, replayed %v, want done, replayed %v", i, toolText(t, resp), replayed, wantReplayed)
+		}
+	}
+	if n := runs.Load(); n != 1 {
+		t.Errorf("ran %d times, want once", n)
+	}
+	if _, replayed, _ := store.Do(context.Background(), "other key", fn); replayed || runs.Load() != 2 {
+		t.Error("a different key was replayed")
+	}
+}
+
+func TestIdempotencyStoreReleasesKeyAfterPanic(t *testing.T) {
+	store := newIdempotencyStore(defaultIdempotencyTTL)
+	started, release := make(chan struct{}), make(chan struct{})
+	panicked := make(chan any, 1)
+	go func() {
+		defer func() { panicked <- recover() }()
+		store.Do(context.Background(), "key", func() (*mcp_golang.ToolResponse, error) {
+			close(started)
+			<-release
+			panic("deliberate")
+		})
+	}()
+	<-started
+
+	// A call waiting on the panicking one gets an error instead of hanging
+	waited := make(chan error, 1)
+	go func() {
+		_, _, err := store.Do(context.Background(), "key", func() (*mcp_golang.ToolResponse, error) {
+			return nil, errors.New("ran instead of waiting")
+		})
+		waited <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	if r := <-panicked; r != "deliberate" {
+		t.Errorf("the panic was not passed on, recovered %v", r)
+	}
+	select {
+	case err := <-waited:
+		if err == nil {
+			t.Error("the waiting call got no error")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the waiting call was never released")
+	}
+
+	// A retry with the same key runs again, promptly
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, replayed, err := store.Do(ctx, "key", func() (*mcp_golang.ToolResponse, error) {
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent("retried")), nil
+	})
+	if err != nil || replayed || toolText(t, resp) != "retried" {
+		t.Errorf("retry got %v, replayed %v, want it run afresh", err, replayed)
+	}
+}
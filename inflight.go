@@ -0,0 +1,52 @@
+package main
+
+import "sync"
+
+// inFlightCalls counts the tool calls that are currently executing. Once Shutdown is called no new call is
+// admitted, so the count can only fall from then on and a call starting during shutdown can't slip past the wait.
+type inFlightCalls struct {
+	mu           sync.Mutex
+	running      int
+	shuttingDown bool
+	// idle is closed once shutting down with no calls left running.
+	idle chan struct{}
+}
+
+// Start admits a tool call, reporting false once shutdown has begun. Every admitted call must call Finish.
+func (c *inFlightCalls) Start() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.shuttingDown {
+		return false
+	}
+	c.running++
+	return true
+}
+
+// Finish marks an admitted tool call as done.
+func (c *inFlightCalls) Finish() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.running--
+	if c.shuttingDown && c.running == 0 {
+		close(c.idle)
+	}
+}
+
+// Shutdown stops admitting tool calls and returns a channel that is closed once the running ones have finished.
+// Calling it again returns the same channel.
+func (c *inFlightCalls) Shutdown() <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.shuttingDown {
+		c.shuttingDown = true
+		c.idle = make(chan struct{})
+		if c.running == 0 {
+			close(c.idle)
+		}
+	}
+	return c.idle
+}
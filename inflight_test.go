@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+func TestInFlightShutdownWaitsForRunningCalls(t *testing.T) {
+	calls := &inFlightCalls{}
+	if !calls.Start() {
+		t.Fatal("a call was turned away before shutdown")
+	}
+	idle := calls.Shutdown()
+	if calls.Start() {
+		t.Error("a call was admitted after shutdown began")
+	}
+	select {
+	case <-idle:
+		t.Fatal("shutdown finished with a call still running")
+	case <-time.After(10 * time.Millisecond):
+	}
+	calls.Finish()
+	select {
+	case <-idle:
+	case <-time.After(time.Second):
+		t.Fatal("shutdown didn't finish once the running call did")
+	}
+	if again := calls.Shutdown(); again != idle {
+		t.Error("a second shutdown returned a different channel")
+	}
+}
+
+func TestInFlightShutdownRacesWithNewCalls(t *testing.T) {
+	// Run with -race: calls starting while shutdown begins are either admitted and waited for, or turned away
+	calls := &inFlightCalls{}
+	var finished sync.WaitGroup
+	var admitted, done sync.Map
+	for i := range 100 {
+		finished.Add(1)
+		go func() {
+			defer finished.Done()
+			if calls.Start() {
+				admitted.Store(i, true)
+				time.Sleep(time.Millisecond)
+				done.Store(i, true)
+				calls.Finish()
+			}
+		}()
+	}
+	<-calls.Shutdown()
+	admitted.Range(func(i, _ any) bool {
+		if _, ok := done.Load(i); !ok {
+			t.Errorf("call %v was admitted but shutdown didn't wait for it", i)
+		}
+		return true
+	})
+	finished.Wait()
+}
+
+func TestTrackInFlightFailsCallsDuringShutdown(t *testing.T) {
+	previous := inFlight
+	inFlight = &inFlightCalls{}
+	t.Cleanup(func() { inFlight = previous })
+
+	tool := trackInFlight(func(context.Context, any) (*mcp_golang.ToolResponse, error) {
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent("ok")), nil
+	})
+	if _, err := tool(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if !waitForInFlight(time.Second) {
+		t.Fatal("timed out waiting with no calls running")
+	}
+	if _, err := tool(context.Background(), nil); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("got error %v, want calls after shutdown turned away", err)
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// defaultLocale is the locale prices are formatted for when a tool call doesn't name one.
+const defaultLocale = "en-US"
+
+// localePrinter returns a printer that formats numbers with the grouping and decimal separators of the given
+// BCP 47 locale tag, such as en-US or de-DE. An empty tag selects defaultLocale.
+func localePrinter(locale string) (*message.Printer, error) {
+	locale = strings.TrimSpace(locale)
+	if locale == "" {
+		locale = defaultLocale
+	}
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported locale %q, expected a tag such as en-US or de-DE", locale)
+	}
+	return message.NewPrinter(tag), nil
+}
+
+// formatPrice formats a price with the number of decimals conventional for currency, using the printer's locale.
+func formatPrice(p *message.Printer, price float64, currency string) string {
+	return p.Sprintf("%.*f", decimalsFor(currency), price)
+}
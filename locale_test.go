@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestFormatPriceForLocale(t *testing.T) {
+	tests := []struct {
+		locale string
+		want   string
+	}{
+		{"de-DE", "50.123,45"},
+		{"en-US", "50,123.45"},
+		{"", "50,123.45"},
+	}
+	for _, tt := range tests {
+		p, err := localePrinter(tt.locale)
+		if err != nil {
+			t.Fatalf("locale %q: %v", tt.locale, err)
+		}
+		if got := formatPrice(p, 50123.45, "EUR"); got != tt.want {
+			t.Errorf("locale %q: got %q, want %q", tt.locale, got, tt.want)
+		}
+	}
+
+	if _, err := localePrinter("not a locale!"); err == nil {
+		t.Error("invalid locale tag was accepted")
+	}
+}
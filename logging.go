@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// defaultLogBufferLines is how many recent log lines are kept in memory for the logs://recent resource.
+const defaultLogBufferLines = 200
+
+// recentLogsResourceURI is the URI of the resource serving the lines held by the log buffer.
+const recentLogsResourceURI = "logs://recent"
+
+// parseLogLevel converts a level name (debug, info, warn, error) into a slog.Level.
+func parseLogLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(s))); err != nil {
+		return 0, fmt.Errorf("invalid log level %q (expected debug, info, warn or error)", s)
+	}
+	return level, nil
+}
+
+// newLogger creates a JSON logger writing to stderr, leaving stdout free for the stdio transport.
+// When buffer is not nil every line is also kept in it.
+// Records logged with a context that carries a request ID are tagged with it.
+func newLogger(level slog.Level, buffer *logBuffer) *slog.Logger {
+	var w io.Writer = os.Stderr
+	if buffer != nil {
+		w = io.MultiWriter(os.Stderr, buffer)
+	}
+	return slog.New(requestIDHandler{slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})})
+}
+
+// logBuffer is an io.Writer that keeps the last lines written to it in a ring buffer, safe for concurrent use.
+type logBuffer struct {
+	mu    sync.Mutex
+	lines []string
+	// next is the index the next line is stored at; once the buffer is full it is also the oldest line.
+	next int
+	full bool
+}
+
+// newLogBuffer creates a logBuffer holding at most size lines.
+func newLogBuffer(size int) *logBuffer {
+	return &logBuffer{lines: make([]string, size)}
+}
+
+// Write implements io.Writer, storing each non-empty line of p and evicting the oldest lines once the buffer is full.
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, line := range strings.Split(string(p), "\n") {
+		if line == "" || len(b.lines) == 0 {
+			continue
+		}
+		b.lines[b.next] = line
+		b.next = (b.next + 1) % len(b.lines)
+		if b.next == 0 {
+			b.full = true
+		}
+	}
+	return len(p), nil
+}
+
+// Lines returns the buffered lines, oldest first.
+func (b *logBuffer) Lines() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.full {
+		return append([]string(nil), b.lines[:b.next]...)
+	}
+	return append(append([]string(nil), b.lines[b.next:]...), b.lines[:b.next]...)
+}
+
+// Read returns the content of logs://recent: the buffered lines, oldest first, one per line.
+func (b *logBuffer) Read() (string, error) {
+	return strings.Join(b.Lines(), "\n"), nil
+}
+
+// requestIDHandler adds a request_id attribute to records whose context carries a request ID.
+type requestIDHandler struct {
+	slog.Handler
+}
+
+// Handle implements slog.Handler.
+func (h requestIDHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := requestIDFromContext(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler.
+func (h requestIDHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return requestIDHandler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup implements slog.Handler.
+func (h requestIDHandler) WithGroup(name string) slog.Handler {
+	return requestIDHandler{h.Handler.WithGroup(name)}
+}
+
+// fatal logs msg at error level with the given attributes and exits the process.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
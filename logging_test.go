@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestLogBufferKeepsNewestLinesInOrder(t *testing.T) {
+	buffer := newLogBuffer(3)
+	fmt.Fprint(buffer, "one\ntwo\n")
+	if got := buffer.Lines(); !slices.Equal(got, []string{"one", "two"}) {
+		t.Errorf("got %q before the buffer filled up", got)
+	}
+
+	for _, line := range []string{"three", "four", "five"} {
+		fmt.Fprintln(buffer, line)
+	}
+	if got := buffer.Lines(); !slices.Equal(got, []string{"three", "four", "five"}) {
+		t.Errorf("got %q, want the last three lines, oldest first", got)
+	}
+	if text, _ := buffer.Read(); text != "three\nfour\nfive" {
+		t.Errorf("logs://recent reads %q", text)
+	}
+}
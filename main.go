@@ -1,177 +1,302 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
-	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	mcp_golang "github.com/metoro-io/mcp-golang"
-	"github.com/metoro-io/mcp-golang/transport/stdio"
+	mcphttp "github.com/metoro-io/mcp-golang/transport/http"
 )
 
 // Content represents the main structure for submitting title and optional description as part of a request body.
 type Content struct {
-	Title       string  `json:"title" jsonschema:"required,description=The title to submit"`
-	Description *string `json:"description" jsonschema:"description=The description to submit"`
+	Title       string  `json:"title" jsonschema:"required,maxLength=1024,description=The title to submit"`
+	Description *string `json:"description" jsonschema:"maxLength=1024,description=The description to submit"`
 }
 
 // MyFunctionsArguments represents the arguments required for specific function execution, including a submitter and content.
 type MyFunctionsArguments struct {
-	Submitter string  `json:"submitter" jsonschema:"required,description=The name of the thing calling this tool (openai, google, claude, etc)"`
+	Submitter string  `json:"submitter" jsonschema:"required,maxLength=256,description=The name of the thing calling this tool (openai, google, claude, etc)"`
 	Content   Content `json:"content" jsonschema:"required,description=The content of the message"`
+	Idempotent
 }
 
-// BitcoinPriceArguments defines the structure for arguments used to request Bitcoin price in a specific currency.
-type BitcoinPriceArguments struct {
-	Currency string `json:"currency" jsonschema:"required,description=The currency to get the Bitcoin price in (USD, EUR, GBP, etc)"`
-}
+// shutdownTimeout bounds how long the server waits for in-flight tool calls once a shutdown signal arrives.
+const shutdownTimeout = 10 * time.Second
 
-// CoinGeckoResponse represents the response structure for Bitcoin price data from the CoinGecko API across multiple currencies.
-type CoinGeckoResponse struct {
-	Bitcoin struct {
-		USD float64 `json:"usd"`
-		EUR float64 `json:"eur"`
-		GBP float64 `json:"gbp"`
-		JPY float64 `json:"jpy"`
-		AUD float64 `json:"aud"`
-		CAD float64 `json:"cad"`
-		CHF float64 `json:"chf"`
-		CNY float64 `json:"cny"`
-		KRW float64 `json:"krw"`
-		RUB float64 `json:"rub"`
-	} `json:"bitcoin"`
-}
+// inFlight tracks tool calls that are currently executing so shutdown can let them finish.
+var inFlight = &inFlightCalls{}
 
 // main initializes and starts the MCP server, registers tools, prompts, and resources, and handles incoming requests.
 func main() {
-	log.Println("Starting MCP Server...")
+	startTime = time.Now()
 
-	server := mcp_golang.NewServer(stdio.NewStdioServerTransport())
+	cfg, err := loadConfig(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
-	// Register "hello" tool
-	err := server.RegisterTool("hello", "Say hello to a person with a personalized greeting message", func(arguments MyFunctionsArguments) (*mcp_golang.ToolResponse, error) {
-		log.Println("Received request for hello tool")
-		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Hello, %s! Welcome to the MCP Example.", arguments.Submitter))), nil
-	})
+	level, err := parseLogLevel(cfg.LogLevel)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	// Keep recent log lines in memory so clients can read them through logs://recent
+	var logs *logBuffer
+	if cfg.LogBufferLines > 0 {
+		logs = newLogBuffer(cfg.LogBufferLines)
+	}
+	slog.SetDefault(newLogger(level, logs))
+
+	// Keep a summary of recent tool calls for the call_history tool
+	if cfg.CallHistorySize > 0 {
+		recentCalls = newCallHistory(cfg.CallHistorySize)
+	}
+
+	// Bound every tool call, however many upstream requests it makes
+	toolTimeout = time.Duration(cfg.ToolTimeout)
+
+	// Network transports serve many clients at once, so cap how many tool calls run concurrently
+	if cfg.Transport == transportSSE || cfg.Transport == transportWS {
+		toolSlots = newToolSlots(cfg.MaxConcurrency)
+	}
+
+	// Fail fast on a default currency the price tools would reject on every call
+	defaultCurrency, err = NormalizeCurrency(cfg.DefaultCurrency)
 	if err != nil {
-		log.Fatalf("Error registering hello tool: %v", err)
+		fmt.Fprintf(os.Stderr, "invalid default currency: %v\n", err)
+		os.Exit(2)
+	}
+
+	// Every btc_fee_estimate call without its own size would be rejected, so refuse an unusable default size up front
+	if cfg.FeeVBytes < 1 || cfg.FeeVBytes > maxFeeVBytes {
+		fmt.Fprintf(os.Stderr, "invalid fee vbytes %d: must be between 1 and %d\n", cfg.FeeVBytes, maxFeeVBytes)
+		os.Exit(2)
 	}
 
-	// Register "bitcoin_price" tool
-	err = server.RegisterTool("bitcoin_price", "Get the latest Bitcoin price in various currencies", func(arguments BitcoinPriceArguments) (*mcp_golang.ToolResponse, error) {
-		log.Printf("Received request for bitcoin_price tool with currency: %s", arguments.Currency)
+	slog.Info("Starting MCP Server...")
 
-		// Default to USD if no currency is specified
-		currency := arguments.Currency
-		if currency == "" {
-			currency = "USD"
+	// Stop on SIGINT/SIGTERM so process managers get a clean exit
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serverTransport, err := buildTransport(cfg)
+	if err != nil {
+		fatal("Error creating transport", "error", err)
+	}
+	switch cfg.Transport {
+	case transportSSE:
+		slog.Info("Using transport", "transport", cfg.Transport, "addr", cfg.Addr, "endpoint", httpEndpoint)
+	case transportWS:
+		slog.Info("Using transport", "transport", cfg.Transport, "addr", cfg.Addr, "endpoint", cfg.WSPath)
+	default:
+		slog.Info("Using transport", "transport", transportStdio)
+	}
+
+	// Some clients nest the tool arguments in an "arguments" envelope; unwrap them before the library binds them
+	server := mcp_golang.NewServer(unwrapEnvelopes(serverTransport))
+
+	// Identify ourselves to upstream APIs; every client created below picks this up
+	userAgent = cfg.UserAgent
+
+	// Options shared by every upstream client; offline mode stops them all from touching the network
+	clientOpts := []ClientOption{WithTimeout(time.Duration(cfg.RequestTimeout)), WithMaxBodySize(cfg.MaxBodySize)}
+	if cfg.Offline {
+		clientOpts = append(clientOpts, WithOffline())
+		slog.Warn("Offline mode: price tools return simulated fixture data")
+	}
+
+	// Create a single CoinGecko client shared by all price tools
+	cryptoClient := NewCryptoClient(append([]ClientOption{
+		WithBaseURL(cfg.CoinGeckoBaseURL),
+		WithAPIKey(cfg.CoinGeckoAPIKey),
+		WithCircuitBreaker(newCircuitBreaker("CoinGecko", cfg.BreakerThreshold, time.Duration(cfg.BreakerCooldown))),
+	}, clientOpts...)...)
+	if cfg.CoinGeckoAPIKey != "" {
+		// Never log the key itself
+		slog.Info("Using CoinGecko Pro API key", "base_url", cryptoClient.baseURL)
+	}
+	// Fail over to CoinCap for single prices while CoinGecko is down; offline there is no outage to fail over from
+	if cfg.CoinCapBaseURL != "" && !cfg.Offline {
+		cryptoClient.SetFallback(NewCoinCapClient(append([]ClientOption{WithBaseURL(cfg.CoinCapBaseURL)}, clientOpts...)...))
+	}
+
+	// Cache prices so repeated calls don't run into CoinGecko's rate limits, and to fall back on while it is down
+	cache := newPriceCache(time.Duration(cfg.CacheTTL), time.Duration(cfg.MaxStale))
+	if cfg.CacheFile != "" {
+		// A bad cache file only costs a cold start, so don't refuse to run over it
+		if n, err := cache.LoadFile(cfg.CacheFile); err != nil {
+			slog.Warn("Error restoring price cache", "file", cfg.CacheFile, "error", err)
+		} else {
+			slog.Info("Restored price cache", "file", cfg.CacheFile, "entries", n)
 		}
+	}
 
-		// Call CoinGecko API to get the latest Bitcoin price
-		price, err := getBitcoinPrice(currency)
+	// Refuse to start with a price template that can't render, rather than failing every bitcoin_price call
+	priceTemplate, err := parsePriceTemplate(cfg.PriceTemplate)
+	if err != nil {
+		fatal("Error parsing price template", "error", err)
+	}
+
+	// Load user-defined HTTP tools, refusing to start on a bad manifest rather than silently dropping it
+	var manifestTools []RegisteredTool
+	if cfg.ToolsDir != "" {
+		// Each manifest tool gets its own circuit breaker, so one failing API doesn't pause the others
+		manifestOpts := append(slices.Clip(clientOpts), WithOwnCircuitBreaker(cfg.BreakerThreshold, time.Duration(cfg.BreakerCooldown)))
+		manifestTools, err = LoadToolManifests(cfg.ToolsDir, cfg.ManifestHosts, manifestOpts...)
 		if err != nil {
-			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Error fetching Bitcoin price: %v", err))), nil
+			fatal("Error loading tool manifests", "dir", cfg.ToolsDir, "error", err)
 		}
+		slog.Info("Loaded tool manifests", "dir", cfg.ToolsDir, "count", len(manifestTools))
+	}
 
-		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("The current Bitcoin price is %.2f %s (as of %s)",
-			price,
-			currency,
-			time.Now().Format(time.RFC1123)))), nil
+	// Register all tools, prompts and resources, reporting every failure at once
+	err = registerAll(server, &services{
+		config:        cfg,
+		crypto:        cryptoClient,
+		weather:       NewWeatherClient(clientOpts...),
+		mempool:       NewMempoolClient(append([]ClientOption{WithBaseURL(cfg.MempoolBaseURL)}, clientOpts...)...),
+		clientOpts:    clientOpts,
+		cache:         cache,
+		coins:         newCoinIndex(cryptoClient, coinListTTL),
+		limiters:      newToolLimiters(cfg.RateLimit, cfg.RateBurst),
+		debug:         cfg.Debug,
+		manifestTools: manifestTools,
+		priceTemplate: priceTemplate,
+		logs:          logs,
 	})
 	if err != nil {
-		log.Fatalf("Error registering bitcoin_price tool: %v", err)
+		fatal("Error registering server capabilities", "error", err)
 	}
+	logCapabilities(cfg.Transport)
 
-	// Register "prompt_test" prompt
-	err = server.RegisterPrompt("prompt_test", "This is a test prompt", func(arguments Content) (*mcp_golang.PromptResponse, error) {
-		log.Println("Received request for prompt_test")
-		return mcp_golang.NewPromptResponse("description", mcp_golang.NewPromptMessage(mcp_golang.NewTextContent(fmt.Sprintf("Hello, %s!", arguments.Title)), mcp_golang.RoleUser)), nil
-	})
-	if err != nil {
-		log.Fatalf("Error registering prompt_test: %v", err)
+	// Prefetch common prices in the background so serving isn't held up; there is nothing to fetch offline
+	if cfg.Warmup {
+		if cfg.Offline {
+			slog.Info("Skipping price cache warmup in offline mode")
+		} else {
+			go warmupCache(ctx, cryptoClient, cache, warmupCurrencies)
+		}
 	}
 
-	// Register test resource
-	err = server.RegisterResource("test://resource", "resource_test", "This is a test resource", "application/json",
-		func() (*mcp_golang.ResourceResponse, error) {
-			log.Println("Received request for resource: test://resource")
-			return mcp_golang.NewResourceResponse(mcp_golang.NewTextEmbeddedResource(
-				"test://resource", "This is a test resource", "application/json",
-			)), nil
-		})
-	if err != nil {
-		log.Fatalf("Error registering resource: %v", err)
-	} else {
-		log.Println("Successfully registered resource: test://resource") // Debug log
+	// Expose Prometheus metrics when requested
+	if cfg.MetricsAddr != "" {
+		metricsServer := startMetricsServer(cfg.MetricsAddr)
+		defer metricsServer.Close()
 	}
 
-	// Start the server
-	log.Println("MCP Server is now running and waiting for requests...")
-	err = server.Serve()
-	if err != nil {
-		log.Fatalf("Server error: %v", err)
+	// Network transports are served by our own HTTP server, which also exposes /healthz
+	switch t := serverTransport.(type) {
+	case *mcphttp.GinTransport:
+		httpServer := startHTTPServer(cfg.Addr, newHTTPRouter(t))
+		defer httpServer.Close()
+	case *wsTransport:
+		httpServer := startHTTPServer(cfg.Addr, newWSRouter(t, cfg.WSPath))
+		defer httpServer.Close()
 	}
 
-	select {} // Keeps the server running
-}
+	// Start the server; network transports block in Serve, so run it in the background
+	slog.Info("MCP Server is now running and waiting for requests...")
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve()
+	}()
 
-// getBitcoinPrice retrieves the current Bitcoin price in the specified currency using the CoinGecko API.
-// The function returns the price as a float64 and an error if the currency is unsupported or the API call fails.
-func getBitcoinPrice(currency string) (float64, error) {
-	// Create HTTP client with timeout
-	client := &http.Client{
-		Timeout: 10 * time.Second,
+	// The stdio transport stops once stdin is closed for good; the network transports only stop on a signal
+	var transportDone <-chan struct{}
+	if t, ok := serverTransport.(*stdioTransport); ok {
+		transportDone = t.Done()
 	}
 
-	// Make request to CoinGecko API
-	resp, err := client.Get("https://api.coingecko.com/api/v3/simple/price?ids=bitcoin&vs_currencies=usd,eur,gbp,jpy,aud,cad,chf,cny,krw,rub")
-	if err != nil {
-		return 0, fmt.Errorf("error making request to CoinGecko API: %w", err)
+	// Keep serving until a shutdown signal arrives, the transport stops or it fails
+	select {
+	case <-ctx.Done():
+	case <-transportDone:
+	case err := <-serveErr:
+		if err != nil {
+			fatal("Server error", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+		case <-transportDone:
+		}
 	}
-	defer resp.Body.Close()
+	slog.Info("shutting down")
 
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return 0, fmt.Errorf("error reading response body: %w", err)
+	if !waitForInFlight(shutdownTimeout) {
+		slog.Warn("Timed out waiting for in-flight tool calls", "timeout", shutdownTimeout)
 	}
 
-	// Parse JSON response
-	var coinGeckoResp CoinGeckoResponse
-	err = json.Unmarshal(body, &coinGeckoResp)
-	if err != nil {
-		return 0, fmt.Errorf("error parsing JSON response: %w", err)
-	}
-
-	// Get price for requested currency
-	var price float64
-	switch currency {
-	case "USD", "usd":
-		price = coinGeckoResp.Bitcoin.USD
-	case "EUR", "eur":
-		price = coinGeckoResp.Bitcoin.EUR
-	case "GBP", "gbp":
-		price = coinGeckoResp.Bitcoin.GBP
-	case "JPY", "jpy":
-		price = coinGeckoResp.Bitcoin.JPY
-	case "AUD", "aud":
-		price = coinGeckoResp.Bitcoin.AUD
-	case "CAD", "cad":
-		price = coinGeckoResp.Bitcoin.CAD
-	case "CHF", "chf":
-		price = coinGeckoResp.Bitcoin.CHF
-	case "CNY", "cny":
-		price = coinGeckoResp.Bitcoin.CNY
-	case "KRW", "krw":
-		price = coinGeckoResp.Bitcoin.KRW
-	case "RUB", "rub":
-		price = coinGeckoResp.Bitcoin.RUB
-	default:
-		return 0, fmt.Errorf("unsupported currency: %s", currency)
+	if cfg.CacheFile != "" {
+		if err := cache.SaveFile(cfg.CacheFile); err != nil {
+			slog.Error("Error saving price cache", "file", cfg.CacheFile, "error", err)
+		} else {
+			slog.Info("Saved price cache", "file", cfg.CacheFile)
+		}
+	}
+}
+
+// waitForInFlight turns away new tool calls and blocks until the tracked ones finish or the timeout elapses,
+// reporting whether they finished.
+func waitForInFlight(timeout time.Duration) bool {
+	select {
+	case <-inFlight.Shutdown():
+		return true
+	case <-time.After(timeout):
+		return false
 	}
+}
 
-	return price, nil
+// startMetricsServer serves the metrics registry at /metrics on addr in the background.
+func startMetricsServer(addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics)
+
+	srv := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Metrics server failed", "addr", addr, "error", err)
+		}
+	}()
+	slog.Info("Serving metrics", "addr", addr, "path", "/metrics")
+	return srv
+}
+
+// newHTTPRouter routes MCP messages on /mcp to the transport and serves the /healthz readiness endpoint.
+func newHTTPRouter(t *mcphttp.GinTransport) http.Handler {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.POST(httpEndpoint, t.Handler())
+	router.GET("/healthz", gin.WrapF(healthzHandler))
+	return router
+}
+
+// newWSRouter accepts WebSocket connections on path and serves the /healthz readiness endpoint.
+func newWSRouter(t *wsTransport, path string) http.Handler {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.GET(path, gin.WrapH(t.Handler()))
+	router.GET("/healthz", gin.WrapF(healthzHandler))
+	return router
+}
+
+// startHTTPServer serves handler on addr in the background.
+func startHTTPServer(addr string, handler http.Handler) *http.Server {
+	srv := &http.Server{Addr: addr, Handler: handler}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fatal("HTTP server failed", "addr", addr, "error", err)
+		}
+	}()
+	return srv
 }
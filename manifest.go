@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// ToolManifest declares a read-only tool that proxies a GET request to an HTTP API, so tools can be added without writing Go.
+// URLTemplate placeholders such as {city} are replaced with the values of the arguments of the same name.
+type ToolManifest struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	URLTemplate string             `json:"url_template"`
+	Arguments   []ManifestArgument `json:"arguments"`
+}
+
+// ManifestArgument describes one argument of a manifest tool.
+type ManifestArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Required    bool   `json:"required"`
+}
+
+// ManifestToolArguments holds the arguments of a manifest tool call. Manifest tools are declared at runtime,
+// so their arguments are a string map rather than a struct; the argument list is spelled out in the description instead.
+type ManifestToolArguments map[string]string
+
+// RegisteredTool is a manifest tool ready to be passed to registerTool.
+type RegisteredTool struct {
+	Name        string
+	Description string
+	Handler     func(context.Context, ManifestToolArguments) (*mcp_golang.ToolResponse, error)
+}
+
+// placeholderPattern matches {name} placeholders in a URL template.
+var placeholderPattern = regexp.MustCompile(`\{([A-Za-z0-9_]+)\}`)
+
+// LoadToolManifests reads every .json manifest in dir and builds a tool for each. To guard against SSRF, a manifest
+// may only target http or https URLs on one of allowedHosts, and placeholders are not allowed in the scheme or host.
+// Each tool's HTTP client is created with opts, like the upstream API clients.
+func LoadToolManifests(dir string, allowedHosts []string, opts ...ClientOption) ([]RegisteredTool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading tool manifest directory: %w", err)
+	}
+
+	var tools []RegisteredTool
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		tool, err := loadToolManifest(path, allowedHosts, opts...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tool manifest %s: %w", path, err))
+			continue
+		}
+		tools = append(tools, tool)
+	}
+	return tools, errors.Join(errs...)
+}
+
+// loadToolManifest parses and validates a single manifest file.
+func loadToolManifest(path string, allowedHosts []string, opts ...ClientOption) (RegisteredTool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return RegisteredTool{}, err
+	}
+	var m ToolManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return RegisteredTool{}, fmt.Errorf("error parsing manifest: %w", err)
+	}
+	if err := m.validate(allowedHosts); err != nil {
+		return RegisteredTool{}, err
+	}
+	return RegisteredTool{
+		Name:        m.Name,
+		Description: m.describe(),
+		Handler:     manifestTool(m, allowedHosts, opts...),
+	}, nil
+}
+
+// validate checks that the manifest is complete, that every placeholder is a declared argument and that the URL
+// template can only ever reach an allowed host.
+func (m ToolManifest) validate(allowedHosts []string) error {
+	if m.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if m.URLTemplate == "" {
+		return fmt.Errorf("url_template is required")
+	}
+
+	declared := make(map[string]bool, len(m.Arguments))
+	for _, arg := range m.Arguments {
+		if arg.Name == "" {
+			return fmt.Errorf("every argument needs a name")
+		}
+		declared[arg.Name] = true
+	}
+	for _, match := range placeholderPattern.FindAllStringSubmatch(m.URLTemplate, -1) {
+		if !declared[match[1]] {
+			return fmt.Errorf("url_template uses undeclared argument %q", match[1])
+		}
+	}
+
+	// The scheme and host must be fixed, so arguments can't redirect the request elsewhere
+	u, err := url.Parse(m.URLTemplate)
+	if err != nil {
+		return fmt.Errorf("invalid url_template: %w", err)
+	}
+	if strings.ContainsAny(u.Scheme+u.Host, "{}") {
+		return fmt.Errorf("url_template must not use placeholders in the scheme or host")
+	}
+	return checkAllowedURL(u, allowedHosts)
+}
+
+// describe returns the tool description followed by its arguments, since they don't appear in the input schema.
+func (m ToolManifest) describe() string {
+	if len(m.Arguments) == 0 {
+		return m.Description
+	}
+	var sb strings.Builder
+	sb.WriteString(m.Description)
+	sb.WriteString(" Arguments:")
+	for _, arg := range m.Arguments {
+		fmt.Fprintf(&sb, " %s", arg.Name)
+		if arg.Required {
+			sb.WriteString(" (required)")
+		}
+		if arg.Description != "" {
+			fmt.Fprintf(&sb, " - %s", arg.Description)
+		}
+		sb.WriteString(";")
+	}
+	return strings.TrimSuffix(sb.String(), ";")
+}
+
+// checkAllowedURL rejects URLs that aren't http or https or that point outside allowedHosts.
+func checkAllowedURL(u *url.URL, allowedHosts []string) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("scheme %q is not allowed, expected http or https", u.Scheme)
+	}
+	if !slices.Contains(allowedHosts, strings.ToLower(u.Hostname())) {
+		return fmt.Errorf("host %q is not in the host allowlist", u.Hostname())
+	}
+	return nil
+}
+
+// errRedirectRefused is returned, wrapped, by clients set up with restrictRedirects when they refuse to follow a redirect.
+var errRedirectRefused = errors.New("redirect refused")
+
+// restrictRedirects makes the client refuse redirects that leave allowedHosts, so a redirect can't take a request
+// off the allowlist either.
+func restrictRedirects(client *http.Client, allowedHosts []string) {
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return fmt.Errorf("%w: stopped after 10 redirects", errRedirectRefused)
+		}
+		if err := checkAllowedURL(req.URL, allowedHosts); err != nil {
+			return fmt.Errorf("%w: %w", errRedirectRefused, err)
+		}
+		return nil
+	}
+}
+
+// expandURLTemplate substitutes arguments into the template, escaping each value for the part of the URL it lands in.
+func expandURLTemplate(template string, arguments ManifestToolArguments) string {
+	path, query, hasQuery := strings.Cut(template, "?")
+	path = placeholderPattern.ReplaceAllStringFunc(path, func(p string) string {
+		return url.PathEscape(arguments[strings.Trim(p, "{}")])
+	})
+	if !hasQuery {
+		return path
+	}
+	query = placeholderPattern.ReplaceAllStringFunc(query, func(p string) string {
+		return url.QueryEscape(arguments[strings.Trim(p, "{}")])
+	})
+	return path + "?" + query
+}
+
+// manifestTool returns the handler for a manifest tool, which fetches the expanded URL with a client created with
+// opts and returns the response body.
+func manifestTool(m ToolManifest, allowedHosts []string, opts ...ClientOption) func(context.Context, ManifestToolArguments) (*mcp_golang.ToolResponse, error) {
+	client := newAPIClient(m.Name, "", opts...)
+	restrictRedirects(client.httpClient, allowedHosts)
+
+	return func(ctx context.Context, arguments ManifestToolArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", m.Name, "arguments", arguments)
+
+		for _, arg := range m.Arguments {
+			if arg.Required && strings.TrimSpace(arguments[arg.Name]) == "" {
+				return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Error calling %s: missing required argument: %s", m.Name, arg.Name))), nil
+			}
+		}
+
+		endpoint := expandURLTemplate(m.URLTemplate, arguments)
+		u, err := url.Parse(endpoint)
+		if err == nil {
+			err = checkAllowedURL(u, allowedHosts)
+		}
+		if err != nil {
+			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Error calling %s: %v", m.Name, err))), nil
+		}
+
+		resp, err := client.get(ctx, endpoint, nil)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error calling manifest tool", "tool", m.Name, "error", err)
+			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Error calling %s: %s", m.Name, describeError(err)))), nil
+		}
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(string(resp.Body))), nil
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestExpandURLTemplate(t *testing.T) {
+	got := expandURLTemplate("https://api.example.com/cities/{city}/weather?units={units}&q={city}", ManifestToolArguments{
+		"city":  "New York/NY",
+		"units": "metric & more",
+	})
+	want := "https://api.example.com/cities/New%20York%2FNY/weather?units=metric+%26+more&q=New+York%2FNY"
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestLoadToolManifestsRejectsHosts(t *testing.T) {
+	allowed := []string{"api.example.com"}
+	tests := []struct {
+		name     string
+		manifest string
+		wantErr  string
+	}{
+		{"allowed host", `{"name":"city_info","url_template":"https://api.example.com/cities/{city}","arguments":[{"name":"city","required":true}]}`, ""},
+		{"host off the allowlist", `{"name":"evil","url_template":"https://evil.example.net/steal"}`, "not in the host allowlist"},
+		{"placeholder in the host", `{"name":"anyhost","url_template":"https://{host}/x","arguments":[{"name":"host"}]}`, "url_template"},
+		{"non-http scheme", `{"name":"local","url_template":"file:///etc/passwd"}`, "scheme"},
+		{"undeclared placeholder", `{"name":"typo","url_template":"https://api.example.com/{cty}"}`, "undeclared argument"},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "tool.json"), []byte(tt.manifest), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		tools, err := LoadToolManifests(dir, allowed)
+		if tt.wantErr == "" {
+			if err != nil || len(tools) != 1 {
+				t.Errorf("%s: got %d tools and error %v, want the tool loaded", tt.name, len(tools), err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: got error %v, want one mentioning %q", tt.name, err, tt.wantErr)
+		}
+		if len(tools) != 0 {
+			t.Errorf("%s: rejected manifest still produced a tool", tt.name)
+		}
+	}
+}
+
+func TestManifestToolUsesClientOptions(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(strings.Repeat("x", 4096)))
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+	manifest := `{"name":"big","url_template":"` + server.URL + `/big"}`
+	if err := os.WriteFile(filepath.Join(dir, "big.json"), []byte(manifest), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	call := func(opts ...ClientOption) string {
+		t.Helper()
+		tools, err := LoadToolManifests(dir, []string{"127.0.0.1"}, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := tools[0].Handler(context.Background(), ManifestToolArguments{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return toolText(t, resp)
+	}
+
+	if text := call(WithMaxBodySize(1024)); !strings.Contains(text, "response too large: big API sent more than 1024 bytes") {
+		t.Errorf("got %q, want the body refused past the configured limit", text)
+	}
+	requests.Store(0)
+	if text := call(WithOffline()); !strings.Contains(text, "not available in offline mode") || requests.Load() != 0 {
+		t.Errorf("got %q after %d requests, want the call refused offline", text, requests.Load())
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxMarketChartDays is the longest window the market chart tools accept.
+const maxMarketChartDays = 365
+
+// CoinGeckoMarketChartResponse represents the parts of the CoinGecko coins/{id}/market_chart response we use.
+// Each price is a [timestamp in milliseconds, price] pair.
+type CoinGeckoMarketChartResponse struct {
+	Prices [][]float64 `json:"prices"`
+}
+
+// PricePoint is a single price sample from a market chart.
+type PricePoint struct {
+	Time  time.Time
+	Price float64
+}
+
+// MarketChart retrieves the price history of a coin in the specified currency over the last days days,
+// sorted from oldest to newest. CoinGecko picks the sample interval from the length of the window.
+func (c *CryptoClient) MarketChart(ctx context.Context, coinID, currency string, days int) ([]PricePoint, error) {
+	coinID = strings.ToLower(coinID)
+	query := url.Values{}
+	query.Set("vs_currency", strings.ToLower(currency))
+	query.Set("days", strconv.Itoa(days))
+
+	var data CoinGeckoMarketChartResponse
+	err := c.getJSON(ctx, "/coins/"+url.PathEscape(coinID)+"/market_chart", query, &data)
+	if isStatus(err, http.StatusNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrCoinNotFound, coinID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseMarketChart(data)
+}
+
+// parseMarketChart converts the array-of-arrays price series from CoinGecko into PricePoints sorted by time.
+func parseMarketChart(data CoinGeckoMarketChartResponse) ([]PricePoint, error) {
+	points := make([]PricePoint, 0, len(data.Prices))
+	for _, pair := range data.Prices {
+		if len(pair) != 2 {
+			return nil, fmt.Errorf("unexpected market chart entry with %d values", len(pair))
+		}
+		points = append(points, PricePoint{
+			Time:  time.UnixMilli(int64(pair[0])).UTC(),
+			Price: pair[1],
+		})
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].Time.Before(points[j].Time)
+	})
+	return points, nil
+}
+
+// dailyCloses buckets points, which must be sorted by time, into UTC days and keeps the last sample of each day.
+// CoinGecko samples short windows every few minutes or hours and long windows daily, so this gives a regular
+// series whatever the window length.
+func dailyCloses(points []PricePoint) []PricePoint {
+	var closes []PricePoint
+	for _, p := range points {
+		day := p.Time.UTC().Truncate(24 * time.Hour)
+		if n := len(closes); n > 0 && closes[n-1].Time.UTC().Truncate(24*time.Hour).Equal(day) {
+			closes[n-1] = p
+			continue
+		}
+		closes = append(closes, p)
+	}
+	return closes
+}
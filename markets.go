@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// maxTopCoins caps how many coins top_coins lists.
+const maxTopCoins = 50
+
+// Output formats accepted by top_coins.
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
+// CoinGeckoMarket represents the parts of one entry of the CoinGecko coins/markets response we use.
+// CoinGecko reports null for values it doesn't have, such as the 24h change of a newly listed coin.
+type CoinGeckoMarket struct {
+	ID                       string   `json:"id"`
+	Symbol                   string   `json:"symbol"`
+	Name                     string   `json:"name"`
+	CurrentPrice             *float64 `json:"current_price"`
+	MarketCap                *float64 `json:"market_cap"`
+	MarketCapRank            *int     `json:"market_cap_rank"`
+	PriceChangePercentage24h *float64 `json:"price_change_percentage_24h"`
+}
+
+// TopCoinsArguments defines the structure for arguments used to list the largest coins by market cap.
+type TopCoinsArguments struct {
+	N        int    `json:"n" jsonschema:"default=10,description=How many coins to list; capped at 50"`
+	Currency string `json:"currency" jsonschema:"description=The currency to price the coins in (USD, EUR, GBP, etc)"`
+	Format   string `json:"format" jsonschema:"enum=text,enum=json,default=text,description=text for an aligned table or json"`
+	Stream   bool   `json:"stream" jsonschema:"description=Report progress while the market data is fetched; clients without progress notifications get a text table in chunks"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting numeric arguments given as numeric strings too.
+func (a *TopCoinsArguments) UnmarshalJSON(data []byte) error {
+	type plain TopCoinsArguments
+	return decodeArguments(data, (*plain)(a))
+}
+
+// TopCoin is one row of the top_coins result.
+type TopCoin struct {
+	Rank      int      `json:"rank"`
+	ID        string   `json:"id"`
+	Symbol    string   `json:"symbol"`
+	Name      string   `json:"name"`
+	Price     *float64 `json:"price"`
+	MarketCap *float64 `json:"market_cap"`
+	Change24h *float64 `json:"change_24h_percent"`
+}
+
+// TopCoinsResult is the JSON form of the top_coins result.
+type TopCoinsResult struct {
+	Currency string    `json:"currency"`
+	Coins    []TopCoin `json:"coins"`
+}
+
+// Markets retrieves the n largest coins by market cap, priced in the specified currency.
+func (c *CryptoClient) Markets(ctx context.Context, currency string, n int) ([]CoinGeckoMarket, error) {
+	query := url.Values{}
+	query.Set("vs_currency", strings.ToLower(currency))
+	query.Set("order", "market_cap_desc")
+	query.Set("per_page", strconv.Itoa(n))
+	query.Set("page", "1")
+	query.Set("sparkline", "false")
+	query.Set("price_change_percentage", "24h")
+
+	var markets []CoinGeckoMarket
+	if err := c.getJSON(ctx, "/coins/markets", query, &markets); err != nil {
+		return nil, err
+	}
+	return markets, nil
+}
+
+// CoinMarkets retrieves the markets entries of the coins with the given CoinGecko ids with a single request.
+// The returned map is keyed by lowercase coin id; coins CoinGecko doesn't know are absent.
+func (c *CryptoClient) CoinMarkets(ctx context.Context, coinIDs []string, currency string) (map[string]CoinGeckoMarket, error) {
+	ids := make([]string, len(coinIDs))
+	for i, id := range coinIDs {
+		ids[i] = strings.ToLower(id)
+	}
+	query := url.Values{}
+	query.Set("vs_currency", strings.ToLower(currency))
+	query.Set("ids", strings.Join(ids, ","))
+	query.Set("sparkline", "false")
+	query.Set("price_change_percentage", "24h")
+
+	var markets []CoinGeckoMarket
+	if err := c.getJSON(ctx, "/coins/markets", query, &markets); err != nil {
+		return nil, err
+	}
+	byID := make(map[string]CoinGeckoMarket, len(markets))
+	for _, m := range markets {
+		byID[m.ID] = m
+	}
+	return byID, nil
+}
+
+// topCoins converts CoinGecko market entries into result rows, numbering coins without a rank by position.
+func topCoins(markets []CoinGeckoMarket) []TopCoin {
+	coins := make([]TopCoin, len(markets))
+	for i, m := range markets {
+		rank := i + 1
+		if m.MarketCapRank != nil {
+			rank = *m.MarketCapRank
+		}
+		coins[i] = TopCoin{
+			Rank:      rank,
+			ID:        m.ID,
+			Symbol:    strings.ToUpper(m.Symbol),
+			Name:      m.Name,
+			Price:     m.CurrentPrice,
+			MarketCap: m.MarketCap,
+			Change24h: m.PriceChangePercentage24h,
+		}
+	}
+	return coins
+}
+
+// formatTopCoins renders coins as an aligned text table.
+func formatTopCoins(coins []TopCoin, currency string) string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "#\tCoin\tPrice (%s)\t24h\t\n", currency)
+	for _, coin := range coins {
+		price, change := "n/a", "n/a"
+		if coin.Price != nil {
+			price = tablePrice(*coin.Price, currency)
+		}
+		if coin.Change24h != nil {
+			change = fmt.Sprintf("%+.2f%%", *coin.Change24h)
+		}
+		fmt.Fprintf(w, "%d\t%s (%s)\t%s\t%s\t\n", coin.Rank, coin.Name, coin.Symbol, price, change)
+	}
+	w.Flush()
+	return sb.String()
+}
+
+// tablePrice formats a price for the table with the decimals conventional for currency, keeping significant digits
+// for coins worth less than one unit.
+func tablePrice(price float64, currency string) string {
+	if price < 1 {
+		rounded, _ := strconv.ParseFloat(strconv.FormatFloat(price, 'g', 4, 64), 64)
+		return strconv.FormatFloat(rounded, 'f', -1, 64)
+	}
+	return formatAmount(price, currency)
+}
+
+// topCoinsTool returns the handler for the top_coins tool, fetching market data with client.
+func topCoinsTool(client *CryptoClient) func(context.Context, TopCoinsArguments) (*mcp_golang.ToolResponse, error) {
+	return func(ctx context.Context, arguments TopCoinsArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", "top_coins", "n", arguments.N, "currency", arguments.Currency, "format", arguments.Format, "stream", arguments.Stream)
+
+		n := min(arguments.N, maxTopCoins)
+		if n < 1 {
+			return toolFailure("error listing top coins", fmt.Errorf("n must be at least 1, got %d", n))
+		}
+		format := strings.ToLower(strings.TrimSpace(arguments.Format))
+		if format != formatText && format != formatJSON {
+			return toolFailure("error listing top coins", fmt.Errorf("unknown format %q, expected %s or %s", arguments.Format, formatText, formatJSON))
+		}
+		currency, err := NormalizeCurrency(currencyOrDefault(arguments.Currency))
+		if err != nil {
+			return toolFailure("error listing top coins", err)
+		}
+
+		progress := newToolProgress(ctx, arguments.Stream)
+		progress.Report(ctx, 0, n, fmt.Sprintf("fetching the top %d coins", n))
+		markets, err := client.Markets(ctx, currency, n)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error fetching coin markets", "tool", "top_coins", "currency", currency, "error", err)
+			return toolFailure("error listing top coins", err)
+		}
+		coins := topCoins(markets)
+		progress.Report(ctx, n, n, fmt.Sprintf("fetched %d/%d coins", len(coins), n))
+
+		if format == formatJSON {
+			return NewJSONToolResponse(TopCoinsResult{Currency: currency, Coins: coins})
+		}
+		return progress.Respond(formatTopCoins(coins, currency)), nil
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// cannedMarkets is a coins/markets payload in CoinGecko's full shape, including a coin with null values.
+const cannedMarkets = `[
+	{"id":"bitcoin","symbol":"btc","name":"Bitcoin","image":"https://example.com/btc.png","current_price":50000,
+	 "market_cap":980000000000,"market_cap_rank":1,"total_volume":25000000000,"high_24h":51000,"low_24h":49000,
+	 "price_change_percentage_24h":2.5,"circulating_supply":19600000,"roi":null,"last_updated":"2024-03-01T09:30:00.000Z"},
+	{"id":"new-coin","symbol":"new","name":"New Coin","current_price":0.0123,"market_cap":null,"market_cap_rank":null,
+	 "price_change_percentage_24h":null,"roi":{"times":1.2,"currency":"usd","percentage":120}}
+]`
+
+func TestTopCoinsParsesMarketsPayload(t *testing.T) {
+	var perPage string
+	transport := &countingTransport{respond: func(req *http.Request) (*http.Response, error) {
+		perPage = req.URL.Query().Get("per_page")
+		return cannedResponse(req, http.StatusOK, cannedMarkets), nil
+	}}
+	resp, err := topCoinsTool(testCryptoClient(transport))(context.Background(), TopCoinsArguments{N: 100, Currency: "usd", Format: "json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perPage != "50" {
+		t.Errorf("requested %s coins, want n capped at 50", perPage)
+	}
+
+	var result TopCoinsResult
+	if err := json.Unmarshal([]byte(toolText(t, resp)), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Currency != "USD" || len(result.Coins) != 2 {
+		t.Fatalf("got %+v, want two coins priced in USD", result)
+	}
+	btc := result.Coins[0]
+	if btc.Rank != 1 || btc.Symbol != "BTC" || btc.Price == nil || *btc.Price != 50000 || btc.Change24h == nil || *btc.Change24h != 2.5 {
+		t.Errorf("got %+v, want bitcoin ranked 1st at 50000 USD, up 2.5%%", btc)
+	}
+	// Coins without a rank are numbered by position; their null values stay null
+	newCoin := result.Coins[1]
+	if newCoin.Rank != 2 || newCoin.MarketCap != nil || newCoin.Change24h != nil {
+		t.Errorf("got %+v, want rank 2 with no market cap or change", newCoin)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// latencyBuckets are the upper bounds, in seconds, of the tool call latency histogram.
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Outcome labels recorded for each tool call.
+const (
+	outcomeOK    = "ok"
+	outcomeError = "error"
+)
+
+// callKey identifies a counter series by tool name and outcome.
+type callKey struct {
+	tool    string
+	outcome string
+}
+
+// histogram is a cumulative latency histogram using latencyBuckets.
+type histogram struct {
+	counts []uint64
+	sum    float64
+	total  uint64
+}
+
+// metricsRegistry records per-tool invocation counts and latencies, safe for concurrent use.
+type metricsRegistry struct {
+	mu        sync.Mutex
+	calls     map[callKey]uint64
+	latencies map[string]*histogram
+}
+
+// metrics is the registry shared by all tool handlers.
+var metrics = newMetricsRegistry()
+
+// newMetricsRegistry creates an empty metricsRegistry.
+func newMetricsRegistry() *metricsRegistry {
+	return &metricsRegistry{
+		calls:     make(map[callKey]uint64),
+		latencies: make(map[string]*histogram),
+	}
+}
+
+// Observe records one call of tool with the given outcome and duration.
+func (m *metricsRegistry) Observe(tool, outcome string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls[callKey{tool: tool, outcome: outcome}]++
+
+	h, ok := m.latencies[tool]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(latencyBuckets))}
+		m.latencies[tool] = h
+	}
+	seconds := d.Seconds()
+	for i, bound := range latencyBuckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.total++
+}
+
+// WriteTo writes all metrics to w in the Prometheus text exposition format.
+func (m *metricsRegistry) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cw := &countingWriter{w: w}
+
+	keys := make([]callKey, 0, len(m.calls))
+	for k := range m.calls {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].tool != keys[j].tool {
+			return keys[i].tool < keys[j].tool
+		}
+		return keys[i].outcome < keys[j].outcome
+	})
+
+	fmt.Fprintln(cw, "# HELP mcp_tool_calls_total Total number of tool invocations.")
+	fmt.Fprintln(cw, "# TYPE mcp_tool_calls_total counter")
+	for _, k := range keys {
+		fmt.Fprintf(cw, "mcp_tool_calls_total{tool=%q,outcome=%q} %d\n", k.tool, k.outcome, m.calls[k])
+	}
+
+	tools := make([]string, 0, len(m.latencies))
+	for tool := range m.latencies {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+
+	fmt.Fprintln(cw, "# HELP mcp_tool_call_duration_seconds Latency of tool invocations.")
+	fmt.Fprintln(cw, "# TYPE mcp_tool_call_duration_seconds histogram")
+	for _, tool := range tools {
+		h := m.latencies[tool]
+		for i, bound := range latencyBuckets {
+			fmt.Fprintf(cw, "mcp_tool_call_duration_seconds_bucket{tool=%q,le=%q} %d\n", tool, strconv.FormatFloat(bound, 'g', -1, 64), h.counts[i])
+		}
+		fmt.Fprintf(cw, "mcp_tool_call_duration_seconds_bucket{tool=%q,le=\"+Inf\"} %d\n", tool, h.total)
+		fmt.Fprintf(cw, "mcp_tool_call_duration_seconds_sum{tool=%q} %g\n", tool, h.sum)
+		fmt.Fprintf(cw, "mcp_tool_call_duration_seconds_count{tool=%q} %d\n", tool, h.total)
+	}
+
+	return cw.n, cw.err
+}
+
+// ServeHTTP exposes the registry for Prometheus scraping.
+func (m *metricsRegistry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	m.WriteTo(w)
+}
+
+// countingWriter tracks bytes written and the first write error.
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+// Write implements io.Writer.
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"golang.org/x/time/rate"
+)
+
+// ToolHandler is a tool handler with its arguments type-erased, so middleware can be shared by every tool.
+type ToolHandler = func(ctx context.Context, arguments any) (*mcp_golang.ToolResponse, error)
+
+// Middleware decorates a ToolHandler with cross-cutting behaviour.
+type Middleware func(ToolHandler) ToolHandler
+
+// Chain wraps h with mws so that the first middleware is the outermost one.
+func Chain(h ToolHandler, mws ...Middleware) ToolHandler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// WithLogging logs every invocation of the named tool with its duration and, on failure, the error, and counts
+// it in the call counters reported by the stats tool.
+// The request ID stored in ctx by assignRequestID is included in both cases.
+func WithLogging(name string) Middleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, arguments any) (*mcp_golang.ToolResponse, error) {
+			calls.Inc(name)
+			start := time.Now()
+			resp, err := next(ctx, arguments)
+			if err != nil {
+				slog.ErrorContext(ctx, "Tool call failed", "tool", name, "duration", time.Since(start), "error", err)
+			} else {
+				slog.InfoContext(ctx, "Tool call completed", "tool", name, "duration", time.Since(start))
+			}
+			return resp, err
+		}
+	}
+}
+
+// WithTiming records the outcome and latency of every invocation of the named tool in the metrics registry.
+func WithTiming(name string) Middleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, arguments any) (*mcp_golang.ToolResponse, error) {
+			start := time.Now()
+			resp, err := next(ctx, arguments)
+			outcome := outcomeOK
+			if err != nil {
+				outcome = outcomeError
+			}
+			metrics.Observe(name, outcome, time.Since(start))
+			return resp, err
+		}
+	}
+}
+
+// WithRecover converts a panic in the named tool into an error response so it cannot crash the server.
+func WithRecover(name string) Middleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, arguments any) (resp *mcp_golang.ToolResponse, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					slog.ErrorContext(ctx, "Recovered from panic in tool", "tool", name, "panic", r, "stack", string(debug.Stack()))
+					resp, err = nil, fmt.Errorf("tool %s failed unexpectedly: %v", name, r)
+				}
+			}()
+			return next(ctx, arguments)
+		}
+	}
+}
+
+// trackInFlight lets graceful shutdown wait for running invocations to complete, failing calls that arrive once
+// shutdown has begun.
+func trackInFlight(next ToolHandler) ToolHandler {
+	return func(ctx context.Context, arguments any) (*mcp_golang.ToolResponse, error) {
+		if !inFlight.Start() {
+			return nil, ErrShuttingDown
+		}
+		defer inFlight.Finish()
+		return next(ctx, arguments)
+	}
+}
+
+// wrapTool decorates a typed tool handler with the standard middleware chain followed by any extra middleware, and
+// returns a handler with the same signature, so the library can still derive the input schema from the arguments type.
+func wrapTool[T any](name string, handler func(context.Context, T) (*mcp_golang.ToolResponse, error), extra ...Middleware) func(context.Context, T) (*mcp_golang.ToolResponse, error) {
+	mws := append([]Middleware{assignRequestID, trackInFlight, WithLogging(name), WithTiming(name), WithHistory(name), WithConcurrencyLimit(name), WithDeadline(name), WithRecover(name)}, extra...)
+	h := Chain(func(ctx context.Context, arguments any) (*mcp_golang.ToolResponse, error) {
+		return handler(ctx, arguments.(T))
+	}, mws...)
+
+	return func(ctx context.Context, arguments T) (*mcp_golang.ToolResponse, error) {
+		// Fill in omitted arguments from their default= tags before any middleware or the handler sees them
+		if err := applyDefaults(&arguments); err != nil {
+			return nil, err
+		}
+		return h(ctx, arguments)
+	}
+}
+
+// recoverPrompt wraps a prompt handler so that a panic is logged and returned as an error instead of crashing the server.
+func recoverPrompt[T any](name string, handler func(T) (*mcp_golang.PromptResponse, error)) func(T) (*mcp_golang.PromptResponse, error) {
+	return func(arguments T) (resp *mcp_golang.PromptResponse, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("Recovered from panic in prompt", "prompt", name, "panic", r, "stack", string(debug.Stack()))
+				resp, err = nil, fmt.Errorf("prompt %s failed unexpectedly: %v", name, r)
+			}
+		}()
+		return handler(arguments)
+	}
+}
+
+// recoverResource wraps a resource handler so that a panic is logged and returned as an error instead of crashing the server.
+func recoverResource(uri string, handler func() (*mcp_golang.ResourceResponse, error)) func() (*mcp_golang.ResourceResponse, error) {
+	return func() (resp *mcp_golang.ResourceResponse, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("Recovered from panic in resource", "uri", uri, "panic", r, "stack", string(debug.Stack()))
+				resp, err = nil, fmt.Errorf("resource %s failed unexpectedly: %v", uri, r)
+			}
+		}()
+		return handler()
+	}
+}
+
+// WithRateLimit fails calls with ErrServerBusy when limiter has no tokens left, instead of hitting the upstream API
+// and getting throttled. A nil limiter disables rate limiting.
+func WithRateLimit(name string, limiter *rate.Limiter) Middleware {
+	return func(next ToolHandler) ToolHandler {
+		if limiter == nil {
+			return next
+		}
+		return func(ctx context.Context, arguments any) (*mcp_golang.ToolResponse, error) {
+			if !limiter.Allow() {
+				slog.WarnContext(ctx, "Rate limit exceeded", "tool", name)
+				return toolFailure("error calling "+name, fmt.Errorf("%w: the %s rate limit is used up", ErrServerBusy, name))
+			}
+			return next(ctx, arguments)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"golang.org/x/time/rate"
+)
+
+func TestWrapToolRecoversFromPanic(t *testing.T) {
+	calls := 0
+	tool := wrapTool("boom", func(ctx context.Context, arguments MyFunctionsArguments) (*mcp_golang.ToolResponse, error) {
+		calls++
+		panic("deliberate")
+	})
+
+	// A second call proves the first panic didn't take the server down with it
+	for i := range 2 {
+		resp, err := tool(context.Background(), MyFunctionsArguments{Submitter: "test"})
+		if err == nil || !strings.Contains(err.Error(), "deliberate") {
+			t.Errorf("call %d: got error %v, want one reporting the panic", i+1, err)
+		}
+		if resp != nil {
+			t.Errorf("call %d: got response %v alongside the error", i+1, resp)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("handler ran %d times, want 2", calls)
+	}
+}
+
+func TestWithRateLimitRejectsOverBurst(t *testing.T) {
+	// A zero rate never refills, so exactly the burst is let through
+	calls := 0
+	h := WithRateLimit("bitcoin_price", rate.NewLimiter(0, 2))(func(ctx context.Context, arguments any) (*mcp_golang.ToolResponse, error) {
+		calls++
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent("ok")), nil
+	})
+
+	for i := range 3 {
+		resp, err := h(context.Background(), nil)
+		if busy := errors.Is(err, ErrServerBusy); busy != (i == 2) {
+			t.Errorf("call %d: got response %v and error %v", i+1, resp, err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("handler ran %d times, want 2", calls)
+	}
+}
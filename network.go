@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// mempoolHashrateResponse represents the parts of the mempool.space mining/hashrate response we use. The hashrate
+// is in hashes per second.
+type mempoolHashrateResponse struct {
+	CurrentHashrate   float64 `json:"currentHashrate"`
+	CurrentDifficulty float64 `json:"currentDifficulty"`
+}
+
+// NetworkStats is the current state of the Bitcoin network. It is what btc_network returns, so it stays the same
+// whichever provider the numbers come from.
+type NetworkStats struct {
+	BlockHeight int64     `json:"block_height" jsonschema:"description=The height of the latest block"`
+	Difficulty  float64   `json:"difficulty" jsonschema:"description=The current mining difficulty"`
+	Hashrate    float64   `json:"hashrate" jsonschema:"description=The estimated network hashrate in hashes per second"`
+	Source      string    `json:"source" jsonschema:"description=The API the numbers came from"`
+	Timestamp   time.Time `json:"timestamp" jsonschema:"description=When the numbers were fetched"`
+}
+
+// BTCNetworkArguments defines the (empty) arguments of the btc_network tool.
+type BTCNetworkArguments struct{}
+
+// TipHeight retrieves the height of the latest block. mempool.space answers with the bare number as plain text.
+func (c *MempoolClient) TipHeight(ctx context.Context) (int64, error) {
+	resp, err := c.get(ctx, "/blocks/tip/height", nil)
+	if err != nil {
+		return 0, err
+	}
+	return parseTipHeight(c.name, resp.Body)
+}
+
+// parseTipHeight parses the body of a blocks/tip/height response from the named API.
+func parseTipHeight(name string, body []byte) (int64, error) {
+	height, err := strconv.ParseInt(strings.TrimSpace(string(body)), 10, 64)
+	if err != nil || height < 0 {
+		return 0, fmt.Errorf("%s API returned an invalid block height: %s", name, bodySnippet(body))
+	}
+	return height, nil
+}
+
+// NetworkStats retrieves the current block height, difficulty and hashrate with one request for the height and one
+// for the mining figures.
+func (c *MempoolClient) NetworkStats(ctx context.Context) (NetworkStats, error) {
+	height, err := c.TipHeight(ctx)
+	if err != nil {
+		return NetworkStats{}, err
+	}
+
+	var mining mempoolHashrateResponse
+	if err := c.getJSON(ctx, "/v1/mining/hashrate/3d", nil, &mining); err != nil {
+		return NetworkStats{}, err
+	}
+	if mining.CurrentDifficulty <= 0 || mining.CurrentHashrate <= 0 {
+		return NetworkStats{}, fmt.Errorf("%s API returned no difficulty or hashrate", c.name)
+	}
+
+	return NetworkStats{
+		BlockHeight: height,
+		Difficulty:  mining.CurrentDifficulty,
+		Hashrate:    mining.CurrentHashrate,
+		Source:      c.name,
+		Timestamp:   time.Now().UTC(),
+	}, nil
+}
+
+// btcNetworkTool returns the handler for the btc_network tool, fetching the network stats with client.
+func btcNetworkTool(client *MempoolClient) func(context.Context, BTCNetworkArguments) (*mcp_golang.ToolResponse, error) {
+	return func(ctx context.Context, _ BTCNetworkArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", "btc_network")
+
+		stats, err := client.NetworkStats(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error fetching network stats", "tool", "btc_network", "error", err)
+			return toolFailure("error fetching Bitcoin network stats", err)
+		}
+		return NewJSONToolResponse(stats)
+	}
+}
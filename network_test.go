@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// cannedHashrate is a mempool.space mining/hashrate/3d payload in its full shape.
+const cannedHashrate = `{
+	"hashrates": [{"timestamp":1709251200,"avgHashrate":6.01e20},{"timestamp":1709337600,"avgHashrate":6.12e20}],
+	"difficulty": [{"time":1709251200,"height":832000,"difficulty":79351228131136.77,"adjustment":1.0157}],
+	"currentHashrate": 6.05e20,
+	"currentDifficulty": 79351228131136.77
+}`
+
+// mempoolStub answers the tip height as plain text, the way mempool.space does, and the mining figures as JSON.
+func mempoolStub(tipHeight string) *countingTransport {
+	return &countingTransport{respond: func(req *http.Request) (*http.Response, error) {
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/blocks/tip/height"):
+			resp := cannedResponse(req, http.StatusOK, tipHeight)
+			resp.Header.Set("Content-Type", "text/plain")
+			return resp, nil
+		case strings.HasSuffix(req.URL.Path, "/v1/mining/hashrate/3d"):
+			return cannedResponse(req, http.StatusOK, cannedHashrate), nil
+		}
+		return cannedResponse(req, http.StatusNotFound, "Not found"), nil
+	}}
+}
+
+func TestNetworkStatsParsesPayloads(t *testing.T) {
+	transport := mempoolStub("832145\n")
+	stats, err := testMempoolClient(transport).NetworkStats(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.BlockHeight != 832145 || stats.Difficulty != 79351228131136.77 || stats.Hashrate != 6.05e20 || stats.Source != "mempool.space" || stats.Timestamp.IsZero() {
+		t.Errorf("got %+v, want height 832145 with the current difficulty and hashrate", stats)
+	}
+	if n := transport.requests.Load(); n != 2 {
+		t.Errorf("made %d requests, want 2", n)
+	}
+
+	// The tool's output is the stable struct, whatever the provider's fields are called
+	resp, err := btcNetworkTool(testMempoolClient(transport))(context.Background(), BTCNetworkArguments{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(toolText(t, resp)), &fields); err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"block_height", "difficulty", "hashrate", "source", "timestamp"} {
+		if _, ok := fields[field]; !ok {
+			t.Errorf("output has no %s field: %v", field, fields)
+		}
+	}
+}
+
+func TestParseTipHeightRejectsGarbage(t *testing.T) {
+	for _, body := range []string{"", "not a number", "-1", "832145.5"} {
+		if _, err := parseTipHeight("mempool.space", []byte(body)); err == nil {
+			t.Errorf("tip height %q was accepted", body)
+		}
+	}
+	if _, err := testMempoolClient(mempoolStub("<html>oops</html>")).NetworkStats(context.Background()); err == nil || !strings.Contains(err.Error(), "invalid block height") {
+		t.Errorf("got error %v, want the invalid height reported", err)
+	}
+}
+
+func TestBTCNetworkFailsWhenUpstreamIsDown(t *testing.T) {
+	_, err := btcNetworkTool(testMempoolClient(offlineTransport()))(context.Background(), BTCNetworkArguments{})
+	if !errors.Is(err, ErrUpstreamUnavailable) || !strings.HasPrefix(err.Error(), "error fetching Bitcoin network stats: ") {
+		t.Errorf("got error %v, want the outage returned", err)
+	}
+}
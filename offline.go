@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// simulatedNote is appended to the output of price tools in offline mode so nobody mistakes fixtures for live prices.
+const simulatedNote = "Note: the server is running in offline mode, so this is simulated fixture data, not a live price."
+
+// offlineUSDPrices are the fixed USD prices served for each coin in offline mode.
+var offlineUSDPrices = map[string]float64{
+	"bitcoin":  65000,
+	"ethereum": 3500,
+	"solana":   150,
+	"dogecoin": 0.15,
+	"cardano":  0.45,
+}
+
+// offlineFXRates convert the offline USD prices into the other supported currencies.
+var offlineFXRates = map[string]float64{
+	"AUD": 1.52,
+	"BRL": 5.40,
+	"CAD": 1.37,
+	"CHF": 0.88,
+	"CNY": 7.20,
+	"DKK": 6.85,
+	"EUR": 0.92,
+	"GBP": 0.79,
+	"HKD": 7.80,
+	"INR": 83.50,
+	"JPY": 150,
+	"KRW": 1350,
+	"MXN": 17.50,
+	"NOK": 10.70,
+	"NZD": 1.65,
+	"PLN": 3.95,
+	"RUB": 92,
+	"SEK": 10.60,
+	"SGD": 1.34,
+	"TRY": 32,
+	"USD": 1,
+	"ZAR": 18.70,
+}
+
+// onlineOnlyTools are the tools backed only by upstream endpoints that have no offline fixtures, such as coin
+// history, market charts, market listings, global market data and exchange tickers. In offline mode they could
+// only ever fail, so they are not registered at all.
+var onlineOnlyTools = map[string]bool{
+	"bitcoin_change":            true,
+	"bitcoin_price_on":          true,
+	"bitcoin_price_on_exchange": true,
+	"bitcoin_sma":               true,
+	"bitcoin_sparkline":         true,
+	"bitcoin_stats":             true,
+	"bitcoin_trend":             true,
+	"btc_fee_estimate":          true,
+	"btc_network":               true,
+	"compare_coins":             true,
+	"dca_simulate":              true,
+	"global_market":             true,
+	"search_coins":              true,
+	"top_coins":                 true,
+	"weather":                   true,
+}
+
+// WithOffline makes the client refuse to perform any HTTP request. The CoinGecko client answers price lookups
+// from fixture data instead; every other request fails with an offline error.
+func WithOffline() ClientOption {
+	return func(c *apiClient) {
+		c.offline = true
+	}
+}
+
+// Offline reports whether the client is in offline mode.
+func (c *apiClient) Offline() bool {
+	return c.offline
+}
+
+// offlineSimplePrice answers a simple/price request from the fixtures, in the same shape CoinGecko would.
+// Unknown coins and currencies are omitted, as CoinGecko does.
+func offlineSimplePrice(coinIDs, currencies []string) CoinGeckoPriceResponse {
+	data := make(CoinGeckoPriceResponse)
+	for _, coinID := range coinIDs {
+		usd, ok := offlineUSDPrices[coinID]
+		if !ok {
+			continue
+		}
+		prices := make(map[string]float64)
+		for _, currency := range currencies {
+			if rate, ok := offlineFXRates[strings.ToUpper(currency)]; ok {
+				prices[strings.ToLower(currency)] = usd * rate
+			}
+		}
+		data[coinID] = prices
+	}
+	return data
+}
+
+// markSimulated appends the simulated data note to text when client is offline.
+func markSimulated(client *CryptoClient, text string) string {
+	if !client.Offline() {
+		return text
+	}
+	return fmt.Sprintf("%s\n%s", strings.TrimRight(text, "\n"), simulatedNote)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestOfflinePriceMakesNoRequests(t *testing.T) {
+	transport := offlineTransport()
+	svc := testServices(t, Config{Offline: true}, transport)
+	resp, err := bitcoinPriceTool(svc.crypto, svc.cache, svc.priceTemplate)(context.Background(), BitcoinPriceArguments{Currency: "USD"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := toolText(t, resp)
+	if !strings.Contains(text, "65,000.00") || !strings.Contains(text, simulatedNote) {
+		t.Errorf("got %q, want the fixture price marked as simulated", text)
+	}
+	if n := transport.requests.Load(); n != 0 {
+		t.Errorf("made %d HTTP requests in offline mode, want none", n)
+	}
+}
+
+func TestOfflineLeavesOutToolsWithoutFixtures(t *testing.T) {
+	logs := captureLogs(t)
+	if _, err := registerTestServer(t, Config{Offline: true}); err != nil {
+		t.Fatal(err)
+	}
+	names := registeredNames()
+	for name := range onlineOnlyTools {
+		if slices.Contains(names, name) {
+			t.Errorf("%s is registered in offline mode", name)
+		}
+	}
+	for _, name := range []string{"bitcoin_price", "crypto_price", "convert"} {
+		if !slices.Contains(names, name) {
+			t.Errorf("%s is not registered in offline mode", name)
+		}
+	}
+	if !strings.Contains(strings.Join(logs.Lines(), "\n"), "Tools not registered in offline mode") {
+		t.Error("the tools left out in offline mode were not logged")
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// Holding is an amount of one coin held in a portfolio.
+type Holding struct {
+	CoinID string  `json:"coin_id" jsonschema:"required,description=The CoinGecko id of the coin (bitcoin, ethereum, solana, etc)"`
+	Amount float64 `json:"amount" jsonschema:"required,description=The non-negative amount of the coin held"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting numeric arguments given as numeric strings too.
+func (h *Holding) UnmarshalJSON(data []byte) error {
+	type plain Holding
+	return decodeArguments(data, (*plain)(h))
+}
+
+// PortfolioValueArguments defines the structure for arguments used to value a portfolio of coin holdings.
+type PortfolioValueArguments struct {
+	Holdings []Holding `json:"holdings" jsonschema:"required,description=The holdings to value with their coin id and amount"`
+	Currency string    `json:"currency" jsonschema:"description=The currency to value the portfolio in (USD, EUR, GBP, etc)"`
+	Locale   string    `json:"locale" jsonschema:"default=en-US,description=The locale to format the values for (en-US, de-DE, etc)"`
+	Stream   bool      `json:"stream" jsonschema:"description=Report progress while the prices are fetched; clients without progress notifications get the result in chunks"`
+}
+
+// portfolioValueTool returns the handler for the portfolio_value tool, fetching the prices with client.
+func portfolioValueTool(client *CryptoClient) func(context.Context, PortfolioValueArguments) (*mcp_golang.ToolResponse, error) {
+	return func(ctx context.Context, arguments PortfolioValueArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", "portfolio_value", "holdings", len(arguments.Holdings), "currency", arguments.Currency, "locale", arguments.Locale, "stream", arguments.Stream)
+
+		printer, err := localePrinter(arguments.Locale)
+		if err != nil {
+			return toolFailure("error valuing portfolio", err)
+		}
+		// Fall back to the configured default currency if none is specified
+		currency, err := NormalizeCurrency(currencyOrDefault(arguments.Currency))
+		if err != nil {
+			return toolFailure("error valuing portfolio", err)
+		}
+
+		// Normalize the holdings, and deduplicate their coin ids for the fetch while keeping every holding
+		holdings := make([]Holding, 0, len(arguments.Holdings))
+		var coinIDs []string
+		seen := make(map[string]bool)
+		for _, h := range arguments.Holdings {
+			h.CoinID = strings.ToLower(strings.TrimSpace(h.CoinID))
+			if h.CoinID == "" {
+				return toolFailure("error valuing portfolio", errors.New("every holding needs a coin_id"))
+			}
+			if h.Amount < 0 || math.IsNaN(h.Amount) || math.IsInf(h.Amount, 0) {
+				return toolFailure("error valuing portfolio", fmt.Errorf("amount of %s must be a non-negative number, got %v", h.CoinID, h.Amount))
+			}
+			holdings = append(holdings, h)
+			if !seen[h.CoinID] {
+				seen[h.CoinID] = true
+				coinIDs = append(coinIDs, h.CoinID)
+			}
+		}
+		if len(holdings) == 0 {
+			return toolFailure("error valuing portfolio", errors.New("no holdings given"))
+		}
+		if len(coinIDs) > maxBatchCoins {
+			return toolFailure("error valuing portfolio", fmt.Errorf("%d coins given, at most %d are allowed per call", len(coinIDs), maxBatchCoins))
+		}
+
+		// Fetch every coin with a single CoinGecko call, falling back to one call per coin if that fails
+		progress := newToolProgress(ctx, arguments.Stream)
+		progress.Report(ctx, 0, len(coinIDs), fmt.Sprintf("fetching prices for %d coins", len(coinIDs)))
+		var fetched atomic.Int64
+		prices, failed, err := fetchBatch(ctx, coinIDs,
+			func(ctx context.Context) (map[string]float64, error) {
+				return client.CoinPrices(ctx, coinIDs, currency)
+			},
+			func(ctx context.Context, id string) (float64, error) {
+				price, err := client.CryptoPrice(ctx, id, currency)
+				n := int(fetched.Add(1))
+				progress.Report(ctx, n, len(coinIDs), fmt.Sprintf("fetched %d/%d coins", n, len(coinIDs)))
+				return price, err
+			})
+		if err != nil {
+			slog.ErrorContext(ctx, "Error fetching portfolio prices", "tool", "portfolio_value", "coin_ids", coinIDs, "currency", currency, "error", err)
+			return toolFailure("error valuing portfolio", err)
+		}
+		if failed == nil {
+			progress.Report(ctx, len(coinIDs), len(coinIDs), fmt.Sprintf("fetched %d/%d coins", len(coinIDs), len(coinIDs)))
+		}
+
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "Portfolio value in %s (as of %s):\n", currency, time.Now().Format(time.RFC1123))
+		total := new(big.Rat)
+		var valued int
+		var warnings []string
+		for _, h := range holdings {
+			price, ok := prices[h.CoinID]
+			if !ok {
+				reason := error(ErrCoinNotFound)
+				if err, ok := failed[h.CoinID]; ok {
+					reason = err
+				}
+				warnings = append(warnings, fmt.Sprintf("Skipped %s %s: %v", strconv.FormatFloat(h.Amount, 'f', -1, 64), h.CoinID, reason))
+				continue
+			}
+			value := new(big.Rat).Mul(decimalOf(h.Amount), decimalOf(price))
+			total.Add(total, value)
+			valued++
+			fmt.Fprintf(&sb, "- %s %s at %s: %s\n", strconv.FormatFloat(h.Amount, 'f', -1, 64), h.CoinID, formatPrice(printer, price, currency), formatDecimalPrice(printer, value, currency))
+		}
+		if valued == 0 {
+			return toolFailure("error valuing portfolio", fmt.Errorf("no prices available for any holding: %s", strings.Join(warnings, "; ")))
+		}
+		fmt.Fprintf(&sb, "Total: %s %s\n", formatDecimalPrice(printer, total, currency), currency)
+		if len(warnings) > 0 {
+			fmt.Fprintf(&sb, "The total leaves out %d of %d holdings:\n", len(warnings), len(holdings))
+		}
+		for _, warning := range warnings {
+			fmt.Fprintf(&sb, "Warning: %s\n", warning)
+		}
+
+		return progress.Respond(markSimulated(client, sb.String())), nil
+	}
+}
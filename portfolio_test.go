@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestPortfolioValueReportsMissingPrices(t *testing.T) {
+	var ids string
+	transport := &countingTransport{respond: func(req *http.Request) (*http.Response, error) {
+		ids = req.URL.Query().Get("ids")
+		return cannedResponse(req, http.StatusOK, `{"bitcoin":{"usd":40000},"ethereum":{"usd":2000}}`), nil
+	}}
+	tool := portfolioValueTool(testCryptoClient(transport))
+	resp, err := tool(context.Background(), PortfolioValueArguments{
+		Holdings: []Holding{
+			{CoinID: "bitcoin", Amount: 0.5},
+			{CoinID: "Ethereum", Amount: 2},
+			{CoinID: "bitcoin", Amount: 0.25},
+			{CoinID: "dogecoin", Amount: 100},
+		},
+		Currency: "usd",
+		Locale:   "en-US",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := toolText(t, resp)
+
+	if n := transport.requests.Load(); n != 1 {
+		t.Errorf("made %d requests, want a single batch call", n)
+	}
+	if ids != "bitcoin,ethereum,dogecoin" {
+		t.Errorf("fetched ids %q, want each coin once", ids)
+	}
+	for _, want := range []string{
+		"- 0.5 bitcoin at 40,000.00: 20,000.00\n",
+		"- 2 ethereum at 2,000.00: 4,000.00\n",
+		"- 0.25 bitcoin at 40,000.00: 10,000.00\n",
+		"Total: 34,000.00 USD\n",
+		"The total leaves out 1 of 4 holdings:\n",
+		"Warning: Skipped 100 dogecoin: ",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output is missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestPortfolioValueWithNoPrices(t *testing.T) {
+	tool := portfolioValueTool(testCryptoClient(cannedJSON(`{}`)))
+	_, err := tool(context.Background(), PortfolioValueArguments{Holdings: []Holding{{CoinID: "dogecoin", Amount: 1}}, Currency: "USD"})
+	if err == nil || !strings.HasPrefix(err.Error(), "error valuing portfolio: no prices available for any holding") {
+		t.Errorf("got error %v, want every holding reported missing", err)
+	}
+	for _, holdings := range [][]Holding{nil, {{CoinID: " ", Amount: 1}}, {{CoinID: "bitcoin", Amount: -1}}} {
+		if _, err := tool(context.Background(), PortfolioValueArguments{Holdings: holdings, Currency: "USD"}); err == nil {
+			t.Errorf("holdings %v were accepted", holdings)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// BitcoinPriceAllArguments defines the (empty) arguments of the bitcoin_price_all tool.
+type BitcoinPriceAllArguments struct{}
+
+// CurrencyPrice is the Bitcoin price in one currency.
+type CurrencyPrice struct {
+	Currency string  `json:"currency" jsonschema:"description=The ISO 4217 code of the currency"`
+	Price    float64 `json:"price" jsonschema:"description=The Bitcoin price in the currency"`
+}
+
+// BitcoinPriceAllResult is the structured payload returned by the bitcoin_price_all tool.
+type BitcoinPriceAllResult struct {
+	Prices    []CurrencyPrice `json:"prices" jsonschema:"description=The Bitcoin price in every supported currency CoinGecko returned; highest price first"`
+	Timestamp time.Time       `json:"timestamp" jsonschema:"description=When the prices were fetched from CoinGecko"`
+	Simulated bool            `json:"simulated,omitempty" jsonschema:"description=Set when the server runs in offline mode and the prices are fixture data"`
+}
+
+// sortedPrices turns prices keyed by currency into a list ordered by price, highest first, and by currency
+// code between equal prices so the order is stable.
+func sortedPrices(prices map[string]float64) []CurrencyPrice {
+	list := make([]CurrencyPrice, 0, len(prices))
+	for currency, price := range prices {
+		list = append(list, CurrencyPrice{Currency: currency, Price: price})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Price != list[j].Price {
+			return list[i].Price > list[j].Price
+		}
+		return list[i].Currency < list[j].Currency
+	})
+	return list
+}
+
+// bitcoinPriceAllTool returns the handler for the bitcoin_price_all tool, fetching every price with one call to client.
+func bitcoinPriceAllTool(client *CryptoClient) func(context.Context, BitcoinPriceAllArguments) (*mcp_golang.ToolResponse, error) {
+	return func(ctx context.Context, _ BitcoinPriceAllArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", "bitcoin_price_all")
+
+		// CryptoPrices leaves out the currencies CoinGecko omitted, so they are skipped rather than shown as zero
+		currencies := supportedCurrencyList()
+		prices, err := client.CryptoPrices(ctx, "bitcoin", currencies)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error fetching Bitcoin prices", "tool", "bitcoin_price_all", "error", err)
+			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Error fetching Bitcoin prices: %s", describeError(err)))), nil
+		}
+		if len(prices) < len(currencies) {
+			slog.WarnContext(ctx, "CoinGecko omitted some currencies", "tool", "bitcoin_price_all", "requested", len(currencies), "returned", len(prices))
+		}
+
+		return NewJSONToolResponse(BitcoinPriceAllResult{
+			Prices:    sortedPrices(prices),
+			Timestamp: time.Now().UTC(),
+			Simulated: client.Offline(),
+		})
+	}
+}
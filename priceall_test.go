@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBitcoinPriceAllIsSortedAndSkipsMissingCurrencies(t *testing.T) {
+	var requested []string
+	transport := &countingTransport{respond: func(req *http.Request) (*http.Response, error) {
+		requested = strings.Split(req.URL.Query().Get("vs_currencies"), ",")
+		// CoinGecko leaves out currencies it has no price for; every other supported currency is missing here
+		return cannedResponse(req, http.StatusOK, `{"bitcoin":{"usd":50000,"gbp":40000,"jpy":7500000,"eur":46000,"chf":46000}}`), nil
+	}}
+	resp, err := bitcoinPriceAllTool(testCryptoClient(transport))(context.Background(), BitcoinPriceAllArguments{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := transport.requests.Load(); n != 1 || len(requested) != len(SupportedCurrencies) {
+		t.Errorf("made %d requests for %d currencies, want one for all %d supported", n, len(requested), len(SupportedCurrencies))
+	}
+
+	var result BitcoinPriceAllResult
+	if err := json.Unmarshal([]byte(toolText(t, resp)), &result); err != nil {
+		t.Fatal(err)
+	}
+	// Equal prices are ordered by currency code
+	want := []CurrencyPrice{{"JPY", 7500000}, {"USD", 50000}, {"CHF", 46000}, {"EUR", 46000}, {"GBP", 40000}}
+	if !slices.Equal(result.Prices, want) {
+		t.Errorf("got %v, want %v", result.Prices, want)
+	}
+	if result.Timestamp.IsZero() || result.Simulated {
+		t.Errorf("got timestamp %v and simulated %v, want a live fetch time", result.Timestamp, result.Simulated)
+	}
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"text/template"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// BitcoinPriceArguments defines the structure for arguments used to request Bitcoin price in a specific currency.
+type BitcoinPriceArguments struct {
+	Currency string `json:"currency" jsonschema:"required,description=The currency to get the Bitcoin price in (USD, EUR, GBP, etc)"`
+	Locale   string `json:"locale" jsonschema:"default=en-US,description=The locale to format the price for (en-US, de-DE, etc)"`
+}
+
+// BitcoinPriceJSONArguments defines the structure for arguments used to request the Bitcoin price as JSON.
+type BitcoinPriceJSONArguments struct {
+	Currency string `json:"currency" jsonschema:"required,description=The currency to get the Bitcoin price in (USD, EUR, GBP, etc)"`
+}
+
+// BitcoinPricesArguments defines the structure for arguments used to request the Bitcoin price in several currencies at once.
+type BitcoinPricesArguments struct {
+	Currencies []string `json:"currencies" jsonschema:"required,description=The currencies to get the Bitcoin price in (USD, EUR, GBP, etc)"`
+	Locale     string   `json:"locale" jsonschema:"default=en-US,description=The locale to format the prices for (en-US, de-DE, etc)"`
+}
+
+// CryptoPriceArguments defines the structure for arguments used to request the price of any coin listed on CoinGecko.
+type CryptoPriceArguments struct {
+	CoinID   string `json:"coin_id" jsonschema:"required,description=The CoinGecko id of the coin (bitcoin, ethereum, solana, etc)"`
+	Currency string `json:"currency" jsonschema:"required,description=The currency to get the price in (USD, EUR, GBP, etc)"`
+	Locale   string `json:"locale" jsonschema:"default=en-US,description=The locale to format the price for (en-US, de-DE, etc)"`
+}
+
+// maxBatchCoins caps how many coins crypto_prices fetches in one call, keeping the request URL a sane size.
+const maxBatchCoins = 25
+
+// CryptoPricesArguments defines the structure for arguments used to request the price of several coins at once.
+type CryptoPricesArguments struct {
+	CoinIDs  []string `json:"coin_ids" jsonschema:"required,description=The CoinGecko ids of the coins (bitcoin, ethereum, solana, etc), at most 25"`
+	Currency string   `json:"currency" jsonschema:"required,description=The currency to get the prices in (USD, EUR, GBP, etc)"`
+	Locale   string   `json:"locale" jsonschema:"default=en-US,description=The locale to format the prices for (en-US, de-DE, etc)"`
+}
+
+// BitcoinPriceResult is the structured payload returned by the bitcoin_price_json tool.
+// Stale is set when CoinGecko was unreachable and the price is the last cached value as of Timestamp.
+type BitcoinPriceResult struct {
+	Price     float64   `json:"price" jsonschema:"description=The Bitcoin price in the requested currency"`
+	Currency  string    `json:"currency" jsonschema:"description=The ISO 4217 code of the currency"`
+	Timestamp time.Time `json:"timestamp" jsonschema:"description=When the price was fetched from CoinGecko"`
+	Source    string    `json:"source" jsonschema:"description=Where the price came from: CoinGecko or the fallback CoinCap when fetched for this call or cache when served from the price cache"`
+	Stale     bool      `json:"stale,omitempty" jsonschema:"description=Set when CoinGecko was unavailable and this is the last cached price"`
+	Simulated bool      `json:"simulated,omitempty" jsonschema:"description=Set when the server runs in offline mode and the price is fixture data"`
+}
+
+// priceQuote is a price together with when it was fetched, the source that served it and whether it is a stale
+// cached fallback. Fallback is set when the price was fetched from the fallback source because CoinGecko was unavailable.
+type priceQuote struct {
+	Currency string
+	Price    float64
+	AsOf     time.Time
+	Source   string
+	Stale    bool
+	Fallback bool
+}
+
+// staleNote describes how old a stale quote is, for appending to tool output.
+func (q priceQuote) staleNote() string {
+	return fmt.Sprintf("CoinGecko is currently unavailable, so this is the last known price from %s ago", time.Since(q.AsOf).Round(time.Second))
+}
+
+// fallbackNote names the fallback source a price came from, for appending to tool output.
+func fallbackNote(source string) string {
+	return fmt.Sprintf("CoinGecko is currently unavailable, so this price comes from %s", source)
+}
+
+// lookupBitcoinPrice validates the requested currency, falling back to the default currency, and returns the Bitcoin price from cache
+// when possible, otherwise from client. If client fails, the last cached price is returned marked as stale,
+// provided it is within the cache's max-stale window.
+func lookupBitcoinPrice(ctx context.Context, client *CryptoClient, cache *priceCache, currency string) (priceQuote, error) {
+	// Fall back to the configured default currency if none is specified
+	currency = currencyOrDefault(currency)
+
+	// Reject unknown currencies before making any network request
+	currency, err := NormalizeCurrency(currency)
+	if err != nil {
+		return priceQuote{}, err
+	}
+
+	// Serve from the cache when possible, otherwise call CoinGecko API to get the latest Bitcoin price
+	key := priceCacheKey("bitcoin", strings.ToLower(currency))
+	p, err := cache.Fetch(ctx, key, client.httpClient.Timeout, func(ctx context.Context) (SourcedPrice, error) {
+		return client.BitcoinPrice(ctx, currency)
+	})
+	if err != nil {
+		// A slightly stale price is more useful than an error while CoinGecko is down
+		if stale, fetchedAt, ok := cache.GetStale(key); ok {
+			slog.WarnContext(ctx, "Serving stale Bitcoin price", "currency", currency, "fetched_at", fetchedAt, "error", err)
+			return priceQuote{Currency: currency, Price: stale, AsOf: fetchedAt, Source: sourceCache, Stale: true}, nil
+		}
+		slog.ErrorContext(ctx, "Error fetching Bitcoin price", "currency", currency, "error", err)
+		return priceQuote{}, err
+	}
+	return priceQuote{
+		Currency: currency,
+		Price:    p.Price,
+		AsOf:     time.Now(),
+		Source:   p.Source,
+		Fallback: p.Source != sourceCache && p.Source != client.Name(),
+	}, nil
+}
+
+// bitcoinPriceTool returns the handler for the bitcoin_price tool, serving prices from cache before asking client
+// and rendering them with tmpl.
+func bitcoinPriceTool(client *CryptoClient, cache *priceCache, tmpl *template.Template) func(context.Context, BitcoinPriceArguments) (*mcp_golang.ToolResponse, error) {
+	return func(ctx context.Context, arguments BitcoinPriceArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", "bitcoin_price", "currency", arguments.Currency, "locale", arguments.Locale)
+
+		printer, err := localePrinter(arguments.Locale)
+		if err != nil {
+			return toolFailure("error fetching Bitcoin price", err)
+		}
+		quote, err := lookupBitcoinPrice(ctx, client, cache, arguments.Currency)
+		if err != nil {
+			return toolFailure("error fetching Bitcoin price", err)
+		}
+
+		text, err := renderPrice(tmpl, PriceTemplateData{
+			Price:    formatPrice(printer, quote.Price, quote.Currency),
+			Currency: quote.Currency,
+			Time:     quote.AsOf,
+			Source:   quote.Source,
+		})
+		if err != nil {
+			return toolFailure("error fetching Bitcoin price", err)
+		}
+		if quote.Stale {
+			text += "\nWarning: " + quote.staleNote()
+		}
+		if quote.Fallback {
+			text += "\nNote: " + fallbackNote(quote.Source)
+		}
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(markSimulated(client, text))), nil
+	}
+}
+
+// bitcoinPriceJSONTool returns the handler for the bitcoin_price_json tool, which reports the same price as
+// bitcoin_price as a JSON object for programmatic consumers.
+func bitcoinPriceJSONTool(client *CryptoClient, cache *priceCache) func(context.Context, BitcoinPriceJSONArguments) (*mcp_golang.ToolResponse, error) {
+	return func(ctx context.Context, arguments BitcoinPriceJSONArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", "bitcoin_price_json", "currency", arguments.Currency)
+
+		quote, err := lookupBitcoinPrice(ctx, client, cache, arguments.Currency)
+		if err != nil {
+			return toolFailure("error fetching Bitcoin price", err)
+		}
+
+		return NewJSONToolResponse(BitcoinPriceResult{
+			Price:     quote.Price,
+			Currency:  quote.Currency,
+			Timestamp: quote.AsOf.UTC(),
+			Source:    quote.Source,
+			Stale:     quote.Stale,
+			Simulated: client.Offline(),
+		})
+	}
+}
+
+// bitcoinPricesTool returns the handler for the bitcoin_prices tool, fetching every currency with one client call.
+func bitcoinPricesTool(client *CryptoClient) func(context.Context, BitcoinPricesArguments) (*mcp_golang.ToolResponse, error) {
+	return func(ctx context.Context, arguments BitcoinPricesArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", "bitcoin_prices", "currencies", arguments.Currencies, "locale", arguments.Locale)
+
+		printer, err := localePrinter(arguments.Locale)
+		if err != nil {
+			return toolFailure("error fetching Bitcoin prices", err)
+		}
+
+		// Normalize and deduplicate the requested currencies, noting any we can't handle
+		var currencies, warnings []string
+		seen := make(map[string]bool)
+		for _, c := range arguments.Currencies {
+			currency, err := NormalizeCurrency(c)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("Skipped %q: not a supported currency", c))
+				continue
+			}
+			if seen[currency] {
+				continue
+			}
+			seen[currency] = true
+			currencies = append(currencies, currency)
+		}
+		if len(currencies) == 0 {
+			return toolFailure("error fetching Bitcoin prices", fmt.Errorf("no supported currencies requested, valid codes are: %s", strings.Join(supportedCurrencyList(), ", ")))
+		}
+
+		// Fetch every currency with a single CoinGecko call, falling back to one call per currency if that fails
+		prices, failed, err := fetchBatch(ctx, currencies,
+			func(ctx context.Context) (map[string]float64, error) {
+				return client.CryptoPrices(ctx, "bitcoin", currencies)
+			},
+			func(ctx context.Context, currency string) (float64, error) {
+				return client.CryptoPrice(ctx, "bitcoin", currency)
+			})
+		if err != nil {
+			slog.ErrorContext(ctx, "Error fetching Bitcoin prices", "tool", "bitcoin_prices", "currencies", currencies, "error", err)
+			return toolFailure("error fetching Bitcoin prices", err)
+		}
+
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "Current Bitcoin prices (as of %s):\n", time.Now().Format(time.RFC1123))
+		for _, currency := range currencies {
+			price, ok := prices[currency]
+			if !ok {
+				err, ok := failed[currency]
+				if !ok {
+					err = priceUnavailableError(currency)
+				}
+				warnings = append(warnings, fmt.Sprintf("Skipped %s: %v", currency, err))
+				continue
+			}
+			fmt.Fprintf(&sb, "- %s: %s\n", currency, formatPrice(printer, price, currency))
+		}
+		for _, warning := range warnings {
+			fmt.Fprintf(&sb, "Warning: %s\n", warning)
+		}
+
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(markSimulated(client, sb.String()))), nil
+	}
+}
+
+// cryptoPriceTool returns the handler for the crypto_price tool, fetching prices with client.
+func cryptoPriceTool(client *CryptoClient) func(context.Context, CryptoPriceArguments) (*mcp_golang.ToolResponse, error) {
+	return func(ctx context.Context, arguments CryptoPriceArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", "crypto_price", "coin_id", arguments.CoinID, "currency", arguments.Currency, "locale", arguments.Locale)
+
+		printer, err := localePrinter(arguments.Locale)
+		if err != nil {
+			return toolFailure(fmt.Sprintf("error fetching %s price", arguments.CoinID), err)
+		}
+
+		// Fall back to the configured default currency if none is specified
+		currency, err := NormalizeCurrency(currencyOrDefault(arguments.Currency))
+		if err != nil {
+			return toolFailure(fmt.Sprintf("error fetching %s price", arguments.CoinID), err)
+		}
+
+		// Call CoinGecko API to get the latest price, or the fallback source while it is down
+		price, err := client.QuotePrice(ctx, arguments.CoinID, currency)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error fetching crypto price", "tool", "crypto_price", "coin_id", arguments.CoinID, "currency", currency, "error", err)
+			return toolFailure(fmt.Sprintf("error fetching %s price", arguments.CoinID), err)
+		}
+
+		text := fmt.Sprintf("The current %s price is %s %s (as of %s)",
+			arguments.CoinID,
+			formatPrice(printer, price.Price, currency),
+			currency,
+			time.Now().Format(time.RFC1123))
+		if price.Source != client.Name() {
+			text += "\nNote: " + fallbackNote(price.Source)
+		}
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(markSimulated(client, text))), nil
+	}
+}
+
+// cryptoPricesTool returns the handler for the crypto_prices tool, fetching every coin with one client call where possible.
+func cryptoPricesTool(client *CryptoClient) func(context.Context, CryptoPricesArguments) (*mcp_golang.ToolResponse, error) {
+	return func(ctx context.Context, arguments CryptoPricesArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", "crypto_prices", "coin_ids", arguments.CoinIDs, "currency", arguments.Currency, "locale", arguments.Locale)
+
+		printer, err := localePrinter(arguments.Locale)
+		if err != nil {
+			return toolFailure("error fetching crypto prices", err)
+		}
+
+		// Fall back to the configured default currency if none is specified
+		currency, err := NormalizeCurrency(currencyOrDefault(arguments.Currency))
+		if err != nil {
+			return toolFailure("error fetching crypto prices", err)
+		}
+
+		// Normalize and deduplicate the coin ids, keeping the order they were asked for
+		var coinIDs []string
+		seen := make(map[string]bool)
+		for _, id := range arguments.CoinIDs {
+			id = strings.ToLower(strings.TrimSpace(id))
+			if id == "" || seen[id] {
+				continue
+			}
+			seen[id] = true
+			coinIDs = append(coinIDs, id)
+		}
+		if len(coinIDs) == 0 {
+			return toolFailure("error fetching crypto prices", errors.New("no coin ids requested"))
+		}
+		if len(coinIDs) > maxBatchCoins {
+			return toolFailure("error fetching crypto prices", fmt.Errorf("%d coins requested, at most %d are allowed per call", len(coinIDs), maxBatchCoins))
+		}
+
+		// Fetch every coin with a single CoinGecko call, falling back to one call per coin if that fails
+		prices, failed, err := fetchBatch(ctx, coinIDs,
+			func(ctx context.Context) (map[string]float64, error) {
+				return client.CoinPrices(ctx, coinIDs, currency)
+			},
+			func(ctx context.Context, id string) (float64, error) {
+				return client.CryptoPrice(ctx, id, currency)
+			})
+		if err != nil {
+			slog.ErrorContext(ctx, "Error fetching crypto prices", "tool", "crypto_prices", "coin_ids", coinIDs, "currency", currency, "error", err)
+			return toolFailure("error fetching crypto prices", err)
+		}
+
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "Current prices in %s (as of %s):\n", currency, time.Now().Format(time.RFC1123))
+		var warnings []string
+		for _, id := range coinIDs {
+			price, ok := prices[id]
+			if !ok {
+				if err, ok := failed[id]; ok {
+					warnings = append(warnings, fmt.Sprintf("Skipped %s: %v", id, err))
+				} else {
+					warnings = append(warnings, fmt.Sprintf("Skipped %s: %v", id, ErrCoinNotFound))
+				}
+				continue
+			}
+			fmt.Fprintf(&sb, "- %s: %s\n", id, formatPrice(printer, price, currency))
+		}
+		for _, warning := range warnings {
+			fmt.Fprintf(&sb, "Warning: %s\n", warning)
+		}
+
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(markSimulated(client, sb.String()))), nil
+	}
+}
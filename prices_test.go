@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+func TestBitcoinPriceJSONRoundTrips(t *testing.T) {
+	tool := bitcoinPriceJSONTool(testCryptoClient(cannedJSON(`{"bitcoin":{"usd":50123.45}}`)), newPriceCache(defaultCacheTTL, 0))
+	resp, err := tool(context.Background(), BitcoinPriceJSONArguments{Currency: "usd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := toolText(t, resp)
+
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(text), &fields); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fields["price"].(float64); !ok {
+		t.Errorf("price is encoded as %T, want a JSON number: %s", fields["price"], text)
+	}
+
+	var result BitcoinPriceResult
+	if err := json.Unmarshal([]byte(text), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Price != 50123.45 || result.Currency != "USD" || result.Source != "CoinGecko" || result.Timestamp.IsZero() {
+		t.Errorf("got %+v, want 50123.45 USD from CoinGecko with a timestamp", result)
+	}
+}
+
+func TestBitcoinPriceFailureIsAnError(t *testing.T) {
+	transport := &countingTransport{respond: func(req *http.Request) (*http.Response, error) {
+		return cannedResponse(req, http.StatusInternalServerError, `{"error":"internal"}`), nil
+	}}
+	tmpl, err := parsePriceTemplate("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tool := bitcoinPriceTool(testCryptoClient(transport), newPriceCache(defaultCacheTTL, 0), tmpl)
+	resp, err := tool(context.Background(), BitcoinPriceArguments{Currency: "USD"})
+	// Returning the error is what makes mcp-golang flag the response with isError
+	if err == nil {
+		t.Fatalf("got response %+v and no error, want the failure returned as an error", resp)
+	}
+	if !strings.HasPrefix(err.Error(), "error fetching Bitcoin price: ") {
+		t.Errorf("got error %q, want it to say what failed", err)
+	}
+}
+
+func TestPriceToolsFailTheSameWay(t *testing.T) {
+	down := &countingTransport{respond: func(req *http.Request) (*http.Response, error) {
+		return cannedResponse(req, http.StatusServiceUnavailable, `{"error":"maintenance"}`), nil
+	}}
+	client := testCryptoClient(down)
+	calls := map[string]func() (*mcp_golang.ToolResponse, error){
+		"error fetching Bitcoin price: ": func() (*mcp_golang.ToolResponse, error) {
+			return bitcoinPriceJSONTool(client, newPriceCache(defaultCacheTTL, 0))(context.Background(), BitcoinPriceJSONArguments{Currency: "USD"})
+		},
+		"error fetching Bitcoin prices: ": func() (*mcp_golang.ToolResponse, error) {
+			return bitcoinPricesTool(client)(context.Background(), BitcoinPricesArguments{Currencies: []string{"USD", "EUR"}})
+		},
+		"error fetching ethereum price: ": func() (*mcp_golang.ToolResponse, error) {
+			return cryptoPriceTool(client)(context.Background(), CryptoPriceArguments{CoinID: "ethereum", Currency: "USD"})
+		},
+		"error fetching crypto prices: ": func() (*mcp_golang.ToolResponse, error) {
+			return cryptoPricesTool(client)(context.Background(), CryptoPricesArguments{CoinIDs: []string{"bitcoin", "ethereum"}, Currency: "USD"})
+		},
+	}
+	for prefix, call := range calls {
+		resp, err := call()
+		if err == nil {
+			t.Errorf("%sgot response %+v and no error", prefix, resp)
+			continue
+		}
+		if !strings.HasPrefix(err.Error(), prefix) || !errors.Is(err, ErrUpstreamUnavailable) || !strings.Contains(err.Error(), "try again later") {
+			t.Errorf("got error %q, want it to start with %q and carry the unavailable hint", err, prefix)
+		}
+	}
+
+	// Invalid arguments are errors too
+	if _, err := bitcoinPricesTool(client)(context.Background(), BitcoinPricesArguments{Currencies: []string{"XYZ"}}); err == nil {
+		t.Error("bitcoin_prices with no supported currencies succeeded")
+	}
+	if _, err := cryptoPricesTool(client)(context.Background(), CryptoPricesArguments{Currency: "USD"}); err == nil {
+		t.Error("crypto_prices with no coin ids succeeded")
+	}
+}
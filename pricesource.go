@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultCoinCapBaseURL is the public CoinCap API endpoint used as the fallback price source unless configured otherwise.
+const defaultCoinCapBaseURL = "https://api.coincap.io/v2"
+
+// sourceCache is the source reported for prices served from the price cache rather than fetched for the call.
+const sourceCache = "cache"
+
+// PriceProvider is a source of current coin prices.
+type PriceProvider interface {
+	// Name identifies the source in logs and tool output.
+	Name() string
+	// Price returns the current price of the coin with the given CoinGecko id in currency, an ISO 4217 code.
+	Price(ctx context.Context, coinID, currency string) (float64, error)
+}
+
+// SourcedPrice is a price together with the name of the source that served it and, when that source said,
+// how long it may be cached for.
+type SourcedPrice struct {
+	Price  float64
+	MaxAge time.Duration
+	Source string
+}
+
+// shouldFailOver reports whether err means the primary price source can't serve right now, rather than that it
+// rejected the request, so that asking the fallback source is worthwhile.
+func shouldFailOver(err error) bool {
+	return errors.Is(err, ErrUpstreamUnavailable) || errors.Is(err, ErrRateLimited)
+}
+
+// coinCapAssetResponse represents the parts of the CoinCap assets/{id} response we use. CoinCap encodes
+// numbers as strings.
+type coinCapAssetResponse struct {
+	Data struct {
+		PriceUSD string `json:"priceUsd"`
+	} `json:"data"`
+}
+
+// coinCapRatesResponse represents the parts of the CoinCap rates response we use. RateUSD is the value of one unit
+// of the currency in US dollars.
+type coinCapRatesResponse struct {
+	Data []struct {
+		Symbol  string `json:"symbol"`
+		Type    string `json:"type"`
+		RateUSD string `json:"rateUsd"`
+	} `json:"data"`
+}
+
+// CoinCapClient fetches coin prices from the CoinCap API. It serves as the fallback price source while CoinGecko is down.
+type CoinCapClient struct {
+	apiClient
+}
+
+// NewCoinCapClient creates a CoinCapClient using the public CoinCap endpoint, then applies the given options.
+func NewCoinCapClient(opts ...ClientOption) *CoinCapClient {
+	return &CoinCapClient{
+		apiClient: newAPIClient("CoinCap", defaultCoinCapBaseURL, opts...),
+	}
+}
+
+// Name implements PriceProvider.
+func (c *CoinCapClient) Name() string {
+	return c.name
+}
+
+// Price implements PriceProvider. CoinCap only quotes prices in US dollars, so other currencies are converted with
+// CoinCap's exchange rates, which costs a second request. CoinCap uses the same ids as CoinGecko for the major coins.
+func (c *CoinCapClient) Price(ctx context.Context, coinID, currency string) (float64, error) {
+	coinID = strings.ToLower(coinID)
+	var asset coinCapAssetResponse
+	err := c.getJSON(ctx, "/assets/"+url.PathEscape(coinID), nil, &asset)
+	if isStatus(err, http.StatusNotFound) {
+		return 0, fmt.Errorf("%w: %s", ErrCoinNotFound, coinID)
+	}
+	if err != nil {
+		return 0, err
+	}
+	priceUSD, err := strconv.ParseFloat(asset.Data.PriceUSD, 64)
+	if err != nil {
+		return 0, fmt.Errorf("CoinCap returned an invalid price %q for %s", asset.Data.PriceUSD, coinID)
+	}
+	if strings.EqualFold(currency, "USD") {
+		return priceUSD, nil
+	}
+
+	var rates coinCapRatesResponse
+	if err := c.getJSON(ctx, "/rates", nil, &rates); err != nil {
+		return 0, err
+	}
+	for _, rate := range rates.Data {
+		if rate.Type != "fiat" || !strings.EqualFold(rate.Symbol, currency) {
+			continue
+		}
+		rateUSD, err := strconv.ParseFloat(rate.RateUSD, 64)
+		if err != nil || rateUSD <= 0 {
+			return 0, fmt.Errorf("CoinCap returned an invalid %s exchange rate %q", currency, rate.RateUSD)
+		}
+		return priceUSD / rateUSD, nil
+	}
+	return 0, priceUnavailableError(currency)
+}
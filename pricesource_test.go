@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestQuotePriceFailsOverToCoinCap(t *testing.T) {
+	coinGecko := &countingTransport{respond: func(req *http.Request) (*http.Response, error) {
+		return cannedResponse(req, http.StatusServiceUnavailable, `{"error":"maintenance"}`), nil
+	}}
+	coinCap := &countingTransport{respond: func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case "/v2/assets/bitcoin":
+			return cannedResponse(req, http.StatusOK, `{"data":{"priceUsd":"60000.00"}}`), nil
+		case "/v2/rates":
+			return cannedResponse(req, http.StatusOK, `{"data":[{"symbol":"EUR","type":"fiat","rateUsd":"1.2"}]}`), nil
+		}
+		return cannedResponse(req, http.StatusNotFound, `{"error":"not found"}`), nil
+	}}
+	client := testCryptoClient(coinGecko)
+	client.SetFallback(NewCoinCapClient(WithTransport(coinCap), WithRetries(0), WithRetryBaseDelay(0)))
+
+	price, err := client.QuotePrice(context.Background(), "bitcoin", "EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if price.Price != 50000 || price.Source != "CoinCap" {
+		t.Errorf("got %+v, want 50000 from CoinCap", price)
+	}
+	if coinGecko.requests.Load() == 0 {
+		t.Error("CoinGecko was never asked")
+	}
+
+	// The tool says where the price came from
+	tmpl, err := parsePriceTemplate("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := bitcoinPriceTool(client, newPriceCache(defaultCacheTTL, 0), tmpl)(context.Background(), BitcoinPriceArguments{Currency: "EUR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := toolText(t, resp); !strings.Contains(text, fallbackNote("CoinCap")) {
+		t.Errorf("got %q, want it to note the CoinCap fallback", text)
+	}
+}
+
+func TestQuotePriceDoesNotFailOverOnRejectedRequest(t *testing.T) {
+	coinGecko := &countingTransport{respond: func(req *http.Request) (*http.Response, error) {
+		return cannedResponse(req, http.StatusBadRequest, `{"error":"invalid vs_currency"}`), nil
+	}}
+	coinCap := offlineTransport()
+	client := testCryptoClient(coinGecko)
+	client.SetFallback(NewCoinCapClient(WithTransport(coinCap), WithRetries(0), WithRetryBaseDelay(0)))
+
+	if _, err := client.QuotePrice(context.Background(), "bitcoin", "EUR"); err == nil {
+		t.Fatal("a rejected request succeeded")
+	}
+	if n := coinCap.requests.Load(); n != 0 {
+		t.Errorf("CoinCap was asked %d times, want a rejected request not to fail over", n)
+	}
+}
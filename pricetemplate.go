@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// defaultPriceTemplate renders the bitcoin_price text when -price-template is not set.
+const defaultPriceTemplate = `The current Bitcoin price is {{.Price}} {{.Currency}} (as of {{.Time.Format "Mon, 02 Jan 2006 15:04:05 MST"}})`
+
+// PriceTemplateData holds the fields available to a price template.
+type PriceTemplateData struct {
+	// Price is the price formatted for the caller's locale and the currency's decimals, such as 65,000.00.
+	Price string
+	// Currency is the ISO 4217 code, such as USD.
+	Currency string
+	// Time is when the price was fetched.
+	Time time.Time
+	// Source is where the price came from: CoinGecko, the fallback CoinCap, or cache for a cached price.
+	Source string
+}
+
+// parsePriceTemplate parses a text/template for the bitcoin_price text, using defaultPriceTemplate when text is
+// empty. The template is also rendered once against sample data, so that a reference to an unknown field fails at
+// startup rather than on the first tool call.
+func parsePriceTemplate(text string) (*template.Template, error) {
+	if strings.TrimSpace(text) == "" {
+		text = defaultPriceTemplate
+	}
+	tmpl, err := template.New("price").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid price template: %w", err)
+	}
+	sample := PriceTemplateData{Price: "65,000.00", Currency: fallbackCurrency, Time: time.Now(), Source: "CoinGecko"}
+	if err := tmpl.Execute(io.Discard, sample); err != nil {
+		return nil, fmt.Errorf("invalid price template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// renderPrice renders data with tmpl.
+func renderPrice(tmpl *template.Template, data PriceTemplateData) (string, error) {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("error rendering price template: %w", err)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCustomPriceTemplate(t *testing.T) {
+	tmpl, err := parsePriceTemplate(`1 BTC = {{.Price}} {{.Currency}} via {{.Source}} at {{.Time.Format "2006-01-02"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := renderPrice(tmpl, PriceTemplateData{
+		Price:    "50,123.45",
+		Currency: "EUR",
+		Time:     time.Date(2024, time.March, 1, 9, 30, 0, 0, time.UTC),
+		Source:   "CoinGecko",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "1 BTC = 50,123.45 EUR via CoinGecko at 2024-03-01"; text != want {
+		t.Errorf("got %q, want %q", text, want)
+	}
+
+	tool := bitcoinPriceTool(testCryptoClient(cannedJSON(`{"bitcoin":{"eur":50123.45}}`)), newPriceCache(defaultCacheTTL, 0), tmpl)
+	resp, err := tool(context.Background(), BitcoinPriceArguments{Currency: "EUR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := toolText(t, resp); !strings.HasPrefix(text, "1 BTC = 50,123.45 EUR via CoinGecko at ") {
+		t.Errorf("bitcoin_price answered %q, want the custom template", text)
+	}
+}
+
+func TestPriceTemplateRejectedAtStartup(t *testing.T) {
+	for _, text := range []string{"{{.Price", "{{.Volume}}"} {
+		if _, err := parsePriceTemplate(text); err == nil || !strings.HasPrefix(err.Error(), "invalid price template") {
+			t.Errorf("template %q: got error %v, want it rejected", text, err)
+		}
+	}
+}
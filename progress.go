@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/metoro-io/mcp-golang/transport"
+)
+
+// progressMethod is the MCP notification that reports how far a request has come.
+const progressMethod = "notifications/progress"
+
+// progressKey is the context key under which the progress reporter of the current request is stored.
+type progressKey struct{}
+
+// progressReporter sends progress notifications for one request back over the connection it arrived on.
+type progressReporter struct {
+	token json.RawMessage
+	send  func(context.Context, *transport.BaseJsonRpcMessage) error
+}
+
+// progressParams is the payload of a progress notification.
+type progressParams struct {
+	ProgressToken json.RawMessage `json:"progressToken"`
+	Progress      int             `json:"progress"`
+	Total         int             `json:"total,omitempty"`
+	Message       string          `json:"message,omitempty"`
+}
+
+// contextWithProgress returns a copy of ctx carrying a reporter that delivers progress with send, when message is a
+// tool call whose _meta holds a progressToken. mcp-golang has no progress API of its own, so the transports that
+// can push messages to the client call this before handing a request over; other messages return ctx unchanged.
+func contextWithProgress(ctx context.Context, message *transport.BaseJsonRpcMessage, send func(context.Context, *transport.BaseJsonRpcMessage) error) context.Context {
+	if message.Type != transport.BaseMessageTypeJSONRPCRequestType || message.JsonRpcRequest.Method != "tools/call" {
+		return ctx
+	}
+	var params struct {
+		Meta struct {
+			ProgressToken json.RawMessage `json:"progressToken"`
+		} `json:"_meta"`
+	}
+	if err := json.Unmarshal(message.JsonRpcRequest.Params, &params); err != nil {
+		return ctx
+	}
+	if token := params.Meta.ProgressToken; len(token) > 0 && string(token) != "null" {
+		return context.WithValue(ctx, progressKey{}, &progressReporter{token: token, send: send})
+	}
+	return ctx
+}
+
+// toolProgress streams the progress of one tool call that was asked to stream. Progress goes out as notifications
+// when the transport and client support them. Otherwise, as on the HTTP transport, the final content is sent in
+// chunks of one line each instead. A call that wasn't asked to stream reports nothing and responds as usual.
+type toolProgress struct {
+	stream   bool
+	reporter *progressReporter
+}
+
+// newToolProgress returns the progress of the tool call in ctx; stream is the call's stream argument.
+func newToolProgress(ctx context.Context, stream bool) *toolProgress {
+	reporter, _ := ctx.Value(progressKey{}).(*progressReporter)
+	return &toolProgress{stream: stream, reporter: reporter}
+}
+
+// Report sends a progress notification saying done of total steps are finished. It is safe for concurrent use;
+// failing to deliver a notification is logged and otherwise ignored, since the call itself can still succeed.
+func (p *toolProgress) Report(ctx context.Context, done, total int, message string) {
+	if !p.stream || p.reporter == nil {
+		return
+	}
+	params, err := json.Marshal(progressParams{ProgressToken: p.reporter.token, Progress: done, Total: total, Message: message})
+	if err != nil {
+		slog.WarnContext(ctx, "Error encoding progress notification", "error", err)
+		return
+	}
+	notification := transport.NewBaseMessageNotification(&transport.BaseJSONRPCNotification{
+		Jsonrpc: "2.0",
+		Method:  progressMethod,
+		Params:  params,
+	})
+	if err := p.reporter.send(ctx, notification); err != nil {
+		slog.WarnContext(ctx, "Error sending progress notification", "error", err)
+	}
+}
+
+// Respond returns the tool response carrying text: as one content item, or as one item per line when the call
+// asked to stream but its progress could not be sent as notifications.
+func (p *toolProgress) Respond(text string) *mcp_golang.ToolResponse {
+	if !p.stream || p.reporter != nil {
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(text))
+	}
+	var contents []*mcp_golang.Content
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if line != "" {
+			contents = append(contents, mcp_golang.NewTextContent(line))
+		}
+	}
+	return mcp_golang.NewToolResponse(contents...)
+}
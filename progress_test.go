@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/metoro-io/mcp-golang/transport"
+)
+
+// progressRecorder collects the progress notifications sent for a tool call.
+type progressRecorder struct {
+	mu     sync.Mutex
+	events []progressParams
+}
+
+// send implements the send function of a progressReporter.
+func (r *progressRecorder) send(_ context.Context, message *transport.BaseJsonRpcMessage) error {
+	var params progressParams
+	if err := json.Unmarshal(message.JsonRpcNotification.Params, &params); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if message.JsonRpcNotification.Method == progressMethod {
+		r.events = append(r.events, params)
+	}
+	return nil
+}
+
+// toolCall returns a tools/call request whose _meta holds token, or no _meta at all when token is empty.
+func toolCall(token string) *transport.BaseJsonRpcMessage {
+	params := `{"name":"portfolio_value","arguments":{}}`
+	if token != "" {
+		params = `{"name":"portfolio_value","arguments":{},"_meta":{"progressToken":` + token + `}}`
+	}
+	return transport.NewBaseMessageRequest(&transport.BaseJSONRPCRequest{Id: 1, Jsonrpc: "2.0", Method: "tools/call", Params: json.RawMessage(params)})
+}
+
+func TestPortfolioValueReportsProgress(t *testing.T) {
+	recorder := &progressRecorder{}
+	ctx := contextWithProgress(context.Background(), toolCall(`"portfolio-1"`), recorder.send)
+	tool := portfolioValueTool(testCryptoClient(cannedJSON(`{"bitcoin":{"usd":40000},"ethereum":{"usd":2000}}`)))
+	args := PortfolioValueArguments{Holdings: []Holding{{CoinID: "bitcoin", Amount: 1}, {CoinID: "ethereum", Amount: 1}}, Currency: "USD", Stream: true}
+	resp, err := tool(ctx, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []progressParams{
+		{ProgressToken: json.RawMessage(`"portfolio-1"`), Progress: 0, Total: 2, Message: "fetching prices for 2 coins"},
+		{ProgressToken: json.RawMessage(`"portfolio-1"`), Progress: 2, Total: 2, Message: "fetched 2/2 coins"},
+	}
+	equal := func(a, b progressParams) bool {
+		return string(a.ProgressToken) == string(b.ProgressToken) && a.Progress == b.Progress && a.Total == b.Total && a.Message == b.Message
+	}
+	if !slices.EqualFunc(recorder.events, want, equal) {
+		t.Errorf("got progress %+v, want %+v", recorder.events, want)
+	}
+	// With notifications delivered, the result comes in one piece
+	if len(resp.Content) != 1 {
+		t.Errorf("got %d content items, want 1", len(resp.Content))
+	}
+
+	// Without the stream argument nothing is reported
+	recorder.events = nil
+	args.Stream = false
+	if _, err := tool(ctx, args); err != nil {
+		t.Fatal(err)
+	}
+	if len(recorder.events) != 0 {
+		t.Errorf("got progress %+v for a call that didn't ask to stream", recorder.events)
+	}
+}
+
+func TestStreamWithoutProgressTokenChunksContent(t *testing.T) {
+	recorder := &progressRecorder{}
+	ctx := contextWithProgress(context.Background(), toolCall(""), recorder.send)
+	tool := portfolioValueTool(testCryptoClient(cannedJSON(`{"bitcoin":{"usd":40000},"ethereum":{"usd":2000}}`)))
+	resp, err := tool(ctx, PortfolioValueArguments{Holdings: []Holding{{CoinID: "bitcoin", Amount: 1}, {CoinID: "ethereum", Amount: 1}}, Currency: "USD", Stream: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recorder.events) != 0 {
+		t.Errorf("got progress %+v without a progress token", recorder.events)
+	}
+	// A heading, two holdings and the total, one line each
+	if len(resp.Content) != 4 {
+		t.Errorf("got %d content items, want one per line:\n%s", len(resp.Content), toolText(t, resp))
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// MarketSummaryArguments defines the arguments of the market_summary prompt.
+// Prompt arguments must be strings, so the currency is optional text rather than an enum.
+type MarketSummaryArguments struct {
+	Currency string `json:"currency" jsonschema:"description=The currency to quote the Bitcoin price in (USD, EUR, GBP, etc), defaults to the server default currency"`
+}
+
+// marketSummaryPrompt returns the handler for the market_summary prompt, which embeds the live Bitcoin price
+// in a request for a short market summary. When the price can't be fetched the prompt says so instead of failing.
+func marketSummaryPrompt(client *CryptoClient, cache *priceCache) func(MarketSummaryArguments) (*mcp_golang.PromptResponse, error) {
+	return func(arguments MarketSummaryArguments) (*mcp_golang.PromptResponse, error) {
+		slog.Debug("Received prompt request", "prompt", "market_summary", "currency", arguments.Currency)
+
+		// Prompt handlers get no request context, so the client's own timeout bounds the fetch
+		quote, err := lookupBitcoinPrice(context.Background(), client, cache, arguments.Currency)
+
+		var text string
+		if err != nil {
+			slog.Warn("Market data unavailable for prompt", "prompt", "market_summary", "currency", arguments.Currency, "error", err)
+			text = fmt.Sprintf("Live Bitcoin market data is currently unavailable (%v). "+
+				"Write a short Bitcoin market summary, stating clearly that no current price could be retrieved, "+
+				"and avoid quoting specific figures.", err)
+		} else {
+			text = fmt.Sprintf("The current Bitcoin price is %s %s (as of %s). ",
+				formatAmount(quote.Price, quote.Currency),
+				quote.Currency,
+				quote.AsOf.Format(time.RFC1123))
+			if quote.Stale {
+				text += quote.staleNote() + "; mention that the figure may be out of date. "
+			}
+			if client.Offline() {
+				text += "The figure is simulated offline fixture data; say so in the summary. "
+			}
+			text += "Using this figure, write a short Bitcoin market summary for a general audience."
+		}
+
+		return mcp_golang.NewPromptResponse("Bitcoin market summary", mcp_golang.NewPromptMessage(mcp_golang.NewTextContent(text), mcp_golang.RoleUser)), nil
+	}
+}
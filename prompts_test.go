@@ -0,0 +1,30 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPromptTestRequiresTitle(t *testing.T) {
+	resp, err := promptTest(Content{Title: "World"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := resp.Messages[0].Content.TextContent.Text; text != "Hello, World!" {
+		t.Errorf("got %q, want Hello, World!", text)
+	}
+
+	for _, title := range []string{"", "   "} {
+		resp, err := promptTest(Content{Title: title})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Messages) != 1 {
+			t.Fatalf("title %q: got %d messages, want one", title, len(resp.Messages))
+		}
+		text := resp.Messages[0].Content.TextContent.Text
+		if !strings.Contains(text, "could not be built: missing required field: title") || strings.Contains(text, "Hello") {
+			t.Errorf("title %q: got %q, want the missing title explained instead of a greeting", title, text)
+		}
+	}
+}
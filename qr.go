@@ -0,0 +1,509 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// The qr tool encodes its data in byte mode with error correction level M, in the smallest of versions 1 to 10
+// it fits. Version 10 is 57 modules across, about as large as still renders legibly in a terminal.
+const (
+	qrMaxVersion = 10
+	qrQuietZone  = 4
+)
+
+// qrBlockLayout describes how the codewords of one QR version at level M are split into Reed-Solomon blocks:
+// count1 blocks of data1 data codewords followed by count2 blocks of one codeword more, each with ecPerBlock
+// error correction codewords.
+type qrBlockLayout struct {
+	ecPerBlock     int
+	count1, data1  int
+	count2         int
+	alignPositions []int
+}
+
+// qrLayouts lists the block structure and alignment pattern positions of versions 1 to qrMaxVersion at level M,
+// from ISO/IEC 18004 tables 9 and E.1, indexed by version.
+var qrLayouts = [qrMaxVersion + 1]qrBlockLayout{
+	1:  {10, 1, 16, 0, nil},
+	2:  {16, 1, 28, 0, []int{6, 18}},
+	3:  {26, 1, 44, 0, []int{6, 22}},
+	4:  {18, 2, 32, 0, []int{6, 26}},
+	5:  {24, 2, 43, 0, []int{6, 30}},
+	6:  {16, 4, 27, 0, []int{6, 34}},
+	7:  {18, 4, 31, 0, []int{6, 22, 38}},
+	8:  {22, 2, 38, 2, []int{6, 24, 42}},
+	9:  {22, 3, 36, 2, []int{6, 26, 46}},
+	10: {26, 4, 43, 1, []int{6, 28, 50}},
+}
+
+// dataCodewords returns how many data codewords the version holds.
+func (l qrBlockLayout) dataCodewords() int {
+	return l.count1*l.data1 + l.count2*(l.data1+1)
+}
+
+// qrCharCountBits returns the width of the byte mode character count field in the version.
+func qrCharCountBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+// qrCapacity returns how many bytes of data fit in the version in byte mode.
+func qrCapacity(version int) int {
+	return (qrLayouts[version].dataCodewords()*8 - 4 - qrCharCountBits(version)) / 8
+}
+
+// maxQRDataLength is the most bytes the qr tool encodes.
+var maxQRDataLength = qrCapacity(qrMaxVersion)
+
+// qrCode is an encoded QR symbol; modules[y][x] is true for a dark module.
+type qrCode struct {
+	version int
+	size    int
+	modules [][]bool
+	// function marks the finder, timing, alignment, format and version modules, which masking leaves alone
+	function [][]bool
+}
+
+// encodeQR encodes data as a QR code of the smallest version that holds it, at error correction level M.
+func encodeQR(data []byte) (*qrCode, error) {
+	version := 1
+	for version <= qrMaxVersion && len(data) > qrCapacity(version) {
+		version++
+	}
+	if version > qrMaxVersion {
+		return nil, fmt.Errorf("data is %d bytes, but at most %d fit in a version %d QR code", len(data), maxQRDataLength, qrMaxVersion)
+	}
+
+	q := newQRCode(version)
+	q.drawFunctionPatterns()
+	q.drawCodewords(qrCodewords(version, data))
+
+	// Pick the mask leaving the fewest patterns that confuse scanners, as the standard asks
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		q.applyMask(mask)
+		q.drawFormatBits(mask)
+		if p := q.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+		q.applyMask(mask) // masking is its own inverse
+	}
+	q.applyMask(best)
+	q.drawFormatBits(best)
+	return q, nil
+}
+
+// newQRCode returns a blank symbol of the version.
+func newQRCode(version int) *qrCode {
+	size := version*4 + 17
+	q := &qrCode{version: version, size: size, modules: make([][]bool, size), function: make([][]bool, size)}
+	for y := range q.modules {
+		q.modules[y] = make([]bool, size)
+		q.function[y] = make([]bool, size)
+	}
+	return q
+}
+
+// setFunction sets the function module at column x and row y.
+func (q *qrCode) setFunction(x, y int, dark bool) {
+	q.modules[y][x] = dark
+	q.function[y][x] = true
+}
+
+// drawFunctionPatterns draws the timing patterns, the finder patterns with their separators, the alignment
+// patterns and the version information, and reserves the format information area.
+func (q *qrCode) drawFunctionPatterns() {
+	for i := 0; i < q.size; i++ {
+		q.setFunction(6, i, i%2 == 0)
+		q.setFunction(i, 6, i%2 == 0)
+	}
+
+	for _, c := range [][2]int{{3, 3}, {q.size - 4, 3}, {3, q.size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := c[0]+dx, c[1]+dy
+				if x < 0 || x >= q.size || y < 0 || y >= q.size {
+					continue
+				}
+				dist := max(abs(dx), abs(dy))
+				q.setFunction(x, y, dist != 2 && dist != 4)
+			}
+		}
+	}
+
+	pos := qrLayouts[q.version].alignPositions
+	last := len(pos) - 1
+	for i, y := range pos {
+		for j, x := range pos {
+			// The corners taken by the finder patterns get no alignment pattern
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					q.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	// Reserve the format information area until the mask is chosen
+	q.drawFormatBits(0)
+	q.drawVersionBits()
+}
+
+// abs returns the absolute value of n.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// drawFormatBits draws both copies of the format information for level M and mask, and the dark module.
+func (q *qrCode) drawFormatBits(mask int) {
+	// Level M is 00, so the 5 data bits are just the mask, protected by a BCH(15,5) code
+	data := mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return bits>>i&1 == 1 }
+
+	// First copy, around the top left finder pattern
+	for i := 0; i <= 5; i++ {
+		q.setFunction(8, i, bit(i))
+	}
+	q.setFunction(8, 7, bit(6))
+	q.setFunction(8, 8, bit(7))
+	q.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		q.setFunction(14-i, 8, bit(i))
+	}
+
+	// Second copy, split between the top right and bottom left finder patterns
+	for i := 0; i < 8; i++ {
+		q.setFunction(q.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		q.setFunction(8, q.size-15+i, bit(i))
+	}
+	q.setFunction(8, q.size-8, true)
+}
+
+// drawVersionBits draws both copies of the version information, which versions 7 and up carry.
+func (q *qrCode) drawVersionBits() {
+	if q.version < 7 {
+		return
+	}
+	rem := q.version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1f25)
+	}
+	bits := q.version<<12 | rem
+	for i := 0; i < 18; i++ {
+		dark := bits>>i&1 == 1
+		a, b := q.size-11+i%3, i/3
+		q.setFunction(a, b, dark)
+		q.setFunction(b, a, dark)
+	}
+}
+
+// qrCodewords returns the final codeword sequence for data in the version: the data in byte mode, padded to
+// the version's capacity, split into blocks with their error correction codewords and interleaved.
+func qrCodewords(version int, data []byte) []byte {
+	layout := qrLayouts[version]
+
+	var bits qrBitBuffer
+	bits.append(0b0100, 4) // byte mode
+	bits.append(len(data), qrCharCountBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	capacity := layout.dataCodewords() * 8
+	bits.append(0, min(4, capacity-len(bits))) // terminator
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0xec; len(bits) < capacity; pad ^= 0xec ^ 0x11 {
+		bits.append(pad, 8)
+	}
+	codewords := bits.bytes()
+
+	divisor := reedSolomonDivisor(layout.ecPerBlock)
+	var blocks, ecBlocks [][]byte
+	for i, offset := 0, 0; i < layout.count1+layout.count2; i++ {
+		n := layout.data1
+		if i >= layout.count1 {
+			n++
+		}
+		block := codewords[offset : offset+n]
+		offset += n
+		blocks = append(blocks, block)
+		ecBlocks = append(ecBlocks, reedSolomonRemainder(block, divisor))
+	}
+
+	var out []byte
+	for i := 0; i <= layout.data1; i++ {
+		for _, block := range blocks {
+			if i < len(block) {
+				out = append(out, block[i])
+			}
+		}
+	}
+	for i := 0; i < layout.ecPerBlock; i++ {
+		for _, ec := range ecBlocks {
+			out = append(out, ec[i])
+		}
+	}
+	return out
+}
+
+// qrBitBuffer is a sequence of bits, most significant first.
+type qrBitBuffer []bool
+
+// append adds the n low bits of v.
+func (b *qrBitBuffer) append(v, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, v>>i&1 == 1)
+	}
+}
+
+// bytes packs the bits, whose count must be a multiple of 8, into bytes.
+func (b qrBitBuffer) bytes() []byte {
+	out := make([]byte, len(b)/8)
+	for i, bit := range b {
+		if bit {
+			out[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return out
+}
+
+// gfMultiply multiplies two elements of GF(2^8) modulo the QR code polynomial x^8 + x^4 + x^3 + x^2 + 1.
+func gfMultiply(x, y byte) byte {
+	var z int
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11d)
+		z ^= int(y>>i&1) * int(x)
+	}
+	return byte(z)
+}
+
+// reedSolomonDivisor returns the coefficients of the Reed-Solomon generator polynomial of the given degree,
+// highest first and without the leading 1.
+func reedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+// reedSolomonRemainder returns the error correction codewords of data for the generator polynomial divisor.
+func reedSolomonRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, d := range divisor {
+			result[i] ^= gfMultiply(d, factor)
+		}
+	}
+	return result
+}
+
+// drawCodewords places the codewords in the zigzag order of the standard: two-module-wide columns from right
+// to left, alternately upwards and downwards, skipping the function modules and the vertical timing pattern.
+// Modules left over at the end are the remainder bits, which stay light.
+func (q *qrCode) drawCodewords(codewords []byte) {
+	i := 0
+	for right := q.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < q.size; vert++ {
+			y := vert
+			if upward {
+				y = q.size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if q.function[y][x] || i >= len(codewords)*8 {
+					continue
+				}
+				q.modules[y][x] = codewords[i/8]>>(7-i%8)&1 == 1
+				i++
+			}
+		}
+	}
+}
+
+// applyMask flips the data modules selected by the mask pattern.
+func (q *qrCode) applyMask(mask int) {
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			if q.function[y][x] {
+				continue
+			}
+			var flip bool
+			switch mask {
+			case 0:
+				flip = (x+y)%2 == 0
+			case 1:
+				flip = y%2 == 0
+			case 2:
+				flip = x%3 == 0
+			case 3:
+				flip = (x+y)%3 == 0
+			case 4:
+				flip = (x/3+y/2)%2 == 0
+			case 5:
+				flip = x*y%2+x*y%3 == 0
+			case 6:
+				flip = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				flip = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if flip {
+				q.modules[y][x] = !q.modules[y][x]
+			}
+		}
+	}
+}
+
+// qrFinderLike are the module sequences penalized for looking like part of a finder pattern.
+var qrFinderLike = [][]bool{
+	{true, false, true, true, true, false, true, false, false, false, false},
+	{false, false, false, false, true, false, true, true, true, false, true},
+}
+
+// penalty scores the symbol with the four mask evaluation rules of the standard; lower is better.
+func (q *qrCode) penalty() int {
+	at := func(x, y int, vertical bool) bool {
+		if vertical {
+			return q.modules[x][y]
+		}
+		return q.modules[y][x]
+	}
+
+	score := 0
+	for _, vertical := range []bool{false, true} {
+		for y := 0; y < q.size; y++ {
+			// Rule 1: runs of five or more modules of one color
+			run := 1
+			for x := 1; x <= q.size; x++ {
+				if x < q.size && at(x, y, vertical) == at(x-1, y, vertical) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					score += 3 + run - 5
+				}
+				run = 1
+			}
+			// Rule 3: finder-like patterns with four light modules on one side
+			for x := 0; x+11 <= q.size; x++ {
+				for _, pattern := range qrFinderLike {
+					matches := true
+					for k, dark := range pattern {
+						if at(x+k, y, vertical) != dark {
+							matches = false
+							break
+						}
+					}
+					if matches {
+						score += 40
+					}
+				}
+			}
+		}
+	}
+
+	// Rule 2: 2x2 blocks of one color; rule 4: deviation of the dark share from half
+	dark := 0
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			if q.modules[y][x] {
+				dark++
+			}
+			if x+1 < q.size && y+1 < q.size {
+				c := q.modules[y][x]
+				if q.modules[y][x+1] == c && q.modules[y+1][x] == c && q.modules[y+1][x+1] == c {
+					score += 3
+				}
+			}
+		}
+	}
+	total := q.size * q.size
+	score += ((abs(dark*20-total*10)+total-1)/total - 1) * 10
+	return score
+}
+
+// render draws the symbol with its quiet zone in Unicode half blocks, two module rows per line, so it keeps its
+// proportions in a terminal. Dark modules are drawn as blocks, which suits dark text on a light background;
+// invert swaps that for light text on a dark background.
+func (q *qrCode) render(invert bool) string {
+	dark := func(x, y int) bool {
+		x, y = x-qrQuietZone, y-qrQuietZone
+		inside := x >= 0 && x < q.size && y >= 0 && y < q.size && q.modules[y][x]
+		return inside != invert
+	}
+
+	var sb strings.Builder
+	full := q.size + 2*qrQuietZone
+	for y := 0; y < full; y += 2 {
+		for x := 0; x < full; x++ {
+			top, bottom := dark(x, y), dark(x, y+1)
+			switch {
+			case top && bottom:
+				sb.WriteString("█")
+			case top:
+				sb.WriteString("▀")
+			case bottom:
+				sb.WriteString("▄")
+			default:
+				sb.WriteString(" ")
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// QRArguments defines the structure for arguments used to render a QR code.
+type QRArguments struct {
+	Data   string `json:"data" jsonschema:"required,description=The text to encode such as a bitcoin: payment URI; at most 213 bytes"`
+	Invert bool   `json:"invert" jsonschema:"description=Draw light modules as blocks instead of dark ones for terminals with light text on a dark background"`
+}
+
+// qrTool handles the qr tool.
+func qrTool(ctx context.Context, arguments QRArguments) (*mcp_golang.ToolResponse, error) {
+	slog.DebugContext(ctx, "Received tool request", "tool", "qr", "length", len(arguments.Data), "invert", arguments.Invert)
+
+	if arguments.Data == "" {
+		return toolFailure("error rendering QR code", errors.New("data is required"))
+	}
+	q, err := encodeQR([]byte(arguments.Data))
+	if err != nil {
+		return toolFailure("error rendering QR code", err)
+	}
+	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(q.render(arguments.Invert))), nil
+}
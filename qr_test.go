@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// qrGolden is the symbol for qrGoldenData at version 3-M with mask 2, one row per line with # for dark modules.
+// It was checked against an independent implementation of ISO/IEC 18004.
+const qrGolden = `
+	#######...#....######.#######
+	#.....#..#.#..##.##.#.#.....#
+	#.###.#.#.##..###..##.#.###.#
+	#.###.#.#..#..#..#....#.###.#
+	#.###.#.#.....###.###.#.###.#
+	#.....#.#.#...###.....#.....#
+	#######.#.#.#.#.#.#.#.#######
+	........##..#.....###........
+	#.#####...#.#####..#..#####..
+	#...#..#....#.#..#.##.###..##
+	..###.#####...###.#....####..
+	#...##.####....##.####..##.#.
+	##.#.##..#...####..#......#..
+	###.##.##.###.#...#.#..####.#
+	#.#####.####...#....##.#.##..
+	.#...#....##.......#..##.#...
+	...#..#...#..###.#.#......###
+	#.......#...###.#.#.##.##.###
+	#.#...#...###..#......#..#...
+	#...#.....#.#.#.#...##..##.##
+	#..#.##.#.###.####..#####.##.
+	........#.#.#.#..####...#####
+	#######..#..#.####.##.#.#.#..
+	#.....#.#####...#..##...#..##
+	#.###.#.####...###..#########
+	#.###.#.#.####....#.....#.###
+	#.###.#.##.##..#.##.#..##.##.
+	#.....#..###..#...#..#.##..#.
+	#######.###..####..#.##.###..
+`
+
+// qrGoldenData is a bitcoin: payment URI of 42 bytes, the most a version 3-M symbol holds.
+const qrGoldenData = "bitcoin:1BoatSLRHtKNngkdXEeobR76b53LETtpyT"
+
+// qrRows returns the modules of q as rows of # and . characters.
+func qrRows(q *qrCode) []string {
+	rows := make([]string, q.size)
+	for y, row := range q.modules {
+		var sb strings.Builder
+		for _, dark := range row {
+			if dark {
+				sb.WriteByte('#')
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+		rows[y] = sb.String()
+	}
+	return rows
+}
+
+func TestEncodeQRMatchesGolden(t *testing.T) {
+	q, err := encodeQR([]byte(qrGoldenData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.version != 3 {
+		t.Errorf("got version %d, want 3", q.version)
+	}
+	want := strings.Fields(qrGolden)
+	got := qrRows(q)
+	if len(got) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(got), len(want))
+	}
+	for y := range want {
+		if got[y] != want[y] {
+			t.Errorf("row %d:\n got %s\nwant %s", y, got[y], want[y])
+		}
+	}
+}
+
+func TestQRRenderDrawsTwoRowsPerLine(t *testing.T) {
+	q, err := encodeQR([]byte(qrGoldenData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks := map[[2]bool]rune{{false, false}: ' ', {true, false}: '▀', {false, true}: '▄', {true, true}: '█'}
+	for _, invert := range []bool{false, true} {
+		lines := strings.Split(strings.TrimSuffix(q.render(invert), "\n"), "\n")
+		full := q.size + 2*qrQuietZone
+		if len(lines) != (full+1)/2 {
+			t.Fatalf("invert %v: got %d lines, want %d", invert, len(lines), (full+1)/2)
+		}
+		dark := func(x, y int) bool {
+			x, y = x-qrQuietZone, y-qrQuietZone
+			return (x >= 0 && x < q.size && y >= 0 && y < q.size && q.modules[y][x]) != invert
+		}
+		for i, line := range lines {
+			runes := []rune(line)
+			if len(runes) != full {
+				t.Fatalf("invert %v: line %d is %d wide, want %d", invert, i, len(runes), full)
+			}
+			for x, r := range runes {
+				if want := blocks[[2]bool{dark(x, 2*i), dark(x, 2*i+1)}]; r != want {
+					t.Fatalf("invert %v: line %d column %d is %q, want %q", invert, i, x, r, want)
+				}
+			}
+		}
+	}
+}
+
+func TestEncodeQRPicksSmallestVersion(t *testing.T) {
+	tests := []struct {
+		length  int
+		version int
+	}{
+		{1, 1},
+		{14, 1},
+		{15, 2},
+		{42, 3},
+		{43, 4},
+		{maxQRDataLength, qrMaxVersion},
+	}
+	for _, tt := range tests {
+		q, err := encodeQR([]byte(strings.Repeat("a", tt.length)))
+		if err != nil {
+			t.Errorf("%d bytes: %v", tt.length, err)
+			continue
+		}
+		if q.version != tt.version || q.size != tt.version*4+17 {
+			t.Errorf("%d bytes: got version %d of size %d, want version %d", tt.length, q.version, q.size, tt.version)
+		}
+	}
+}
+
+func TestQRToolRejectsTooMuchData(t *testing.T) {
+	if maxQRDataLength != 213 {
+		t.Errorf("maxQRDataLength is %d, but the tool description promises 213", maxQRDataLength)
+	}
+	_, err := qrTool(context.Background(), QRArguments{Data: strings.Repeat("a", maxQRDataLength+1)})
+	if err == nil || !strings.HasPrefix(err.Error(), "error rendering QR code: data is 214 bytes, but at most 213 fit") {
+		t.Errorf("got error %v, want the data rejected as too large", err)
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"reflect"
+	"slices"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// ToolInfo describes a registered tool.
+type ToolInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// registry lists every tool registered through registerTool, in registration order.
+var registry []ToolInfo
+
+// registeredPrompts and registeredResources list the names of the prompts registered through registerPrompt and
+// the URIs of the resources registered through registerResource, in registration order.
+var registeredPrompts, registeredResources []string
+
+// enabledTools and disabledTools filter the tools registerTool registers, from -enable and -disable; registerAll
+// sets them before registering anything. When enabledTools is not nil only the tools it lists are registered,
+// otherwise every tool but those in disabledTools is. The two flags are mutually exclusive.
+var enabledTools, disabledTools map[string]bool
+
+// experimentalTools are tools still settling in, which are only registered when experimentalEnabled is set
+// from MCP_EXPERIMENTAL or -experimental, so stable deployments don't expose them by default.
+var experimentalTools = map[string]bool{
+	"dca_simulate":    true,
+	"portfolio_value": true,
+}
+
+// experimentalEnabled turns on the experimental tools; registerAll sets it before registering anything.
+var experimentalEnabled bool
+
+// gatedTools lists the experimental tools registerTool skipped because experimental tools are off, in registration order.
+var gatedTools []string
+
+// featureEnabled reports whether the named tool may be registered as far as the experimental gate goes: stable
+// tools always may, experimental ones only when experimental tools are turned on.
+func featureEnabled(name string) bool {
+	return !experimentalTools[name] || experimentalEnabled
+}
+
+// offlineMode leaves out the onlineOnlyTools; registerAll sets it from -offline before registering anything.
+var offlineMode bool
+
+// offlineTools lists the tools registerTool skipped because they need the network while offlineMode is set, in
+// registration order.
+var offlineTools []string
+
+// skippedTools lists the tools registerTool was asked to register but skipped because of the filter, in registration order.
+var skippedTools []string
+
+// toolSet turns a list of tool names into a set, returning nil for an empty list.
+func toolSet(names []string) map[string]bool {
+	if len(names) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
+
+// toolEnabled reports whether the named tool passes the -enable or -disable filter.
+func toolEnabled(name string) bool {
+	if enabledTools != nil {
+		return enabledTools[name]
+	}
+	return !disabledTools[name]
+}
+
+// ListToolsArguments defines the (empty) arguments of the list_tools tool.
+type ListToolsArguments struct{}
+
+// registerTool wraps handler in the standard middleware chain plus mws, registers it with the server and records it in the registry.
+// Registering a name twice is an error rather than silently replacing the earlier tool. Experimental tools, while
+// they are off, tools that need the network in offline mode, and tools filtered out with -enable or -disable are skipped.
+func registerTool[T any](server *mcp_golang.Server, name, description string, handler func(context.Context, T) (*mcp_golang.ToolResponse, error), mws ...Middleware) error {
+	if !featureEnabled(name) {
+		gatedTools = append(gatedTools, name)
+		return nil
+	}
+	if offlineMode && onlineOnlyTools[name] {
+		offlineTools = append(offlineTools, name)
+		return nil
+	}
+	if !toolEnabled(name) {
+		skippedTools = append(skippedTools, name)
+		return nil
+	}
+	if server.CheckToolRegistered(name) {
+		return fmt.Errorf("registering tool %s: a tool with this name is already registered", name)
+	}
+	err := server.RegisterTool(name, description, wrapTool(name, handler, mws...))
+	if err != nil {
+		return fmt.Errorf("registering tool %s: %w", name, err)
+	}
+	registry = append(registry, ToolInfo{Name: name, Description: description})
+	if acceptsEnvelope(reflect.TypeFor[T]()) {
+		envelopeTools[name] = true
+	}
+	return nil
+}
+
+// reportFilteredTools logs the experimental tools registered or left out, the tools left out in offline mode and the
+// tools skipped by the -enable or -disable filter, and warns about listed names that no registration asked for, which
+// are most likely typos.
+func reportFilteredTools() {
+	if experimentalEnabled {
+		var enabled []string
+		for _, tool := range registry {
+			if experimentalTools[tool.Name] {
+				enabled = append(enabled, tool.Name)
+			}
+		}
+		slog.Info("Experimental tools enabled", "tools", enabled)
+	} else if len(gatedTools) > 0 {
+		slog.Info("Experimental tools not registered, set MCP_EXPERIMENTAL=1 to enable them", "tools", gatedTools)
+	}
+	if len(offlineTools) > 0 {
+		slog.Info("Tools not registered in offline mode, they have no fixture data", "tools", offlineTools)
+	}
+	if len(skippedTools) > 0 {
+		slog.Info("Disabled tools", "tools", skippedTools)
+	}
+
+	// An -enable name is known once it is registered, a -disable name once it is skipped
+	flag, listed, seen := "-disable", disabledTools, toolSet(skippedTools)
+	if enabledTools != nil {
+		flag, listed, seen = "-enable", enabledTools, make(map[string]bool, len(registry))
+		for _, tool := range registry {
+			seen[tool.Name] = true
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(listed)) {
+		if slices.Contains(gatedTools, name) {
+			slog.Warn("Ignoring experimental tool in "+flag+" while experimental tools are off", "tool", name)
+			continue
+		}
+		if !seen[name] {
+			slog.Warn("Ignoring unknown tool in "+flag, "tool", name)
+		}
+	}
+}
+
+// logCapabilities logs what the server exposes over transport: the names and counts of its registered tools,
+// prompts and resources, so operators can confirm at a glance what clients will see.
+func logCapabilities(transport string) {
+	tools := make([]string, len(registry))
+	for i, tool := range registry {
+		tools[i] = tool.Name
+	}
+	slog.Info("Registered capabilities",
+		"transport", transport,
+		slog.Group("tools", "count", len(tools), "names", tools),
+		slog.Group("prompts", "count", len(registeredPrompts), "names", registeredPrompts),
+		slog.Group("resources", "count", len(registeredResources), "uris", registeredResources))
+}
+
+// listTools handles the list_tools tool, returning every registered tool as JSON.
+func listTools(ctx context.Context, _ ListToolsArguments) (*mcp_golang.ToolResponse, error) {
+	slog.DebugContext(ctx, "Received tool request", "tool", "list_tools")
+
+	data, err := json.MarshalIndent(registry, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(string(data))), nil
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRegistryListsTools(t *testing.T) {
+	server, err := registerTestServer(t, defaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := registeredNames()
+	for _, name := range []string{"hello", "bitcoin_price", "list_tools"} {
+		if !slices.Contains(names, name) {
+			t.Errorf("registry is missing %s: %v", name, names)
+		}
+		if !server.CheckToolRegistered(name) {
+			t.Errorf("%s is in the registry but not registered with the server", name)
+		}
+	}
+
+	resp, err := listTools(context.Background(), ListToolsArguments{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var listed []ToolInfo
+	if err := json.Unmarshal([]byte(toolText(t, resp)), &listed); err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != len(registry) || listed[len(listed)-1].Name != "list_tools" {
+		t.Errorf("list_tools returned %d tools ending in %v, want all %d ending in list_tools", len(listed), listed[len(listed)-1], len(registry))
+	}
+}
+
+func TestDisabledToolsAreNotRegistered(t *testing.T) {
+	logs := captureLogs(t)
+	cfg := defaultConfig()
+	cfg.DisabledTools = []string{"bitcoin_price", "no_such_tool"}
+	server, err := registerTestServer(t, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := registeredNames()
+	if slices.Contains(names, "bitcoin_price") || server.CheckToolRegistered("bitcoin_price") {
+		t.Errorf("disabled bitcoin_price is registered: %v", names)
+	}
+	for _, name := range []string{"hello", "crypto_price", "list_tools"} {
+		if !slices.Contains(names, name) {
+			t.Errorf("registry is missing %s: %v", name, names)
+		}
+	}
+
+	text := strings.Join(logs.Lines(), "\n")
+	if !strings.Contains(text, `"msg":"Disabled tools"`) || !strings.Contains(text, `"bitcoin_price"`) {
+		t.Errorf("disabled tools were not logged:\n%s", text)
+	}
+	if !strings.Contains(text, `"msg":"Ignoring unknown tool in -disable","tool":"no_such_tool"`) {
+		t.Errorf("unknown tool was not warned about:\n%s", text)
+	}
+}
+
+func TestEnabledToolsAreTheOnlyOnesRegistered(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.EnabledTools = []string{"hello", "bitcoin_price"}
+	server, err := registerTestServer(t, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := registeredNames()
+	if !slices.Equal(names, []string{"hello", "bitcoin_price"}) {
+		t.Errorf("got %v, want only hello and bitcoin_price", names)
+	}
+	if server.CheckToolRegistered("crypto_price") {
+		t.Error("crypto_price is registered with the server but not in the allowlist")
+	}
+}
+
+func TestLogCapabilitiesListsRegistrations(t *testing.T) {
+	if _, err := registerTestServer(t, defaultConfig()); err != nil {
+		t.Fatal(err)
+	}
+	logs := captureLogs(t)
+	logCapabilities("stdio")
+
+	lines := logs.Lines()
+	if len(lines) != 1 {
+		t.Fatalf("got %d log lines, want one summary: %v", len(lines), lines)
+	}
+	var summary struct {
+		Msg       string `json:"msg"`
+		Transport string `json:"transport"`
+		Tools     struct {
+			Count int      `json:"count"`
+			Names []string `json:"names"`
+		} `json:"tools"`
+		Prompts struct {
+			Count int `json:"count"`
+		} `json:"prompts"`
+		Resources struct {
+			Count int      `json:"count"`
+			URIs  []string `json:"uris"`
+		} `json:"resources"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &summary); err != nil {
+		t.Fatal(err)
+	}
+	if summary.Msg != "Registered capabilities" || summary.Transport != "stdio" {
+		t.Errorf("got message %q for transport %q, want the capabilities summary for stdio", summary.Msg, summary.Transport)
+	}
+	for _, name := range []string{"hello", "bitcoin_price"} {
+		if !slices.Contains(summary.Tools.Names, name) {
+			t.Errorf("summary is missing %s: %v", name, summary.Tools.Names)
+		}
+	}
+	if summary.Tools.Count != len(registry) || summary.Prompts.Count != len(registeredPrompts) || summary.Resources.Count != len(registeredResources) {
+		t.Errorf("got counts %d/%d/%d, want %d/%d/%d from the registries", summary.Tools.Count, summary.Prompts.Count, summary.Resources.Count, len(registry), len(registeredPrompts), len(registeredResources))
+	}
+	if !slices.Contains(summary.Resources.URIs, "test://resource") {
+		t.Errorf("summary is missing test://resource: %v", summary.Resources.URIs)
+	}
+}
+
+func TestExperimentalToolsFollowTheEnvironment(t *testing.T) {
+	experimental := []string{"dca_simulate", "portfolio_value"}
+	for _, value := range []string{"", "0", "1", "true"} {
+		t.Setenv("MCP_EXPERIMENTAL", value)
+		cfg, err := loadConfig(nil)
+		if err != nil {
+			t.Fatalf("MCP_EXPERIMENTAL=%q: %v", value, err)
+		}
+		logs := captureLogs(t)
+		if _, err := registerTestServer(t, cfg); err != nil {
+			t.Fatal(err)
+		}
+
+		wantRegistered := value == "1" || value == "true"
+		names := registeredNames()
+		for _, name := range experimental {
+			if slices.Contains(names, name) != wantRegistered {
+				t.Errorf("MCP_EXPERIMENTAL=%q: %s registered is %v, want %v", value, name, !wantRegistered, wantRegistered)
+			}
+		}
+		if !slices.Contains(names, "bitcoin_price") {
+			t.Errorf("MCP_EXPERIMENTAL=%q: stable bitcoin_price is missing", value)
+		}
+		text := strings.Join(logs.Lines(), "\n")
+		if got := strings.Contains(text, `"msg":"Experimental tools enabled"`); got != wantRegistered {
+			t.Errorf("MCP_EXPERIMENTAL=%q: enabled tools logged is %v, want %v", value, got, wantRegistered)
+		}
+	}
+
+	t.Setenv("MCP_EXPERIMENTAL", "maybe")
+	if _, err := loadConfig(nil); err == nil {
+		t.Error("an invalid MCP_EXPERIMENTAL was accepted")
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// requestIDKey is the context key under which the current tool call's request ID is stored.
+type requestIDKey struct{}
+
+// requestIDHeaders are the HTTP headers checked, in order, for a caller-supplied request ID.
+var requestIDHeaders = []string{"X-Request-ID", "X-Correlation-ID"}
+
+// ginContextKey is the key mcp-golang's Gin transport stores the *gin.Context of the HTTP request under.
+const ginContextKey = "ginContext"
+
+// contextWithRequestID returns a copy of ctx carrying id as the request ID.
+func contextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// requestIDFromContext returns the request ID stored in ctx, or "" if there is none.
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// newRequestID generates a short random request ID.
+func newRequestID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "00000000"
+	}
+	return hex.EncodeToString(b)
+}
+
+// transportRequestID returns the request ID carried by the transport, if any.
+// Only the HTTP transport has one, in an X-Request-ID or X-Correlation-ID header; stdio messages carry none.
+func transportRequestID(ctx context.Context) string {
+	c, ok := ctx.Value(ginContextKey).(*gin.Context)
+	if !ok {
+		return ""
+	}
+	for _, header := range requestIDHeaders {
+		if id := c.GetHeader(header); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// assignRequestID stores a request ID in the context of every invocation so that all of its log lines can be
+// correlated, reusing the one supplied by the transport and otherwise generating a new one.
+func assignRequestID(next ToolHandler) ToolHandler {
+	return func(ctx context.Context, arguments any) (*mcp_golang.ToolResponse, error) {
+		id := transportRequestID(ctx)
+		if id == "" {
+			id = newRequestID()
+		}
+		return next(contextWithRequestID(ctx, id), arguments)
+	}
+}
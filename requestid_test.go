@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+func TestConcurrentCallsLogDistinctRequestIDs(t *testing.T) {
+	logs := captureLogs(t)
+
+	// Both calls are held until the other has started, so they really run at the same time
+	var started sync.WaitGroup
+	started.Add(2)
+	tool := wrapTool("slow", func(ctx context.Context, arguments ListToolsArguments) (*mcp_golang.ToolResponse, error) {
+		started.Done()
+		started.Wait()
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent("done")), nil
+	})
+	var calls sync.WaitGroup
+	for range 2 {
+		calls.Add(1)
+		go func() {
+			defer calls.Done()
+			if _, err := tool(context.Background(), ListToolsArguments{}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	calls.Wait()
+
+	ids := map[string]bool{}
+	for _, line := range logs.Lines() {
+		var record struct {
+			Msg       string `json:"msg"`
+			RequestID string `json:"request_id"`
+		}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("log line %q: %v", line, err)
+		}
+		if record.Msg == "Tool call completed" {
+			if record.RequestID == "" {
+				t.Errorf("log line has no request ID: %s", line)
+			}
+			ids[record.RequestID] = true
+		}
+	}
+	if len(ids) != 2 {
+		t.Errorf("got request IDs %v in the logs, want 2 distinct ones", ids)
+	}
+}
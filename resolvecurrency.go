@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// ResolveCurrencyArguments defines the structure for arguments used to look up the code of a currency.
+type ResolveCurrencyArguments struct {
+	Term string `json:"term" jsonschema:"required,maxLength=64,description=A currency code or name or symbol ($, dollars, €, euro, yen, etc)"`
+}
+
+// resolveCurrencyTool handles the resolve_currency tool, mapping a currency name or symbol to its ISO 4217 code
+// the way the price tools do, or listing the candidates when the term is ambiguous.
+func resolveCurrencyTool(ctx context.Context, arguments ResolveCurrencyArguments) (*mcp_golang.ToolResponse, error) {
+	slog.DebugContext(ctx, "Received tool request", "tool", "resolve_currency", "term", arguments.Term)
+	if err := validateRequired(arguments); err != nil {
+		return nil, err
+	}
+
+	var text string
+	switch codes := resolveCurrency(arguments.Term); len(codes) {
+	case 0:
+		text = fmt.Sprintf("No supported currency matches %q; valid codes are: %s", arguments.Term, strings.Join(supportedCurrencyList(), ", "))
+	case 1:
+		text = fmt.Sprintf("%q resolves to %s", arguments.Term, codes[0])
+	default:
+		text = fmt.Sprintf("%q is ambiguous; it could mean %s", arguments.Term, strings.Join(codes, ", "))
+	}
+	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(text)), nil
+}
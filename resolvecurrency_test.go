@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestResolveCurrencyTool(t *testing.T) {
+	tests := []struct {
+		term string
+		want string
+	}{
+		{"€", `"€" resolves to EUR`},
+		{"£", `"£" resolves to GBP`},
+		{"euros", `"euros" resolves to EUR`},
+		{"Yen", `"Yen" resolves to JPY`},
+		{"dollar", `"dollar" is ambiguous; it could mean `},
+		{"¥", `"¥" is ambiguous; it could mean `},
+		{"doubloons", `No supported currency matches "doubloons"; valid codes are: `},
+	}
+	for _, tt := range tests {
+		resp, err := resolveCurrencyTool(context.Background(), ResolveCurrencyArguments{Term: tt.term})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if text := toolText(t, resp); !strings.HasPrefix(text, tt.want) {
+			t.Errorf("term %q: got %q, want %q", tt.term, text, tt.want)
+		}
+	}
+}
+
+func TestCryptoPriceResolvesCurrencyNames(t *testing.T) {
+	transport := cannedJSON(`{"ethereum":{"eur":3200}}`)
+	tool := cryptoPriceTool(testCryptoClient(transport))
+	resp, err := tool(context.Background(), CryptoPriceArguments{CoinID: "ethereum", Currency: "euro"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := toolText(t, resp); !strings.HasPrefix(text, "The current ethereum price is 3,200.00 EUR") {
+		t.Errorf("got %q, want the price in EUR", text)
+	}
+
+	if _, err := tool(context.Background(), CryptoPriceArguments{CoinID: "ethereum", Currency: "doubloons"}); !errors.Is(err, ErrUnsupportedCurrency) {
+		t.Errorf("got error %v, want the currency rejected", err)
+	}
+	if n := transport.requests.Load(); n != 1 {
+		t.Errorf("made %d requests, want only the one for the EUR price", n)
+	}
+	if _, err := NormalizeCurrency("dollars"); !errors.Is(err, ErrUnsupportedCurrency) {
+		t.Errorf("got %v for an ambiguous name, want ErrUnsupportedCurrency", err)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// Resource is a readable MCP resource whose content is produced on every read.
+type Resource struct {
+	URI         string
+	Name        string
+	Description string
+	MimeType    string
+	// Read returns the current content of the resource as text.
+	Read func() (string, error)
+}
+
+// ResourceStore holds resources keyed by URI and dispatches reads to them, safe for concurrent use.
+type ResourceStore struct {
+	mu        sync.RWMutex
+	resources map[string]Resource
+}
+
+// NewResourceStore creates an empty ResourceStore.
+func NewResourceStore() *ResourceStore {
+	return &ResourceStore{resources: make(map[string]Resource)}
+}
+
+// Add stores r under its URI. Adding a URI twice is an error.
+func (s *ResourceStore) Add(r Resource) error {
+	if r.URI == "" || r.Read == nil {
+		return fmt.Errorf("resource %q needs a URI and a Read function", r.Name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.resources[r.URI]; ok {
+		return fmt.Errorf("resource %s is already registered", r.URI)
+	}
+	s.resources[r.URI] = r
+	return nil
+}
+
+// List returns every resource in the store ordered by URI.
+func (s *ResourceStore) List() []Resource {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := make([]Resource, 0, len(s.resources))
+	for _, r := range s.resources {
+		list = append(list, r)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].URI < list[j].URI
+	})
+	return list
+}
+
+// Read reads the resource stored under uri.
+func (s *ResourceStore) Read(uri string) (*mcp_golang.ResourceResponse, error) {
+	s.mu.RLock()
+	r, ok := s.resources[uri]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown resource: %s", uri)
+	}
+
+	slog.Debug("Received resource request", "uri", uri)
+	text, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading resource %s: %w", uri, err)
+	}
+	// Never hand out a body that contradicts the declared content type
+	if r.MimeType == "application/json" && !json.Valid([]byte(text)) {
+		return nil, fmt.Errorf("reading resource %s: content is not valid JSON", uri)
+	}
+	return mcp_golang.NewResourceResponse(mcp_golang.NewTextEmbeddedResource(uri, text, r.MimeType)), nil
+}
+
+// registerResources registers every resource in store with the server, each dispatching its reads through the store.
+func registerResources(server *mcp_golang.Server, store *ResourceStore) error {
+	var errs []error
+	for _, r := range store.List() {
+		uri := r.URI
+		err := registerResource(server, uri, r.Name, r.Description, r.MimeType, func() (*mcp_golang.ResourceResponse, error) {
+			return store.Read(uri)
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// jsonResource returns a Read function that encodes the value returned by fn as indented JSON.
+func jsonResource(fn func() any) func() (string, error) {
+	return func() (string, error) {
+		data, err := json.MarshalIndent(fn(), "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	mcphttp "github.com/metoro-io/mcp-golang/transport/http"
+)
+
+func TestResourceStoreDispatchesReads(t *testing.T) {
+	store := NewResourceStore()
+	for _, r := range []Resource{
+		{URI: "test://one", Name: "one", MimeType: "text/plain", Read: func() (string, error) { return "first", nil }},
+		{URI: "test://two", Name: "two", MimeType: "application/json", Read: func() (string, error) { return `{"n":2}`, nil }},
+	} {
+		if err := store.Add(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Add(Resource{URI: "test://one", Name: "again", Read: func() (string, error) { return "", nil }}); err == nil {
+		t.Error("adding a URI twice was accepted")
+	}
+
+	for uri, want := range map[string]string{"test://one": "first", "test://two": `{"n":2}`} {
+		resp, err := store.Read(uri)
+		if err != nil {
+			t.Fatalf("%s: %v", uri, err)
+		}
+		if got := resourceText(t, resp); got != want {
+			t.Errorf("%s: got %q, want %q", uri, got, want)
+		}
+	}
+	if _, err := store.Read("test://three"); err == nil {
+		t.Error("reading an unknown URI gave no error")
+	}
+
+	resetRegistration(t)
+	server := mcp_golang.NewServer(mcphttp.NewGinTransport())
+	if err := registerResources(server, store); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(registeredResources, []string{"test://one", "test://two"}) {
+		t.Errorf("registered %v, want both URIs", registeredResources)
+	}
+}
+
+func TestTestResourceVariantsMatchTheirTypes(t *testing.T) {
+	text, err := jsonResource(testResource)()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(text), &body); err != nil {
+		t.Fatalf("test://resource is not JSON: %v\n%s", err, text)
+	}
+	if body.Message != testResourceMessage {
+		t.Errorf("got message %q, want %q", body.Message, testResourceMessage)
+	}
+
+	text, err = testResourceText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != testResourceMessage || json.Valid([]byte(text)) {
+		t.Errorf("test://resource/text is %q, want the plain message", text)
+	}
+}
+
+func TestRegisterResourceValidatesURI(t *testing.T) {
+	resetRegistration(t)
+	server := mcp_golang.NewServer(mcphttp.NewGinTransport())
+	read := func() (*mcp_golang.ResourceResponse, error) {
+		return mcp_golang.NewResourceResponse(mcp_golang.NewTextEmbeddedResource("custom://thing", "thing", "text/plain")), nil
+	}
+
+	if err := registerResource(server, "custom+scheme://thing/1", "thing", "A thing", "text/plain", read); err != nil {
+		t.Errorf("valid custom scheme URI rejected: %v", err)
+	}
+	for _, uri := range []string{"no scheme at all", "://missing", "%zz://bad-escape", ""} {
+		if err := registerResource(server, uri, "bad", "A bad URI", "text/plain", read); err == nil {
+			t.Errorf("malformed URI %q was registered", uri)
+		}
+	}
+	if !slices.Equal(registeredResources, []string{"custom+scheme://thing/1"}) {
+		t.Errorf("registered %v, want only the valid URI", registeredResources)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/invopop/jsonschema"
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// outputSchemaReflector builds output schemas the same way the library builds input schemas.
+var outputSchemaReflector = jsonschema.Reflector{
+	Anonymous:      true,
+	DoNotReference: true,
+	ExpandedStruct: true,
+}
+
+// NewJSONToolResponse marshals v into a tool response whose only content is the JSON encoding of v, as text content.
+// An embedded application/json resource would carry the content type, but it needs a made-up URI and clients that
+// only read text content would show nothing, so the content type is stated in the description instead: register
+// the tool with withOutputSchema so clients know to parse the text and which fields to expect.
+func NewJSONToolResponse(v any) (*mcp_golang.ToolResponse, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding tool result: %w", err)
+	}
+	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(string(data))), nil
+}
+
+// toolFailure reports a failed tool call. Returning the error from the handler makes mcp-golang flag the response
+// with isError, so clients can tell it from a successful result; the message is "action: err" plus any hint.
+func toolFailure(action string, err error) (*mcp_golang.ToolResponse, error) {
+	return nil, fmt.Errorf("%s: %w%s", action, err, errorHint(err))
+}
+
+// withOutputSchema appends the JSON Schema of the result type R to a tool description.
+// mcp-golang v0.8.0 has no outputSchema field for tools, so the description is the only place clients can find it.
+func withOutputSchema[R any](description string) string {
+	schema, err := json.Marshal(outputSchemaReflector.ReflectFromType(reflect.TypeFor[R]()))
+	if err != nil {
+		// Schemas are derived from static types, so this only fails on a programming error
+		panic(fmt.Sprintf("building output schema: %v", err))
+	}
+	return fmt.Sprintf("%s. Returns application/json matching this schema: %s", description, schema)
+}
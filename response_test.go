@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewJSONToolResponseRoundTrips(t *testing.T) {
+	want := BitcoinPriceResult{
+		Price:     50123.45,
+		Currency:  "EUR",
+		Timestamp: time.Date(2024, time.March, 1, 9, 30, 0, 0, time.UTC),
+		Source:    "CoinGecko",
+		Stale:     true,
+	}
+	resp, err := NewJSONToolResponse(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got BitcoinPriceResult
+	if err := json.Unmarshal([]byte(toolText(t, resp)), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestWithOutputSchemaDescribesResult(t *testing.T) {
+	description := withOutputSchema[BitcoinPriceResult]("Get the price")
+	prefix := "Get the price. Returns application/json matching this schema: "
+	if !strings.HasPrefix(description, prefix) {
+		t.Fatalf("got %q, want it to start with %q", description, prefix)
+	}
+
+	var schema struct {
+		Type       string                     `json:"type"`
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(description, prefix)), &schema); err != nil {
+		t.Fatal(err)
+	}
+	if schema.Type != "object" {
+		t.Errorf("schema type is %q, want object", schema.Type)
+	}
+	for _, field := range []string{"price", "currency", "timestamp", "source", "stale"} {
+		if _, ok := schema.Properties[field]; !ok {
+			t.Errorf("schema has no %s property", field)
+		}
+	}
+}
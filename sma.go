@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// BitcoinSMAArguments defines the structure for arguments used to request a simple moving average of the Bitcoin price.
+type BitcoinSMAArguments struct {
+	Currency string `json:"currency" jsonschema:"required,description=The currency to get the Bitcoin price in (USD, EUR, GBP, etc)"`
+	Days     int    `json:"days" jsonschema:"required,description=How many days of price history to fetch, from 1 to 365"`
+	Window   int    `json:"window" jsonschema:"required,description=How many daily closes to average; at most days"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting numeric arguments given as numeric strings too.
+func (a *BitcoinSMAArguments) UnmarshalJSON(data []byte) error {
+	type plain BitcoinSMAArguments
+	return decodeArguments(data, (*plain)(a))
+}
+
+// simpleMovingAverage returns the mean of the last window values.
+func simpleMovingAverage(values []float64, window int) (float64, error) {
+	if window < 1 {
+		return 0, fmt.Errorf("window must be at least 1, got %d", window)
+	}
+	if len(values) < window {
+		return 0, fmt.Errorf("only %d daily closes are available, fewer than the window of %d", len(values), window)
+	}
+	var sum float64
+	for _, v := range values[len(values)-window:] {
+		sum += v
+	}
+	return sum / float64(window), nil
+}
+
+// bitcoinSMATool returns the handler for the bitcoin_sma tool, fetching the price history with client.
+func bitcoinSMATool(client *CryptoClient) func(context.Context, BitcoinSMAArguments) (*mcp_golang.ToolResponse, error) {
+	return func(ctx context.Context, arguments BitcoinSMAArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", "bitcoin_sma", "currency", arguments.Currency, "days", arguments.Days, "window", arguments.Window)
+
+		// Fall back to the configured default currency if none is specified
+		currency, err := NormalizeCurrency(currencyOrDefault(arguments.Currency))
+		if err != nil {
+			return toolFailure("error computing Bitcoin moving average", err)
+		}
+		if arguments.Days < 1 || arguments.Days > maxMarketChartDays {
+			return toolFailure("error computing Bitcoin moving average", fmt.Errorf("days must be between 1 and %d, got %d", maxMarketChartDays, arguments.Days))
+		}
+		if arguments.Window < 1 || arguments.Window > arguments.Days {
+			return toolFailure("error computing Bitcoin moving average", fmt.Errorf("window must be between 1 and days (%d), got %d", arguments.Days, arguments.Window))
+		}
+
+		points, err := client.MarketChart(ctx, "bitcoin", currency, arguments.Days)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error fetching Bitcoin market chart", "tool", "bitcoin_sma", "currency", currency, "days", arguments.Days, "error", err)
+			return toolFailure("error computing Bitcoin moving average", err)
+		}
+		if len(points) == 0 {
+			return toolFailure("error computing Bitcoin moving average", fmt.Errorf("no Bitcoin price data in %s is available for the last %d day(s)", currency, arguments.Days))
+		}
+
+		closes := dailyCloses(points)
+		values := make([]float64, len(closes))
+		for i, p := range closes {
+			values[i] = p.Price
+		}
+		sma, err := simpleMovingAverage(values, arguments.Window)
+		if err != nil {
+			return toolFailure("error computing Bitcoin moving average", err)
+		}
+
+		current := points[len(points)-1]
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("The %d-day simple moving average of Bitcoin is %s %s; the current price is %s %s (%+.2f%% against the average, as of %s)",
+			arguments.Window,
+			formatAmount(sma, currency),
+			currency,
+			formatAmount(current.Price, currency),
+			currency,
+			(current.Price-sma)/sma*100,
+			current.Time.Format(isoDateLayout)))), nil
+	}
+}
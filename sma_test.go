@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSimpleMovingAverage(t *testing.T) {
+	values := []float64{10, 20, 30, 40, 50}
+	for window, want := range map[int]float64{1: 50, 3: 40, 5: 30} {
+		got, err := simpleMovingAverage(values, window)
+		if err != nil {
+			t.Fatalf("window %d: %v", window, err)
+		}
+		if got != want {
+			t.Errorf("window %d: got %v, want %v", window, got, want)
+		}
+	}
+	for _, window := range []int{0, 6} {
+		if _, err := simpleMovingAverage(values, window); err == nil {
+			t.Errorf("window %d was accepted for %d values", window, len(values))
+		}
+	}
+}
+
+func TestDailyClosesKeepsLastSampleOfEachDay(t *testing.T) {
+	day := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	points := []PricePoint{
+		{Time: day.Add(1 * time.Hour), Price: 1},
+		{Time: day.Add(23 * time.Hour), Price: 2},
+		{Time: day.Add(25 * time.Hour), Price: 3},
+		{Time: day.Add(30 * time.Hour), Price: 4},
+		{Time: day.Add(50 * time.Hour), Price: 5},
+	}
+	closes := dailyCloses(points)
+	var got []float64
+	for _, p := range closes {
+		got = append(got, p.Price)
+	}
+	if len(got) != 3 || got[0] != 2 || got[1] != 4 || got[2] != 5 {
+		t.Errorf("got closes %v, want [2 4 5]", got)
+	}
+}
+
+func TestBitcoinSMATool(t *testing.T) {
+	tool := bitcoinSMATool(testCryptoClient(cannedJSON(cannedMarketChart)))
+	resp, err := tool(context.Background(), BitcoinSMAArguments{Currency: "USD", Days: 3, Window: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The closes are 40000, 44000 and 42000 once sorted, so the last two average 43000
+	want := "The 2-day simple moving average of Bitcoin is 43000.00 USD; the current price is 42000.00 USD (-2.33% against the average"
+	if text := toolText(t, resp); !strings.HasPrefix(text, want) {
+		t.Errorf("got %q, want it to start with %q", text, want)
+	}
+
+	if _, err := tool(context.Background(), BitcoinSMAArguments{Currency: "USD", Days: 3, Window: 4}); err == nil || !strings.Contains(err.Error(), "window must be between 1 and days (3), got 4") {
+		t.Errorf("got error %v, want a window longer than days rejected", err)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// sparklineWidth is how many characters wide a sparkline is; longer series are downsampled to fit.
+const sparklineWidth = 32
+
+// sparklineBlocks are the block characters used for the eight sparkline levels, lowest first.
+var sparklineBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// BitcoinSparklineArguments defines the structure for arguments used to request a sparkline of recent Bitcoin prices.
+type BitcoinSparklineArguments struct {
+	Currency string `json:"currency" jsonschema:"required,description=The currency to get the Bitcoin price in (USD, EUR, GBP, etc)"`
+	Days     int    `json:"days" jsonschema:"required,description=How many days of history to draw, from 1 to 365"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting numeric arguments given as numeric strings too.
+func (a *BitcoinSparklineArguments) UnmarshalJSON(data []byte) error {
+	type plain BitcoinSparklineArguments
+	return decodeArguments(data, (*plain)(a))
+}
+
+// downsample reduces values to at most width points by averaging consecutive buckets of roughly equal size.
+// Series that already fit are returned unchanged.
+func downsample(values []float64, width int) []float64 {
+	if len(values) <= width {
+		return values
+	}
+	out := make([]float64, width)
+	for i := range out {
+		start := i * len(values) / width
+		end := (i + 1) * len(values) / width
+		var sum float64
+		for _, v := range values[start:end] {
+			sum += v
+		}
+		out[i] = sum / float64(end-start)
+	}
+	return out
+}
+
+// sparkline renders values as a line of block characters scaled between their minimum and maximum.
+// A flat series is drawn at the middle level.
+func sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+
+	var sb strings.Builder
+	top := len(sparklineBlocks) - 1
+	for _, v := range values {
+		level := top / 2
+		if hi > lo {
+			level = int(math.Round((v - lo) / (hi - lo) * float64(top)))
+		}
+		sb.WriteRune(sparklineBlocks[level])
+	}
+	return sb.String()
+}
+
+// bitcoinSparklineTool returns the handler for the bitcoin_sparkline tool, fetching the price history with client.
+func bitcoinSparklineTool(client *CryptoClient) func(context.Context, BitcoinSparklineArguments) (*mcp_golang.ToolResponse, error) {
+	return func(ctx context.Context, arguments BitcoinSparklineArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", "bitcoin_sparkline", "currency", arguments.Currency, "days", arguments.Days)
+
+		// Fall back to the configured default currency if none is specified
+		currency, err := NormalizeCurrency(currencyOrDefault(arguments.Currency))
+		if err != nil {
+			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Error drawing Bitcoin sparkline: %v", err))), nil
+		}
+		if arguments.Days < 1 || arguments.Days > maxMarketChartDays {
+			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Error drawing Bitcoin sparkline: days must be between 1 and %d, got %d", maxMarketChartDays, arguments.Days))), nil
+		}
+
+		points, err := client.MarketChart(ctx, "bitcoin", currency, arguments.Days)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error fetching Bitcoin market chart", "tool", "bitcoin_sparkline", "currency", currency, "days", arguments.Days, "error", err)
+			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Error drawing Bitcoin sparkline: %s", describeError(err)))), nil
+		}
+		if len(points) == 0 {
+			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("No Bitcoin price data in %s is available for the last %d day(s)", currency, arguments.Days))), nil
+		}
+
+		values := make([]float64, len(points))
+		lo, hi := points[0].Price, points[0].Price
+		for i, p := range points {
+			values[i] = p.Price
+			lo = math.Min(lo, p.Price)
+			hi = math.Max(hi, p.Price)
+		}
+
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Bitcoin in %s over the last %d day(s):\n%s\nmin %s, max %s, last %s",
+			currency,
+			arguments.Days,
+			sparkline(downsample(values, sparklineWidth)),
+			formatAmount(lo, currency),
+			formatAmount(hi, currency),
+			formatAmount(values[len(values)-1], currency)))), nil
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDownsample(t *testing.T) {
+	if got := downsample([]float64{1, 2, 3, 4, 5, 6}, 3); !slices.Equal(got, []float64{1.5, 3.5, 5.5}) {
+		t.Errorf("got %v, want the averages of each pair", got)
+	}
+	if got := downsample([]float64{1, 2, 3, 4, 5, 6, 7}, 3); !slices.Equal(got, []float64{1.5, 3.5, 6}) {
+		t.Errorf("got %v, want averages of uneven buckets", got)
+	}
+	short := []float64{3, 1, 2}
+	if got := downsample(short, 5); !slices.Equal(got, short) {
+		t.Errorf("got %v, want a series that fits returned unchanged", got)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		values []float64
+		want   string
+	}{
+		{[]float64{0, 1, 2, 3, 4, 5, 6, 7}, "▁▂▃▄▅▆▇█"},
+		{[]float64{100, 200, 100}, "▁█▁"},
+		{[]float64{5, 5, 5}, "▄▄▄"},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := sparkline(tt.values); got != tt.want {
+			t.Errorf("sparkline(%v) = %q, want %q", tt.values, got, tt.want)
+		}
+	}
+}
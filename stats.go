@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// callCounters counts tool calls in total and per tool, safe for concurrent use.
+// Counters are created once per tool under the lock and then incremented atomically, so the lock is only
+// contended the first time a tool is called.
+type callCounters struct {
+	total   atomic.Int64
+	mu      sync.RWMutex
+	perTool map[string]*atomic.Int64
+}
+
+// newCallCounters creates an empty set of call counters.
+func newCallCounters() *callCounters {
+	return &callCounters{perTool: make(map[string]*atomic.Int64)}
+}
+
+// calls counts every tool call; it is incremented by WithLogging.
+var calls = newCallCounters()
+
+// Inc records one call of the named tool.
+func (c *callCounters) Inc(tool string) {
+	c.total.Add(1)
+
+	c.mu.RLock()
+	counter, ok := c.perTool[tool]
+	c.mu.RUnlock()
+	if !ok {
+		c.mu.Lock()
+		if counter, ok = c.perTool[tool]; !ok {
+			counter = new(atomic.Int64)
+			c.perTool[tool] = counter
+		}
+		c.mu.Unlock()
+	}
+	counter.Add(1)
+}
+
+// Snapshot returns the total number of calls and a copy of the per-tool counts.
+func (c *callCounters) Snapshot() (int64, map[string]int64) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	perTool := make(map[string]int64, len(c.perTool))
+	for tool, counter := range c.perTool {
+		perTool[tool] = counter.Load()
+	}
+	return c.total.Load(), perTool
+}
+
+// StatsArguments defines the (empty) arguments of the stats tool.
+type StatsArguments struct{}
+
+// Stats is the report returned by the stats tool.
+type Stats struct {
+	TotalCalls int64            `json:"total_calls" jsonschema:"description=Tool calls since the server started"`
+	Calls      map[string]int64 `json:"calls" jsonschema:"description=Tool calls by tool name"`
+	Uptime     string           `json:"uptime" jsonschema:"description=How long the server has been running"`
+}
+
+// statsTool handles the stats tool, reporting call counts and uptime as JSON.
+// The stats call itself is counted before the handler runs, so it is included.
+func statsTool(ctx context.Context, _ StatsArguments) (*mcp_golang.ToolResponse, error) {
+	slog.DebugContext(ctx, "Received tool request", "tool", "stats")
+
+	total, perTool := calls.Snapshot()
+	return NewJSONToolResponse(Stats{
+		TotalCalls: total,
+		Calls:      perTool,
+		Uptime:     uptime().String(),
+	})
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestCallCountersConcurrent(t *testing.T) {
+	const n = 200
+	counters := newCallCounters()
+	tools := []string{"hello", "bitcoin_price", "stats", "uptime"}
+
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			counters.Inc(tools[i%len(tools)])
+		}()
+	}
+	wg.Wait()
+
+	total, perTool := counters.Snapshot()
+	if total != n {
+		t.Errorf("total is %d, want %d", total, n)
+	}
+	var sum int64
+	for _, tool := range tools {
+		if perTool[tool] != n/int64(len(tools)) {
+			t.Errorf("%s counted %d calls, want %d", tool, perTool[tool], n/len(tools))
+		}
+		sum += perTool[tool]
+	}
+	if sum != total {
+		t.Errorf("per-tool counts add up to %d, want the total %d", sum, total)
+	}
+}
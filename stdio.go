@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/metoro-io/mcp-golang/transport"
+	"github.com/metoro-io/mcp-golang/transport/stdio"
+)
+
+// maxReconnectAttempts is how many times in a row -reconnect reopens the input after it ends without any data arriving.
+const maxReconnectAttempts = 5
+
+// reconnectBaseDelay is the wait before the first reconnect attempt; each further attempt doubles it.
+const reconnectBaseDelay = 500 * time.Millisecond
+
+// drainPollInterval is how often the transport checks whether every request read before the input ended is answered.
+const drainPollInterval = 10 * time.Millisecond
+
+// stdioTransport serves MCP over a byte stream, stdin and stdout by default, using the library's stdio transport
+// for the framing. That transport silently stops reading when its input ends, so stdioTransport watches the input:
+// once it ends, and any reconnect attempts have failed, the transport closes and Done is closed so the server can
+// shut down. With attempts allowed it opens the input again after a backoff instead, for wrapped or piped setups
+// where the peer comes back.
+type stdioTransport struct {
+	open        func() (io.Reader, error)
+	out         io.Writer
+	maxAttempts int
+	baseDelay   time.Duration
+
+	mu             sync.Mutex
+	current        *stdio.StdioServerTransport
+	messageHandler func(ctx context.Context, message *transport.BaseJsonRpcMessage)
+	errorHandler   func(error)
+	closeHandler   func()
+	// pending counts the requests read whose response hasn't been sent yet
+	pending int
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// newStdioTransport creates a stdioTransport reading from the input returned by open and writing to out. When the
+// input ends it is reopened up to maxAttempts times in a row, waiting baseDelay before the first attempt and twice as
+// long before each further one; zero attempts closes the transport on the first end of input.
+func newStdioTransport(open func() (io.Reader, error), out io.Writer, maxAttempts int, baseDelay time.Duration) *stdioTransport {
+	return &stdioTransport{
+		open:        open,
+		out:         out,
+		maxAttempts: maxAttempts,
+		baseDelay:   baseDelay,
+		done:        make(chan struct{}),
+	}
+}
+
+// Start implements transport.Transport.
+func (t *stdioTransport) Start(ctx context.Context) error {
+	in, err := t.open()
+	if err != nil {
+		return fmt.Errorf("error opening stdio input: %w", err)
+	}
+	go t.serve(in)
+	return nil
+}
+
+// serve reads messages from in until it ends, then reopens it while attempts remain. The attempt count resets
+// whenever an input delivers data, so only consecutive failures count against the limit.
+func (t *stdioTransport) serve(in io.Reader) {
+	delay := t.baseDelay
+	for attempt := 0; ; {
+		if in != nil && t.readUntilEnd(in) {
+			attempt, delay = 0, t.baseDelay
+		}
+		if attempt >= t.maxAttempts {
+			slog.Info("stdio input closed", "reconnect_attempts", attempt)
+			// Requests are answered asynchronously, so let the ones read before the end of input finish first
+			if !t.drain(shutdownTimeout) {
+				slog.Warn("Timed out waiting for responses to stdio requests", "timeout", shutdownTimeout)
+			}
+			t.Close()
+			return
+		}
+
+		attempt++
+		slog.Warn("stdio input closed, reconnecting", "attempt", attempt, "max_attempts", t.maxAttempts, "delay", delay)
+		select {
+		case <-time.After(delay):
+		case <-t.done:
+			return
+		}
+		delay *= 2
+
+		var err error
+		if in, err = t.open(); err != nil {
+			slog.Error("Error reopening stdio input", "attempt", attempt, "error", err)
+			in = nil
+		}
+	}
+}
+
+// readUntilEnd serves the messages read from in until it ends or the transport is closed, and reports whether
+// any data was read.
+func (t *stdioTransport) readUntilEnd(in io.Reader) bool {
+	r := &endReader{r: in, end: make(chan struct{})}
+	inner := stdio.NewStdioServerTransportWithIO(r, t.out)
+
+	t.mu.Lock()
+	handler := t.messageHandler
+	inner.SetMessageHandler(func(ctx context.Context, message *transport.BaseJsonRpcMessage) {
+		if message.Type == transport.BaseMessageTypeJSONRPCRequestType {
+			t.mu.Lock()
+			t.pending++
+			t.mu.Unlock()
+		}
+		handler(contextWithProgress(ctx, message, t.Send), message)
+	})
+	inner.SetErrorHandler(t.errorHandler)
+	t.current = inner
+	t.mu.Unlock()
+
+	// Start only fails on a transport that was already started, and this one is new
+	_ = inner.Start(context.Background())
+	select {
+	case <-r.end:
+	case <-t.done:
+	}
+	return r.sawData.Load()
+}
+
+// Send implements transport.Transport.
+func (t *stdioTransport) Send(ctx context.Context, message *transport.BaseJsonRpcMessage) error {
+	t.mu.Lock()
+	current := t.current
+	t.mu.Unlock()
+	if current == nil {
+		return fmt.Errorf("stdio transport is not started")
+	}
+	err := current.Send(ctx, message)
+
+	if message.Type == transport.BaseMessageTypeJSONRPCResponseType || message.Type == transport.BaseMessageTypeJSONRPCErrorType {
+		t.mu.Lock()
+		// Errors for unparseable input answer no counted request, so stop at zero
+		t.pending = max(t.pending-1, 0)
+		t.mu.Unlock()
+	}
+	return err
+}
+
+// drain waits until every request read so far has been answered or the timeout elapses, reporting whether they were.
+func (t *stdioTransport) drain(timeout time.Duration) bool {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	deadline := time.After(timeout)
+	for {
+		t.mu.Lock()
+		pending := t.pending
+		t.mu.Unlock()
+		if pending == 0 {
+			return true
+		}
+		select {
+		case <-ticker.C:
+		case <-deadline:
+			return false
+		case <-t.done:
+			return false
+		}
+	}
+}
+
+// Close implements transport.Transport, stopping the transport for good and closing Done.
+func (t *stdioTransport) Close() error {
+	t.closeOnce.Do(func() {
+		t.mu.Lock()
+		current, closeHandler := t.current, t.closeHandler
+		t.mu.Unlock()
+
+		close(t.done)
+		if current != nil {
+			current.Close()
+		}
+		if closeHandler != nil {
+			closeHandler()
+		}
+	})
+	return nil
+}
+
+// Done returns a channel that is closed once the transport has stopped, because its input ended for good or Close was called.
+func (t *stdioTransport) Done() <-chan struct{} {
+	return t.done
+}
+
+// SetCloseHandler implements transport.Transport.
+func (t *stdioTransport) SetCloseHandler(handler func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closeHandler = handler
+}
+
+// SetErrorHandler implements transport.Transport.
+func (t *stdioTransport) SetErrorHandler(handler func(error)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.errorHandler = handler
+}
+
+// SetMessageHandler implements transport.Transport.
+func (t *stdioTransport) SetMessageHandler(handler func(ctx context.Context, message *transport.BaseJsonRpcMessage)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.messageHandler = handler
+}
+
+// stdinPath is where stdin is reopened from for a reconnect.
+const stdinPath = "/dev/stdin"
+
+// stdinSource is the input factory of the stdio transport in production. The first connection is stdin itself; each
+// reconnect opens stdin again by path, since the descriptor of a pipe whose writer went away only ever reads EOF.
+// Only a named pipe or a terminal can be reopened to reach a new peer. The pipe is opened without blocking, so a
+// peer that hasn't come back yet reads as another end of input and costs an attempt instead of hanging the server.
+type stdinSource struct {
+	opened   bool
+	reopened *os.File
+}
+
+// open implements the open function of stdioTransport.
+func (s *stdinSource) open() (io.Reader, error) {
+	if !s.opened {
+		s.opened = true
+		return os.Stdin, nil
+	}
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("error inspecting stdin: %w", err)
+	}
+	if info.Mode()&(os.ModeNamedPipe|os.ModeCharDevice) == 0 {
+		return nil, fmt.Errorf("stdin is not a pipe or terminal, so it can't be reopened")
+	}
+	if s.reopened != nil {
+		s.reopened.Close()
+		s.reopened = nil
+	}
+	f, err := os.OpenFile(stdinPath, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return nil, fmt.Errorf("error reopening stdin: %w", err)
+	}
+	s.reopened = f
+	return f, nil
+}
+
+// endReader passes reads through to r, closing end on the first error, io.EOF included, and recording whether any
+// data was read.
+type endReader struct {
+	r       io.Reader
+	end     chan struct{}
+	once    sync.Once
+	sawData atomic.Bool
+}
+
+// Read implements io.Reader.
+func (r *endReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.sawData.Store(true)
+	}
+	if err != nil {
+		r.once.Do(func() { close(r.end) })
+	}
+	return n, err
+}
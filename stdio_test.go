@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/metoro-io/mcp-golang/transport"
+)
+
+// syncBuffer is a bytes.Buffer safe for the concurrent writes of a transport and reads of a test.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestStdioTransportReconnectsAfterEOF(t *testing.T) {
+	// The first input ends straight away, the second carries a request, and the rest end without data
+	var mu sync.Mutex
+	opens := 0
+	open := func() (io.Reader, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		opens++
+		if opens == 2 {
+			return strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping","params":{}}` + "\n"), nil
+		}
+		return strings.NewReader(""), nil
+	}
+	out := &syncBuffer{}
+	tr := newStdioTransport(open, out, 2, time.Millisecond)
+	tr.SetMessageHandler(func(ctx context.Context, message *transport.BaseJsonRpcMessage) {
+		if message.Type != transport.BaseMessageTypeJSONRPCRequestType {
+			return
+		}
+		tr.Send(ctx, transport.NewBaseMessageResponse(&transport.BaseJSONRPCResponse{
+			Id:      message.JsonRpcRequest.Id,
+			Jsonrpc: "2.0",
+			Result:  json.RawMessage(`{}`),
+		}))
+	})
+	if err := tr.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-tr.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the transport never closed after its input ended for good")
+	}
+	if !strings.Contains(out.String(), `"id":1`) {
+		t.Errorf("got output %q, want the request read after reconnecting answered", out.String())
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	// Two failed attempts after the input with data, whose success reset the count
+	if opens != 4 {
+		t.Errorf("opened the input %d times, want 4", opens)
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+	_ "time/tzdata" // embed the timezone database so LoadLocation works on hosts without one
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// CurrentTimeArguments defines the structure for arguments used to request the current time in a timezone.
+type CurrentTimeArguments struct {
+	Timezone string `json:"timezone" jsonschema:"default=UTC,description=The IANA timezone name (America/New_York, Europe/London, etc)"`
+	Format   string `json:"format" jsonschema:"default=2006-01-02T15:04:05Z07:00,description=A Go time layout such as 2006-01-02 15:04; defaults to RFC3339"`
+}
+
+// currentTime returns the time t in the named IANA zone formatted with layout.
+func currentTime(t time.Time, timezone, layout string) (string, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return "", fmt.Errorf("unknown timezone %q, expected an IANA name such as America/New_York", timezone)
+	}
+	return t.In(loc).Format(layout), nil
+}
+
+// currentTimeTool handles the current_time tool.
+func currentTimeTool(ctx context.Context, arguments CurrentTimeArguments) (*mcp_golang.ToolResponse, error) {
+	slog.DebugContext(ctx, "Received tool request", "tool", "current_time", "timezone", arguments.Timezone, "format", arguments.Format)
+
+	formatted, err := currentTime(time.Now(), arguments.Timezone, arguments.Format)
+	if err != nil {
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Error getting current time: %v", err))), nil
+	}
+
+	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(formatted)), nil
+}
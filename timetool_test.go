@@ -0,0 +1,23 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCurrentTime(t *testing.T) {
+	at := time.Date(2024, time.July, 1, 12, 30, 0, 0, time.UTC)
+
+	got, err := currentTime(at, "Asia/Tokyo", "2006-01-02 15:04 MST")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "2024-07-01 21:30 JST"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if _, err := currentTime(at, "Mars/Olympus_Mons", time.RFC3339); err == nil || !strings.Contains(err.Error(), "unknown timezone") {
+		t.Errorf("got error %v, want an unknown timezone error", err)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// maxTimezoneMatches bounds how many zone names search_timezones returns.
+const maxTimezoneMatches = 25
+
+// timezoneList is the list of IANA zone names; the time package can't enumerate the zones it knows, so the list
+// is embedded alongside the database from time/tzdata.
+//
+//go:embed timezones.txt
+var timezoneList string
+
+// timezoneNames holds the zone names from timezoneList, in alphabetical order.
+var timezoneNames = parseTimezoneList(timezoneList)
+
+// parseTimezoneList returns the zone names in list, one per line, skipping blank lines and # comments.
+func parseTimezoneList(list string) []string {
+	var names []string
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		names = append(names, line)
+	}
+	return names
+}
+
+// SearchTimezonesArguments defines the structure for arguments used to search the IANA timezone names.
+type SearchTimezonesArguments struct {
+	Query string `json:"query" jsonschema:"required,description=Part of a timezone name such as York or Europe/; case and spaces versus underscores don't matter"`
+}
+
+// searchTimezones returns up to limit zone names containing query, ignoring case and treating spaces as the
+// underscores zone names use, so "new york" finds America/New_York.
+func searchTimezones(names []string, query string, limit int) []string {
+	query = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(query), " ", "_"))
+	var matches []string
+	for _, name := range names {
+		if len(matches) == limit {
+			break
+		}
+		if strings.Contains(strings.ToLower(name), query) {
+			matches = append(matches, name)
+		}
+	}
+	return matches
+}
+
+// searchTimezonesTool handles the search_timezones tool.
+func searchTimezonesTool(ctx context.Context, arguments SearchTimezonesArguments) (*mcp_golang.ToolResponse, error) {
+	slog.DebugContext(ctx, "Received tool request", "tool", "search_timezones", "query", arguments.Query)
+
+	if strings.TrimSpace(arguments.Query) == "" {
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent("Error searching timezones: a query is required")), nil
+	}
+	// Look for one match more than is shown, to tell whether the list was cut short
+	matches := searchTimezones(timezoneNames, arguments.Query, maxTimezoneMatches+1)
+	if len(matches) == 0 {
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("No timezones match %q", arguments.Query))), nil
+	}
+	text := strings.Join(matches[:min(len(matches), maxTimezoneMatches)], "\n")
+	if len(matches) > maxTimezoneMatches {
+		text += fmt.Sprintf("\n(only the first %d matches are shown; narrow the query to see the rest)", maxTimezoneMatches)
+	}
+	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(text)), nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSearchTimezones(t *testing.T) {
+	for _, query := range []string{"York", "new york", "  NEW_YORK "} {
+		if got := searchTimezones(timezoneNames, query, maxTimezoneMatches); !slices.Contains(got, "America/New_York") {
+			t.Errorf("searchTimezones(%q) = %v, want America/New_York among them", query, got)
+		}
+	}
+	if got := searchTimezones(timezoneNames, "America/", maxTimezoneMatches); len(got) != maxTimezoneMatches {
+		t.Errorf("got %d matches for America/, want the limit of %d", len(got), maxTimezoneMatches)
+	}
+	if got := searchTimezones(timezoneNames, "Atlantis", maxTimezoneMatches); got != nil {
+		t.Errorf("got %v, want no matches", got)
+	}
+}
+
+func TestSearchTimezonesTool(t *testing.T) {
+	resp, err := searchTimezonesTool(context.Background(), SearchTimezonesArguments{Query: "America/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := toolText(t, resp)
+	if lines := strings.Split(text, "\n"); len(lines) != maxTimezoneMatches+1 || !strings.Contains(lines[maxTimezoneMatches], "only the first 25 matches") {
+		t.Errorf("got %d lines, want %d matches and a note that more were left out:\n%s", len(lines), maxTimezoneMatches, text)
+	}
+}
+
+func TestTimezoneListLoads(t *testing.T) {
+	if len(timezoneNames) == 0 || !slices.IsSorted(timezoneNames) {
+		t.Fatalf("got %d zone names, want a sorted list", len(timezoneNames))
+	}
+	for _, name := range timezoneNames {
+		if _, err := time.LoadLocation(name); err != nil {
+			t.Errorf("listed zone %s doesn't load: %v", name, err)
+		}
+	}
+}
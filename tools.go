@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"text/template"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"golang.org/x/time/rate"
+)
+
+// services holds the shared dependencies tool handlers are built from.
+type services struct {
+	config  Config
+	crypto  *CryptoClient
+	weather *WeatherClient
+	mempool *MempoolClient
+	cache   *priceCache
+	coins   *coinIndex
+	// debug enables tools meant for client developers, such as echo.
+	debug bool
+	// manifestTools are the tools loaded from the tool manifest directory, if any.
+	manifestTools []RegisteredTool
+	// priceTemplate renders the bitcoin_price text.
+	priceTemplate *template.Template
+	// logs holds the recent log lines served by logs://recent; nil when the log buffer is disabled.
+	logs *logBuffer
+	// limiters holds the rate limiter for each upstream-backed tool; tools without one are not limited.
+	limiters map[string]*rate.Limiter
+	// clientOpts are the options every upstream client is created with, such as the timeout and offline mode.
+	clientOpts []ClientOption
+}
+
+// rateLimitedTools are the tools that get their own token bucket to protect the CoinGecko API.
+var rateLimitedTools = []string{"bitcoin_price", "bitcoin_price_json", "crypto_price"}
+
+// newToolLimiters creates an independent token bucket allowing rps requests per second with the given burst
+// for each of the rate limited tools. A non-positive rps disables rate limiting.
+func newToolLimiters(rps float64, burst int) map[string]*rate.Limiter {
+	limiters := make(map[string]*rate.Limiter)
+	if rps <= 0 {
+		return limiters
+	}
+	for _, name := range rateLimitedTools {
+		limiters[name] = rate.NewLimiter(rate.Limit(rps), burst)
+	}
+	return limiters
+}
+
+// registerAll registers every tool, prompt and resource with the server.
+// Failures don't stop registration; they are collected and returned together.
+func registerAll(server *mcp_golang.Server, svc *services) error {
+	var errs []error
+	collect := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// Tools
+	enabledTools, disabledTools = toolSet(svc.config.EnabledTools), toolSet(svc.config.DisabledTools)
+	experimentalEnabled = svc.config.Experimental
+	offlineMode = svc.config.Offline
+	idempotency := newIdempotencyStore(defaultIdempotencyTTL)
+	collect(registerTool(server, "hello", "Say hello to a person with a personalized greeting message", helloTool, WithIdempotency("hello", idempotency)))
+	collect(registerTool(server, "bitcoin_price", "Get the latest Bitcoin price in various currencies", bitcoinPriceTool(svc.crypto, svc.cache, svc.priceTemplate), WithRateLimit("bitcoin_price", svc.limiters["bitcoin_price"])))
+	collect(registerTool(server, "bitcoin_price_json", withOutputSchema[BitcoinPriceResult]("Get the latest Bitcoin price as a JSON object"), bitcoinPriceJSONTool(svc.crypto, svc.cache), WithRateLimit("bitcoin_price_json", svc.limiters["bitcoin_price_json"])))
+	collect(registerTool(server, "bitcoin_prices", "Get the latest Bitcoin price in several currencies at once", bitcoinPricesTool(svc.crypto)))
+	collect(registerTool(server, "bitcoin_price_all", withOutputSchema[BitcoinPriceAllResult]("Get the Bitcoin price in every supported currency with one call, as a JSON list sorted from the highest price to the lowest"), bitcoinPriceAllTool(svc.crypto)))
+	collect(registerTool(server, "crypto_price", "Get the latest price of any cryptocurrency listed on CoinGecko in various currencies", cryptoPriceTool(svc.crypto), WithRateLimit("crypto_price", svc.limiters["crypto_price"])))
+	collect(registerTool(server, "crypto_prices", "Get the latest prices of up to 25 cryptocurrencies listed on CoinGecko at once", cryptoPricesTool(svc.crypto)))
+	collect(registerTool(server, "portfolio_value", "Value a portfolio of coin holdings in one currency, listing each holding and the total", portfolioValueTool(svc.crypto)))
+	collect(registerTool(server, "search_coins", "Search CoinGecko for coin ids by name or symbol, returning the top 10 matches", searchCoinsTool(svc.coins)))
+	collect(registerTool(server, "top_coins", "List the largest coins by market cap with their price and 24h change, as a text table or JSON", topCoinsTool(svc.crypto)))
+	collect(registerTool(server, "compare_coins", "Compare two coins side by side by price, 24h change and market cap, and say which is larger by market cap", compareCoinsTool(svc.crypto)))
+	collect(registerTool(server, "bitcoin_stats", "Get the 24h high, low and trading volume and the market cap of Bitcoin in various currencies", bitcoinStatsTool(svc.crypto)))
+	collect(registerTool(server, "global_market", "Get the total market cap, 24h volume, Bitcoin dominance and number of active cryptocurrencies across the whole crypto market", globalMarketTool(svc.crypto)))
+	collect(registerTool(server, "bitcoin_price_on", "Get the Bitcoin price on a past date in various currencies", bitcoinPriceOnTool(svc.crypto)))
+	collect(registerTool(server, "bitcoin_price_on_exchange", "Get the last traded Bitcoin price in a currency on one exchange, such as binance or kraken", bitcoinPriceOnExchangeTool(svc.crypto)))
+	collect(registerTool(server, "bitcoin_change", withOutputSchema[PriceChangeResult]("Get the absolute and percentage change in the Bitcoin price over the last N days as a JSON object"), bitcoinChangeTool(svc.crypto)))
+	collect(registerTool(server, "bitcoin_trend", "Get the Bitcoin price with an arrow and percentage showing its 24h trend, such as ▲ 2.30% — 51,200.00 USD", bitcoinTrendTool(svc.crypto)))
+	collect(registerTool(server, "bitcoin_sparkline", "Draw a text sparkline of the Bitcoin price over the last N days with its min, max and last values", bitcoinSparklineTool(svc.crypto)))
+	collect(registerTool(server, "bitcoin_sma", "Get the simple moving average of the daily Bitcoin closing price over a window, compared with the current price", bitcoinSMATool(svc.crypto)))
+	collect(registerTool(server, "dca_simulate", "Simulate buying a fixed amount of Bitcoin every N days over a past window, reporting the total invested, the coins accumulated and their current value", dcaSimulateTool(svc.crypto)))
+	collect(registerTool(server, "price_alert", "Check whether the Bitcoin price is currently above or below a threshold, and by how much", priceAlertTool(svc.crypto, svc.cache)))
+	collect(registerTool(server, "convert", withOutputSchema[ConversionResult]("Convert an amount between Bitcoin and fiat currencies, or between two fiat currencies via Bitcoin, as a JSON object"), convertTool(svc.crypto)))
+	collect(registerTool(server, "fiat_convert", "Convert an amount between two fiat currencies using the cross rate implied by their Bitcoin prices", fiatConvertTool(svc.crypto)))
+	collect(registerTool(server, "btc_fee_estimate", "Estimate the fee of a Bitcoin transaction from the fee rate mempool.space recommends, in sats and in a fiat currency at the current Bitcoin price", btcFeeEstimateTool(svc.mempool, svc.crypto, svc.config.FeeVBytes)))
+	collect(registerTool(server, "btc_network", withOutputSchema[NetworkStats]("Get the current Bitcoin block height, mining difficulty and network hashrate as a JSON object"), btcNetworkTool(svc.mempool)))
+	collect(registerTool(server, "resolve_currency", "Resolve a currency name or symbol such as dollars, € or yen to the ISO 4217 code the price tools accept", resolveCurrencyTool))
+	collect(registerTool(server, "weather", "Get the current temperature and conditions in a city", weatherTool(svc.weather)))
+	if len(svc.config.HTTPGetHosts) > 0 {
+		collect(registerTool(server, "http_get", "Fetch a URL on one of the allowed hosts ("+strings.Join(svc.config.HTTPGetHosts, ", ")+") and return its status, selected headers and up to 16 KiB of the body", httpGetTool(svc.config.HTTPGetHosts, svc.clientOpts...)))
+	}
+	collect(registerTool(server, "health", withOutputSchema[HealthStatus]("Report server health and uptime, optionally checking CoinGecko reachability"), healthTool(svc.crypto)))
+	collect(registerTool(server, "stats", withOutputSchema[Stats]("Report the total number of tool calls, the count per tool and the server uptime"), statsTool))
+	collect(registerTool(server, "uptime", withOutputSchema[Uptime]("Report how long the server has been running, formatted like 2d 3h 4m 5s, and when it started"), uptimeTool))
+	if recentCalls != nil {
+		collect(registerTool(server, "call_history", withOutputSchema[CallHistory]("Report the most recent tool calls with their redacted arguments, outcome and duration, oldest first"), callHistoryTool(recentCalls)))
+	}
+	collect(registerTool(server, "config", withOutputSchema[Config]("Report the effective server configuration after flags, environment variables and the config file are applied, with secrets redacted"), configTool(svc.config)))
+	collect(registerTool(server, "version", withOutputSchema[VersionInfo]("Report the version, git commit and build date of this server"), versionTool))
+	collect(registerTool(server, "current_time", "Get the current time in a given IANA timezone", currentTimeTool))
+	collect(registerTool(server, "search_timezones", "Find IANA timezone names containing a query such as York, for use with current_time; at most 25 are returned", searchTimezonesTool))
+	collect(registerTool(server, "format_json", "Validate a JSON document and return it indented, or the position of the first syntax error", formatJSONTool))
+	collect(registerTool(server, "calculate", "Evaluate an arithmetic expression with + - * / and parentheses on decimal numbers", calculateTool))
+	collect(registerTool(server, "qr", "Render text such as a bitcoin: payment URI as a QR code drawn in Unicode blocks for display in a terminal", qrTool))
+	if svc.debug {
+		collect(registerTool(server, "echo", "Debugging aid: return the raw arguments as JSON along with the Go type of each top-level field", echoTool))
+	}
+	for _, tool := range svc.manifestTools {
+		collect(registerTool(server, tool.Name, tool.Description, tool.Handler))
+	}
+	// list_tools goes last so it sees every other tool, and itself
+	collect(registerTool(server, "list_tools", "List the names and descriptions of every tool this server provides", listTools))
+	reportFilteredTools()
+
+	// Prompts
+	collect(registerPrompt(server, "prompt_test", "This is a test prompt", promptTest))
+	collect(registerPrompt(server, "market_summary", "Ask for a Bitcoin market summary based on the live price", marketSummaryPrompt(svc.crypto, svc.cache)))
+
+	// Resources
+	store := NewResourceStore()
+	collect(store.Add(Resource{URI: "test://resource", Name: "resource_test", Description: "This is a test resource, as a JSON object", MimeType: "application/json", Read: jsonResource(testResource)}))
+	collect(store.Add(Resource{URI: "test://resource/text", Name: "resource_test_text", Description: "This is a test resource, as plain text", MimeType: "text/plain", Read: testResourceText}))
+	collect(store.Add(Resource{URI: "config://server", Name: "config", Description: "The server's effective configuration, with secrets redacted", MimeType: "application/json",
+		Read: jsonResource(func() any { return svc.config.redacted() })}))
+	collect(store.Add(Resource{URI: currenciesResourceURI, Name: "currencies", Description: "The currency codes accepted by the price tools, as a JSON array", MimeType: "application/json", Read: currenciesResource}))
+	if svc.logs != nil {
+		collect(store.Add(Resource{URI: recentLogsResourceURI, Name: "recent_logs", Description: "The most recent server log lines, oldest first, as JSON lines", MimeType: "text/plain", Read: svc.logs.Read}))
+	}
+	collect(registerResources(server, store))
+
+	return errors.Join(errs...)
+}
+
+// registerPrompt registers a prompt handler guarded against panics.
+func registerPrompt[T any](server *mcp_golang.Server, name, description string, handler func(T) (*mcp_golang.PromptResponse, error)) error {
+	if err := server.RegisterPrompt(name, description, recoverPrompt(name, handler)); err != nil {
+		return fmt.Errorf("registering prompt %s: %w", name, err)
+	}
+	registeredPrompts = append(registeredPrompts, name)
+	return nil
+}
+
+// registerResource registers a resource handler guarded against panics. Malformed URIs are rejected, since clients
+// could never address them.
+func registerResource(server *mcp_golang.Server, uri, name, description, mimeType string, handler func() (*mcp_golang.ResourceResponse, error)) error {
+	if err := validateResourceURI(uri); err != nil {
+		return fmt.Errorf("registering resource %s: %w", uri, err)
+	}
+	if err := server.RegisterResource(uri, name, description, mimeType, recoverResource(uri, handler)); err != nil {
+		return fmt.Errorf("registering resource %s: %w", uri, err)
+	}
+	registeredResources = append(registeredResources, uri)
+	slog.Debug("Successfully registered resource", "uri", uri)
+	return nil
+}
+
+// validateResourceURI checks that uri parses as a URI and has a scheme, such as test:// or config://.
+func validateResourceURI(uri string) error {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return fmt.Errorf("malformed URI: %w", err)
+	}
+	if u.Scheme == "" {
+		return fmt.Errorf("malformed URI: missing scheme")
+	}
+	return nil
+}
+
+// helloTool handles the hello tool.
+func helloTool(ctx context.Context, arguments MyFunctionsArguments) (*mcp_golang.ToolResponse, error) {
+	slog.DebugContext(ctx, "Received tool request", "tool", "hello")
+	if err := validateRequired(arguments); err != nil {
+		return nil, err
+	}
+	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Hello, %s! Welcome to the MCP Example.", arguments.Submitter))), nil
+}
+
+// promptTest handles the prompt_test prompt. Invalid arguments, such as a missing title, get a prompt explaining
+// the problem rather than a malformed greeting.
+func promptTest(arguments Content) (*mcp_golang.PromptResponse, error) {
+	slog.Debug("Received prompt request", "prompt", "prompt_test")
+	if err := validateRequired(arguments); err != nil {
+		return mcp_golang.NewPromptResponse("invalid arguments", mcp_golang.NewPromptMessage(mcp_golang.NewTextContent(fmt.Sprintf("The prompt_test prompt could not be built: %v", err)), mcp_golang.RoleUser)), nil
+	}
+	return mcp_golang.NewPromptResponse("description", mcp_golang.NewPromptMessage(mcp_golang.NewTextContent(fmt.Sprintf("Hello, %s!", arguments.Title)), mcp_golang.RoleUser)), nil
+}
+
+// testResourceMessage is the content of the test resources.
+const testResourceMessage = "This is a test resource"
+
+// testResource returns the content of test://resource, the test message as a JSON object.
+func testResource() any {
+	return map[string]string{"message": testResourceMessage}
+}
+
+// testResourceText returns the content of test://resource/text, the test message as plain text.
+func testResourceText() (string, error) {
+	return testResourceMessage, nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// Arrows shown by bitcoin_trend for a rising, falling and flat 24h change.
+const (
+	arrowUp   = "▲"
+	arrowDown = "▼"
+	arrowFlat = "→"
+)
+
+// BitcoinTrendArguments defines the structure for arguments used to request the Bitcoin price with its 24h trend.
+type BitcoinTrendArguments struct {
+	Currency string `json:"currency" jsonschema:"description=The currency to get the Bitcoin price in (USD, EUR, GBP, etc)"`
+	Locale   string `json:"locale" jsonschema:"default=en-US,description=The locale to format the price for (en-US, de-DE, etc)"`
+}
+
+// CoinMarket retrieves the markets entry of a single coin, which carries its current price and 24h change.
+func (c *CryptoClient) CoinMarket(ctx context.Context, coinID, currency string) (CoinGeckoMarket, error) {
+	coinID = strings.ToLower(coinID)
+	markets, err := c.CoinMarkets(ctx, []string{coinID}, currency)
+	if err != nil {
+		return CoinGeckoMarket{}, err
+	}
+	market, ok := markets[coinID]
+	if !ok {
+		return CoinGeckoMarket{}, fmt.Errorf("%w: %s", ErrCoinNotFound, coinID)
+	}
+	return market, nil
+}
+
+// trendArrow returns the arrow for a percentage change as shown with two decimals, so that a change too small
+// to show reads as flat rather than as a rise or fall of 0.00%.
+func trendArrow(changePercent float64) string {
+	switch rounded := math.Round(changePercent*100) / 100; {
+	case rounded > 0:
+		return arrowUp
+	case rounded < 0:
+		return arrowDown
+	default:
+		return arrowFlat
+	}
+}
+
+// formatTrend renders a price and its 24h change compactly, such as "▲ 2.30% — 51,200.00 USD". A nil change,
+// which CoinGecko reports for coins without enough trading history, is called out instead of shown as flat.
+func formatTrend(price, currency string, changePercent *float64) string {
+	if changePercent == nil {
+		return fmt.Sprintf("%s %s (24h change not available)", price, currency)
+	}
+	return fmt.Sprintf("%s %.2f%% — %s %s", trendArrow(*changePercent), math.Abs(*changePercent), price, currency)
+}
+
+// bitcoinTrendTool returns the handler for the bitcoin_trend tool, fetching the market data with client.
+func bitcoinTrendTool(client *CryptoClient) func(context.Context, BitcoinTrendArguments) (*mcp_golang.ToolResponse, error) {
+	return func(ctx context.Context, arguments BitcoinTrendArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", "bitcoin_trend", "currency", arguments.Currency, "locale", arguments.Locale)
+
+		printer, err := localePrinter(arguments.Locale)
+		if err != nil {
+			return toolFailure("error fetching Bitcoin trend", err)
+		}
+		// Fall back to the configured default currency if none is specified
+		currency, err := NormalizeCurrency(currencyOrDefault(arguments.Currency))
+		if err != nil {
+			return toolFailure("error fetching Bitcoin trend", err)
+		}
+
+		market, err := client.CoinMarket(ctx, "bitcoin", currency)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error fetching Bitcoin market data", "tool", "bitcoin_trend", "currency", currency, "error", err)
+			return toolFailure("error fetching Bitcoin trend", err)
+		}
+		if market.CurrentPrice == nil {
+			return toolFailure("error fetching Bitcoin trend", priceUnavailableError(currency))
+		}
+
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(formatTrend(formatPrice(printer, *market.CurrentPrice, currency), currency, market.PriceChangePercentage24h))), nil
+	}
+}
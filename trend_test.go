@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTrendArrow(t *testing.T) {
+	tests := []struct {
+		change float64
+		want   string
+	}{
+		{2.3, arrowUp},
+		{0.01, arrowUp},
+		{-4.5, arrowDown},
+		{-0.01, arrowDown},
+		{0, arrowFlat},
+		// Too small to show with two decimals, so shown as flat rather than "▲ 0.00%"
+		{0.004, arrowFlat},
+		{-0.004, arrowFlat},
+	}
+	for _, tt := range tests {
+		if got := trendArrow(tt.change); got != tt.want {
+			t.Errorf("trendArrow(%v) = %q, want %q", tt.change, got, tt.want)
+		}
+	}
+}
+
+func TestFormatTrend(t *testing.T) {
+	up, down := 2.3, -1.25
+	tests := []struct {
+		change *float64
+		want   string
+	}{
+		{&up, "▲ 2.30% — 51,200.00 USD"},
+		{&down, "▼ 1.25% — 51,200.00 USD"},
+		{nil, "51,200.00 USD (24h change not available)"},
+	}
+	for _, tt := range tests {
+		if got := formatTrend("51,200.00", "USD", tt.change); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestBitcoinTrendWithoutChange(t *testing.T) {
+	transport := cannedJSON(`[{"id":"bitcoin","symbol":"btc","name":"Bitcoin","current_price":51200,"price_change_percentage_24h":null}]`)
+	resp, err := bitcoinTrendTool(testCryptoClient(transport))(context.Background(), BitcoinTrendArguments{Currency: "USD", Locale: "en-US"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := toolText(t, resp), "51,200.00 USD (24h change not available)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
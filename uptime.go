@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// UptimeArguments defines the (empty) arguments of the uptime tool.
+type UptimeArguments struct{}
+
+// Uptime is the running time returned by the uptime tool.
+type Uptime struct {
+	Uptime    string    `json:"uptime" jsonschema:"description=How long the server has been running, such as 2d 3h 4m 5s"`
+	StartedAt time.Time `json:"started_at" jsonschema:"description=When the server started"`
+}
+
+// formatUptime formats d in whole seconds as days, hours, minutes and seconds, such as "2d 3h 4m 5s". Units larger
+// than the first non-zero one are left out, so a sub-minute duration is just seconds; the ones below it are always
+// shown, zero or not, so "1h 0m 5s" can't be misread.
+func formatUptime(d time.Duration) string {
+	total := int64(max(d, 0) / time.Second)
+	units := []struct {
+		suffix string
+		value  int64
+	}{
+		{"d", total / 86400},
+		{"h", total / 3600 % 24},
+		{"m", total / 60 % 60},
+		{"s", total % 60},
+	}
+
+	var parts []string
+	for i, unit := range units {
+		if len(parts) == 0 && unit.value == 0 && i < len(units)-1 {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%d%s", unit.value, unit.suffix))
+	}
+	return strings.Join(parts, " ")
+}
+
+// uptimeTool handles the uptime tool, reporting how long the server has been running and since when.
+func uptimeTool(ctx context.Context, _ UptimeArguments) (*mcp_golang.ToolResponse, error) {
+	slog.DebugContext(ctx, "Received tool request", "tool", "uptime")
+
+	return NewJSONToolResponse(Uptime{
+		Uptime:    formatUptime(time.Since(startTime)),
+		StartedAt: startTime.UTC(),
+	})
+}
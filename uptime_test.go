@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestFormatUptime(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0s"},
+		{-time.Second, "0s"},
+		{999 * time.Millisecond, "0s"},
+		{42 * time.Second, "42s"},
+		{time.Minute, "1m 0s"},
+		{3*time.Hour + 5*time.Second, "3h 0m 5s"},
+		{2*24*time.Hour + 3*time.Hour + 4*time.Minute + 5*time.Second, "2d 3h 4m 5s"},
+		{24 * time.Hour, "1d 0h 0m 0s"},
+		{400*24*time.Hour + 59*time.Minute, "400d 0h 59m 0s"},
+	}
+	for _, tt := range tests {
+		if got := formatUptime(tt.d); got != tt.want {
+			t.Errorf("formatUptime(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestUptimeToolReportsStartTime(t *testing.T) {
+	resp, err := uptimeTool(context.Background(), UptimeArguments{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Uptime
+	if err := json.Unmarshal([]byte(toolText(t, resp)), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.StartedAt.Equal(startTime) || got.Uptime == "" {
+		t.Errorf("got %+v, want the package start time %v", got, startTime)
+	}
+}
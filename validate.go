@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// validateRequired walks the fields of the struct v and returns an error naming the first field whose jsonschema tag
+// marks it as required but which holds its zero value, or whose string value is longer than its maxLength option.
+// Lengths are counted in runes, as JSON Schema does. Nested structs are checked recursively.
+func validateRequired(v any) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return fmt.Errorf("arguments are missing")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	return validateStruct(rv, "")
+}
+
+// validateStruct checks the required fields and length limits of rv, prefixing reported field names with prefix.
+func validateStruct(rv reflect.Value, prefix string) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := prefix + jsonFieldName(field)
+		value := rv.Field(i)
+
+		if isRequired(field) && isEmpty(value) {
+			return fmt.Errorf("missing required field: %s", name)
+		}
+		if limit, ok := maxLength(field); ok {
+			if n := stringLength(value); n > limit {
+				return fmt.Errorf("field %s is too long: %d characters, the maximum is %d", name, n, limit)
+			}
+		}
+
+		// Descend into nested structs so their own required fields are enforced too
+		if value.Kind() == reflect.Ptr && !value.IsNil() {
+			value = value.Elem()
+		}
+		if value.Kind() == reflect.Struct {
+			// Embedded structs such as Idempotent contribute top-level fields, so don't prefix their names
+			nested := name + "."
+			if field.Anonymous {
+				nested = prefix
+			}
+			if err := validateStruct(value, nested); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isRequired reports whether the field's jsonschema tag contains the required option.
+func isRequired(field reflect.StructField) bool {
+	for _, option := range strings.Split(field.Tag.Get("jsonschema"), ",") {
+		if option == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+// tagOption returns the value of the name= option of the field's jsonschema tag, if it has one.
+func tagOption(field reflect.StructField, name string) (string, bool) {
+	for _, option := range strings.Split(field.Tag.Get("jsonschema"), ",") {
+		if v, ok := strings.CutPrefix(option, name+"="); ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// maxLength returns the limit set by the maxLength option of the field's jsonschema tag, if any.
+func maxLength(field reflect.StructField) (int, bool) {
+	v, ok := tagOption(field, "maxLength")
+	if !ok {
+		return 0, false
+	}
+	limit, err := strconv.Atoi(v)
+	return limit, err == nil
+}
+
+// applyDefaults sets every zero-valued field of the struct v points to that has a default option in its jsonschema
+// tag, such as default=en-US, to that default. Nested structs are filled in recursively; v may also point to a
+// non-struct type, which is left alone. The same tag puts the default in the tool's input schema.
+func applyDefaults(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("applyDefaults needs a non-nil pointer, got %T", v)
+	}
+	if rv = rv.Elem(); rv.Kind() != reflect.Struct {
+		return nil
+	}
+	return applyStructDefaults(rv)
+}
+
+// applyStructDefaults fills in the defaults of the fields of rv.
+func applyStructDefaults(rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		value := rv.Field(i)
+		if value.Kind() == reflect.Struct {
+			if err := applyStructDefaults(value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		def, ok := tagOption(field, "default")
+		if !ok || !value.IsZero() {
+			continue
+		}
+		if err := setFromString(value, def); err != nil {
+			return fmt.Errorf("invalid default for field %s: %w", jsonFieldName(field), err)
+		}
+	}
+	return nil
+}
+
+// setFromString parses s as the kind of v and stores it in v.
+func setFromString(v reflect.Value, s string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("defaults are not supported for %s fields", v.Kind())
+	}
+	return nil
+}
+
+// stringLength returns the number of runes in a string or non-nil string pointer, and 0 for anything else.
+func stringLength(v reflect.Value) int {
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.String {
+		return 0
+	}
+	return utf8.RuneCountInString(v.String())
+}
+
+// isEmpty reports whether a required value should be treated as missing.
+// Structs are never empty themselves; their required fields are checked instead.
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Struct:
+		return false
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+// jsonFieldName returns the name a field is encoded as in JSON.
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
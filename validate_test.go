@@ -0,0 +1,98 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateRequiredMissingFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    any
+		missing string
+	}{
+		{"hello complete", MyFunctionsArguments{Submitter: "claude", Content: Content{Title: "Hi"}}, ""},
+		{"hello without submitter", MyFunctionsArguments{Content: Content{Title: "Hi"}}, "submitter"},
+		{"hello with blank submitter", MyFunctionsArguments{Submitter: "  ", Content: Content{Title: "Hi"}}, "submitter"},
+		{"hello without title", MyFunctionsArguments{Submitter: "claude"}, "content.title"},
+		{"price complete", BitcoinPriceArguments{Currency: "USD"}, ""},
+		{"price without currency", BitcoinPriceArguments{Locale: "en-US"}, "currency"},
+		{"price by pointer", &BitcoinPriceArguments{}, "currency"},
+	}
+	for _, tt := range tests {
+		err := validateRequired(tt.args)
+		switch {
+		case tt.missing == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		case tt.missing != "" && (err == nil || err.Error() != "missing required field: "+tt.missing):
+			t.Errorf("%s: got error %v, want missing required field: %s", tt.name, err, tt.missing)
+		}
+	}
+
+	if err := validateRequired((*BitcoinPriceArguments)(nil)); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("nil arguments: got error %v, want arguments are missing", err)
+	}
+}
+
+func TestValidateRequiredMaxLength(t *testing.T) {
+	title := "Hi"
+	tests := []struct {
+		name    string
+		args    MyFunctionsArguments
+		tooLong string
+	}{
+		{"submitter at the limit", MyFunctionsArguments{Submitter: strings.Repeat("a", 256), Content: Content{Title: title}}, ""},
+		{"submitter over the limit", MyFunctionsArguments{Submitter: strings.Repeat("a", 257), Content: Content{Title: title}}, "submitter"},
+		// Multi-byte characters count once each
+		{"submitter of runes at the limit", MyFunctionsArguments{Submitter: strings.Repeat("é", 256), Content: Content{Title: title}}, ""},
+		{"title at the limit", MyFunctionsArguments{Submitter: "claude", Content: Content{Title: strings.Repeat("a", 1024)}}, ""},
+		{"title over the limit", MyFunctionsArguments{Submitter: "claude", Content: Content{Title: strings.Repeat("a", 1025)}}, "content.title"},
+	}
+	for _, tt := range tests {
+		err := validateRequired(tt.args)
+		switch {
+		case tt.tooLong == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		case tt.tooLong != "" && (err == nil || !strings.HasPrefix(err.Error(), "field "+tt.tooLong+" is too long")):
+			t.Errorf("%s: got error %v, want field %s is too long", tt.name, err, tt.tooLong)
+		}
+	}
+}
+
+func TestApplyDefaults(t *testing.T) {
+	type nested struct {
+		Count int `json:"count" jsonschema:"default=3"`
+	}
+	type arguments struct {
+		Locale   string  `json:"locale" jsonschema:"default=en-US"`
+		N        int     `json:"n" jsonschema:"default=10"`
+		Ratio    float64 `json:"ratio" jsonschema:"default=0.5"`
+		Stream   bool    `json:"stream" jsonschema:"default=true"`
+		Currency string  `json:"currency"`
+		Nested   nested  `json:"nested"`
+	}
+
+	var args arguments
+	if err := applyDefaults(&args); err != nil {
+		t.Fatal(err)
+	}
+	want := arguments{Locale: "en-US", N: 10, Ratio: 0.5, Stream: true, Nested: nested{Count: 3}}
+	if args != want {
+		t.Errorf("got %+v, want %+v", args, want)
+	}
+
+	given := arguments{Locale: "de-DE", N: 5, Currency: "EUR"}
+	if err := applyDefaults(&given); err != nil {
+		t.Fatal(err)
+	}
+	if given.Locale != "de-DE" || given.N != 5 || given.Currency != "EUR" {
+		t.Errorf("got %+v, want the given values kept", given)
+	}
+
+	bad := struct {
+		N int `json:"n" jsonschema:"default=ten"`
+	}{}
+	if err := applyDefaults(&bad); err == nil || !strings.Contains(err.Error(), "invalid default for field n") {
+		t.Errorf("got error %v, want the bad default reported", err)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// Build information, set at build time with for example
+// -ldflags "-X main.version=v1.2.3 -X main.commit=$(git rev-parse HEAD) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)".
+var (
+	version   = "dev"
+	commit    = "unknown"
+	buildDate = "unknown"
+)
+
+// userAgent is sent with every outbound HTTP request; it is set from the config in main.
+var userAgent = defaultUserAgent()
+
+// defaultUserAgent identifies this server and its version to upstream APIs.
+func defaultUserAgent() string {
+	return "mcp-example-golang/" + version
+}
+
+// VersionArguments defines the (empty) arguments of the version tool.
+type VersionArguments struct{}
+
+// VersionInfo is the build information returned by the version tool.
+type VersionInfo struct {
+	Version   string `json:"version" jsonschema:"description=Release version; dev for local builds"`
+	Commit    string `json:"commit" jsonschema:"description=Git commit the server was built from"`
+	BuildDate string `json:"build_date" jsonschema:"description=When the server was built"`
+	GoVersion string `json:"go_version" jsonschema:"description=Go toolchain the server was built with"`
+}
+
+// buildInfo returns the build information of the running server.
+func buildInfo() VersionInfo {
+	return VersionInfo{
+		Version:   version,
+		Commit:    commit,
+		BuildDate: buildDate,
+		GoVersion: runtime.Version(),
+	}
+}
+
+// versionTool handles the version tool, reporting which build of the server is running.
+func versionTool(ctx context.Context, _ VersionArguments) (*mcp_golang.ToolResponse, error) {
+	slog.DebugContext(ctx, "Received tool request", "tool", "version")
+	return NewJSONToolResponse(buildInfo())
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"runtime"
+	"testing"
+)
+
+func TestVersionDefaultsWithoutLdflags(t *testing.T) {
+	resp, err := versionTool(context.Background(), VersionArguments{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var info VersionInfo
+	if err := json.Unmarshal([]byte(toolText(t, resp)), &info); err != nil {
+		t.Fatal(err)
+	}
+	want := VersionInfo{Version: "dev", Commit: "unknown", BuildDate: "unknown", GoVersion: runtime.Version()}
+	if info != want {
+		t.Errorf("got %+v, want %+v", info, want)
+	}
+	if ua := defaultUserAgent(); ua != "mcp-example-golang/dev" {
+		t.Errorf("default user agent is %q, want mcp-example-golang/dev", ua)
+	}
+}
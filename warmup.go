@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// warmupCurrencies are the currencies whose Bitcoin price is prefetched on startup with -warmup.
+var warmupCurrencies = []string{"USD", "EUR", "GBP"}
+
+// warmupCache fetches the Bitcoin price in each of currencies with a single CoinGecko request and stores it in cache,
+// so the first tool calls after startup don't have to wait for the API. Results are logged per currency; failures
+// are not fatal, since the tools simply fetch on demand instead.
+func warmupCache(ctx context.Context, client *CryptoClient, cache *priceCache, currencies []string) {
+	start := time.Now()
+	prices, maxAge, err := client.cryptoPrices(ctx, "bitcoin", currencies)
+	if err != nil {
+		for _, currency := range currencies {
+			slog.WarnContext(ctx, "Error warming up price cache", "currency", currency, "error", err)
+		}
+		return
+	}
+
+	for _, currency := range currencies {
+		price, ok := prices[currency]
+		if !ok {
+			slog.WarnContext(ctx, "Error warming up price cache", "currency", currency, "error", priceUnavailableError(currency))
+			continue
+		}
+		cache.SetWithMaxAge(priceCacheKey("bitcoin", strings.ToLower(currency)), price, maxAge)
+		slog.InfoContext(ctx, "Warmed up price cache", "currency", currency, "duration", time.Since(start))
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestWarmupCachePopulatesCurrencies(t *testing.T) {
+	logs := captureLogs(t)
+	transport := cannedJSON(`{"bitcoin":{"usd":50000,"eur":46000}}`)
+	cache := newPriceCache(defaultCacheTTL, 0)
+	warmupCache(context.Background(), testCryptoClient(transport), cache, warmupCurrencies)
+
+	if n := transport.requests.Load(); n != 1 {
+		t.Errorf("made %d requests, want every currency fetched at once", n)
+	}
+	for currency, want := range map[string]float64{"usd": 50000, "eur": 46000} {
+		if v, ok := cache.Get(priceCacheKey("bitcoin", currency)); !ok || v != want {
+			t.Errorf("%s: cache holds %v, %v, want %v", currency, v, ok, want)
+		}
+	}
+	if _, ok := cache.Get(priceCacheKey("bitcoin", "gbp")); ok {
+		t.Error("GBP was cached though CoinGecko sent no price for it")
+	}
+	if !strings.Contains(strings.Join(logs.Lines(), "\n"), `"msg":"Error warming up price cache","currency":"GBP"`) {
+		t.Error("the missing GBP price was not logged")
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// Open-Meteo endpoints; neither requires an API key.
+const (
+	defaultGeocodingBaseURL = "https://geocoding-api.open-meteo.com/v1"
+	defaultForecastBaseURL  = "https://api.open-meteo.com/v1"
+)
+
+// Supported values for the weather tool's units argument.
+const (
+	unitsMetric   = "metric"
+	unitsImperial = "imperial"
+)
+
+// WeatherArguments defines the structure for arguments used to request the current weather in a city.
+type WeatherArguments struct {
+	City  string `json:"city" jsonschema:"required,description=The name of the city (London, New York, Tokyo, etc)"`
+	Units string `json:"units" jsonschema:"enum=metric,enum=imperial,default=metric,description=metric or imperial"`
+}
+
+// geocodingResponse represents the parts of the Open-Meteo geocoding search response we use.
+type geocodingResponse struct {
+	Results []struct {
+		Name      string  `json:"name"`
+		Country   string  `json:"country"`
+		Latitude  float64 `json:"latitude"`
+		Longitude float64 `json:"longitude"`
+	} `json:"results"`
+}
+
+// forecastResponse represents the parts of the Open-Meteo forecast response we use.
+type forecastResponse struct {
+	Current struct {
+		Temperature float64 `json:"temperature_2m"`
+		WeatherCode int     `json:"weather_code"`
+	} `json:"current"`
+	CurrentUnits struct {
+		Temperature string `json:"temperature_2m"`
+	} `json:"current_units"`
+}
+
+// Weather is the current weather for a resolved location.
+type Weather struct {
+	Location    string
+	Temperature float64
+	Unit        string
+	Conditions  string
+}
+
+// WeatherClient fetches current weather from the Open-Meteo geocoding and forecast APIs.
+type WeatherClient struct {
+	geocoding apiClient
+	forecast  apiClient
+}
+
+// NewWeatherClient creates a WeatherClient using the public Open-Meteo endpoints, then applies the given options to both.
+func NewWeatherClient(opts ...ClientOption) *WeatherClient {
+	return &WeatherClient{
+		geocoding: newAPIClient("Open-Meteo geocoding", defaultGeocodingBaseURL, opts...),
+		forecast:  newAPIClient("Open-Meteo forecast", defaultForecastBaseURL, opts...),
+	}
+}
+
+// CurrentWeather resolves city to coordinates and returns its current temperature and conditions in the given units.
+func (c *WeatherClient) CurrentWeather(ctx context.Context, city, units string) (Weather, error) {
+	query := url.Values{}
+	query.Set("name", city)
+	query.Set("count", "1")
+
+	var places geocodingResponse
+	if err := c.geocoding.getJSON(ctx, "/search", query, &places); err != nil {
+		return Weather{}, err
+	}
+	if len(places.Results) == 0 {
+		return Weather{}, fmt.Errorf("unknown city: %s", city)
+	}
+	place := places.Results[0]
+
+	query = url.Values{}
+	query.Set("latitude", strconv.FormatFloat(place.Latitude, 'f', -1, 64))
+	query.Set("longitude", strconv.FormatFloat(place.Longitude, 'f', -1, 64))
+	query.Set("current", "temperature_2m,weather_code")
+	if units == unitsImperial {
+		query.Set("temperature_unit", "fahrenheit")
+	}
+
+	var forecast forecastResponse
+	if err := c.forecast.getJSON(ctx, "/forecast", query, &forecast); err != nil {
+		return Weather{}, err
+	}
+
+	location := place.Name
+	if place.Country != "" {
+		location += ", " + place.Country
+	}
+	return Weather{
+		Location:    location,
+		Temperature: forecast.Current.Temperature,
+		Unit:        forecast.CurrentUnits.Temperature,
+		Conditions:  weatherConditions(forecast.Current.WeatherCode),
+	}, nil
+}
+
+// weatherConditions describes a WMO weather interpretation code as used by Open-Meteo.
+func weatherConditions(code int) string {
+	switch {
+	case code == 0:
+		return "clear sky"
+	case code <= 3:
+		return "partly cloudy"
+	case code == 45 || code == 48:
+		return "fog"
+	case code >= 51 && code <= 57:
+		return "drizzle"
+	case code >= 61 && code <= 67:
+		return "rain"
+	case code >= 71 && code <= 77:
+		return "snow"
+	case code >= 80 && code <= 82:
+		return "rain showers"
+	case code == 85 || code == 86:
+		return "snow showers"
+	case code >= 95:
+		return "thunderstorm"
+	default:
+		return "unknown conditions"
+	}
+}
+
+// weatherTool returns the handler for the weather tool, fetching forecasts with client.
+func weatherTool(client *WeatherClient) func(context.Context, WeatherArguments) (*mcp_golang.ToolResponse, error) {
+	return func(ctx context.Context, arguments WeatherArguments) (*mcp_golang.ToolResponse, error) {
+		slog.DebugContext(ctx, "Received tool request", "tool", "weather", "city", arguments.City, "units", arguments.Units)
+
+		city := strings.TrimSpace(arguments.City)
+		if city == "" {
+			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent("Error fetching weather: a city is required")), nil
+		}
+		units := strings.ToLower(strings.TrimSpace(arguments.Units))
+		if units != unitsMetric && units != unitsImperial {
+			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Error fetching weather: unsupported units %q, expected %s or %s", arguments.Units, unitsMetric, unitsImperial))), nil
+		}
+
+		weather, err := client.CurrentWeather(ctx, city, units)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error fetching weather", "tool", "weather", "city", city, "error", err)
+			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("Error fetching weather: %s", describeError(err)))), nil
+		}
+
+		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(fmt.Sprintf("The current weather in %s is %.1f%s with %s",
+			weather.Location,
+			weather.Temperature,
+			weather.Unit,
+			weather.Conditions))), nil
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newWeatherStub serves the geocoding and forecast endpoints, knowing only London.
+func newWeatherStub(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/search":
+			if r.URL.Query().Get("name") != "London" {
+				w.Write([]byte(`{"generationtime_ms":0.5}`))
+				return
+			}
+			w.Write([]byte(`{"results":[{"name":"London","country":"United Kingdom","latitude":51.5085,"longitude":-0.12574}]}`))
+		case "/forecast":
+			w.Write([]byte(`{"current":{"temperature_2m":14.3,"weather_code":61},"current_units":{"temperature_2m":"°C"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWeatherTool(t *testing.T) {
+	tool := weatherTool(NewWeatherClient(WithBaseURL(newWeatherStub(t).URL)))
+
+	resp, err := tool(context.Background(), WeatherArguments{City: "London", Units: unitsMetric})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := toolText(t, resp), "The current weather in London, United Kingdom is 14.3°C with rain"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	resp, err = tool(context.Background(), WeatherArguments{City: "Atlantis", Units: unitsMetric})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := toolText(t, resp); !strings.Contains(text, "unknown city: Atlantis") {
+		t.Errorf("got %q, want an unknown city error", text)
+	}
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/metoro-io/mcp-golang/transport"
+	"golang.org/x/net/websocket"
+)
+
+// wsTransport serves MCP over WebSocket connections, one JSON-RPC message per text frame.
+// mcp-golang v0.8.0 has no WebSocket transport, and its server reads from a single transport, so every connection
+// is multiplexed onto this one: each incoming request is given a server-wide id, and Send routes the response back
+// to the connection and original id it came from.
+type wsTransport struct {
+	mu             sync.Mutex
+	messageHandler func(ctx context.Context, message *transport.BaseJsonRpcMessage)
+	errorHandler   func(error)
+	closeHandler   func()
+	conns          map[*wsConn]struct{}
+	pending        map[transport.RequestId]pendingRequest
+	nextID         transport.RequestId
+}
+
+// wsConn is one client connection. Writes are serialized because responses may be sent from several goroutines.
+type wsConn struct {
+	mu sync.Mutex
+	ws *websocket.Conn
+}
+
+// pendingRequest records where the response to a multiplexed request has to go.
+type pendingRequest struct {
+	conn *wsConn
+	id   transport.RequestId
+}
+
+// newWSTransport creates a wsTransport with no connections; mount Handler on an HTTP server to accept them.
+func newWSTransport() *wsTransport {
+	return &wsTransport{
+		conns:   make(map[*wsConn]struct{}),
+		pending: make(map[transport.RequestId]pendingRequest),
+	}
+}
+
+// Start implements transport.Transport. Connections are accepted by Handler, so there is nothing to start.
+func (t *wsTransport) Start(ctx context.Context) error {
+	return nil
+}
+
+// Send implements transport.Transport, delivering responses to the connection that sent the request and
+// broadcasting server-initiated messages to every connection.
+func (t *wsTransport) Send(ctx context.Context, message *transport.BaseJsonRpcMessage) error {
+	var id *transport.RequestId
+	switch message.Type {
+	case transport.BaseMessageTypeJSONRPCResponseType:
+		id = &message.JsonRpcResponse.Id
+	case transport.BaseMessageTypeJSONRPCErrorType:
+		id = &message.JsonRpcError.Id
+	}
+
+	t.mu.Lock()
+	if id == nil {
+		conns := make([]*wsConn, 0, len(t.conns))
+		for conn := range t.conns {
+			conns = append(conns, conn)
+		}
+		t.mu.Unlock()
+		for _, conn := range conns {
+			if err := conn.send(message); err != nil {
+				t.reportError(err)
+			}
+		}
+		return nil
+	}
+	req, ok := t.pending[*id]
+	delete(t.pending, *id)
+	t.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("no pending WebSocket request with id %d", *id)
+	}
+	*id = req.id
+	return req.conn.send(message)
+}
+
+// Close implements transport.Transport, closing every connection.
+func (t *wsTransport) Close() error {
+	t.mu.Lock()
+	conns := t.conns
+	t.conns = make(map[*wsConn]struct{})
+	handler := t.closeHandler
+	t.mu.Unlock()
+
+	for conn := range conns {
+		conn.ws.Close()
+	}
+	if handler != nil {
+		handler()
+	}
+	return nil
+}
+
+// SetCloseHandler implements transport.Transport.
+func (t *wsTransport) SetCloseHandler(handler func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closeHandler = handler
+}
+
+// SetErrorHandler implements transport.Transport.
+func (t *wsTransport) SetErrorHandler(handler func(error)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.errorHandler = handler
+}
+
+// SetMessageHandler implements transport.Transport.
+func (t *wsTransport) SetMessageHandler(handler func(ctx context.Context, message *transport.BaseJsonRpcMessage)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.messageHandler = handler
+}
+
+// Handler returns the HTTP handler that upgrades requests to WebSocket connections and serves them.
+// Clients that send no Origin header, which is everything but browsers, are accepted; browsers only from the
+// server's own origin, so other web pages can't reach the server through a visitor's browser.
+func (t *wsTransport) Handler() http.Handler {
+	return websocket.Server{
+		Handshake: checkWSOrigin,
+		Handler:   t.serveConn,
+	}
+}
+
+// checkWSOrigin rejects WebSocket handshakes from a browser page on another host.
+func checkWSOrigin(config *websocket.Config, req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host != req.Host {
+		return fmt.Errorf("WebSocket origin %q is not allowed", origin)
+	}
+	return nil
+}
+
+// serveConn reads messages from one connection until it closes.
+func (t *wsTransport) serveConn(ws *websocket.Conn) {
+	conn := &wsConn{ws: ws}
+	t.mu.Lock()
+	t.conns[conn] = struct{}{}
+	t.mu.Unlock()
+	slog.Debug("WebSocket client connected", "remote_addr", ws.Request().RemoteAddr)
+
+	defer func() {
+		t.mu.Lock()
+		delete(t.conns, conn)
+		for id, req := range t.pending {
+			if req.conn == conn {
+				delete(t.pending, id)
+			}
+		}
+		t.mu.Unlock()
+		ws.Close()
+		slog.Debug("WebSocket client disconnected", "remote_addr", ws.Request().RemoteAddr)
+	}()
+
+	ctx := ws.Request().Context()
+	for {
+		var data []byte
+		if err := websocket.Message.Receive(ws, &data); err != nil {
+			return
+		}
+		message, err := t.parseMessage(conn, data)
+		if err != nil {
+			t.reportError(err)
+			continue
+		}
+
+		t.mu.Lock()
+		handler := t.messageHandler
+		t.mu.Unlock()
+		if handler != nil {
+			// Progress notifications go straight to this connection; they answer no pending request
+			handler(contextWithProgress(ctx, message, func(_ context.Context, m *transport.BaseJsonRpcMessage) error {
+				return conn.send(m)
+			}), message)
+		}
+	}
+}
+
+// parseMessage decodes a JSON-RPC message from conn. Requests are given a server-wide id and recorded as pending.
+func (t *wsTransport) parseMessage(conn *wsConn, data []byte) (*transport.BaseJsonRpcMessage, error) {
+	var request transport.BaseJSONRPCRequest
+	if err := json.Unmarshal(data, &request); err == nil {
+		t.mu.Lock()
+		t.nextID++
+		t.pending[t.nextID] = pendingRequest{conn: conn, id: request.Id}
+		request.Id = t.nextID
+		t.mu.Unlock()
+		return transport.NewBaseMessageRequest(&request), nil
+	}
+
+	var notification transport.BaseJSONRPCNotification
+	if err := json.Unmarshal(data, &notification); err == nil {
+		return transport.NewBaseMessageNotification(&notification), nil
+	}
+	var response transport.BaseJSONRPCResponse
+	if err := json.Unmarshal(data, &response); err == nil {
+		return transport.NewBaseMessageResponse(&response), nil
+	}
+	var errorResponse transport.BaseJSONRPCError
+	if err := json.Unmarshal(data, &errorResponse); err == nil {
+		return transport.NewBaseMessageError(&errorResponse), nil
+	}
+	return nil, fmt.Errorf("invalid JSON-RPC message received over WebSocket")
+}
+
+// reportError passes err to the error handler, if one is set.
+func (t *wsTransport) reportError(err error) {
+	t.mu.Lock()
+	handler := t.errorHandler
+	t.mu.Unlock()
+	if handler != nil {
+		handler(err)
+	}
+}
+
+// send writes message to the connection as a text frame.
+func (c *wsConn) send(message *transport.BaseJsonRpcMessage) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("error encoding WebSocket message: %w", err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := websocket.Message.Send(c.ws, string(data)); err != nil {
+		return fmt.Errorf("error sending WebSocket message: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/metoro-io/mcp-golang/transport"
+	"golang.org/x/net/websocket"
+)
+
+func TestWSTransportUpgradesAndRoutesResponses(t *testing.T) {
+	wst := newWSTransport()
+	wst.SetMessageHandler(func(ctx context.Context, message *transport.BaseJsonRpcMessage) {
+		if message.Type != transport.BaseMessageTypeJSONRPCRequestType {
+			return
+		}
+		wst.Send(ctx, transport.NewBaseMessageResponse(&transport.BaseJSONRPCResponse{
+			Id:      message.JsonRpcRequest.Id,
+			Jsonrpc: "2.0",
+			Result:  json.RawMessage(`{"method":"` + message.JsonRpcRequest.Method + `"}`),
+		}))
+	})
+	server := httptest.NewServer(newWSRouter(wst, "/mcp"))
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/mcp"
+
+	ws, err := websocket.Dial(wsURL, "", server.URL)
+	if err != nil {
+		t.Fatalf("upgrade failed: %v", err)
+	}
+	defer ws.Close()
+	if err := websocket.Message.Send(ws, `{"jsonrpc":"2.0","id":7,"method":"ping","params":{}}`); err != nil {
+		t.Fatal(err)
+	}
+	var reply string
+	if err := websocket.Message.Receive(ws, &reply); err != nil {
+		t.Fatal(err)
+	}
+	var response struct {
+		ID     int             `json:"id"`
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(reply), &response); err != nil {
+		t.Fatalf("%v in %s", err, reply)
+	}
+	// The server-wide id the request was multiplexed under is swapped back for the client's own
+	if response.ID != 7 || string(response.Result) != `{"method":"ping"}` {
+		t.Errorf("got %s, want the ping answered with id 7", reply)
+	}
+
+	if ws, err := websocket.Dial(wsURL, "", "http://evil.example"); err == nil {
+		ws.Close()
+		t.Error("upgrade from a foreign browser origin was accepted")
+	}
+}